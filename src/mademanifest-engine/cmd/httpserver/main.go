@@ -1,12 +1,13 @@
 // Package main is the mademanifest-engine HTTP service entry point.
 //
-// Phase 12 retired the file-based PoC CLI; this is now the only
-// runtime surface for the engine.  The handler reads its canonical
+// Phase 12 retired the file-based PoC CLI; this is the engine's
+// service surface, and cmd/manifest its offline counterpart over the
+// same pipeline.  The handler reads its canonical
 // constants directly from pkg/canon (compiled in) and the
 // ephemeris data path from SE_EPHE_PATH; no per-request canon JSON
 // files are loaded.
 //
-// Boot-time gates:
+// Boot-time gates (httpservice.SelfCheck, shared with cmd/manifest):
 //   * canon.SelfCheck()        – validates every compiled-in canon
 //                                constant (GateOrder permutation,
 //                                ChannelTable well-formedness, etc.).
@@ -16,7 +17,12 @@
 //                                installed by the production image
 //                                (D20 / A1 RESOLVED).
 //   * ephemeris.ValidateEphePath() – verifies the resolved Swiss
-//                                Ephemeris directory exists.
+//                                Ephemeris directory exists and
+//                                reads the coverage window from
+//                                the required .se1 headers.
+//   * ephemeris.Default().Init() – loads the ephemeris path into
+//                                libswe and verifies the linked
+//                                library version (2.10.03).
 // Any failure is fatal; the engine refuses to start.
 //
 // Environment:
@@ -25,9 +31,45 @@
 //   TRINITY_DEV_CORS  Set to "1" to enable the same CORS posture as
 //                     the --dev-cors flag (k8s-friendly knob; never
 //                     set this in production).
+//   TRINITY_DEBUG     Set to "1" to mount the debug routes, as the
+//                     --debug flag does.
+//   TRINITY_BATCH_MAX_ITEMS  Item cap of one POST /manifest/batch
+//                     request (default 1000).
+//   TRINITY_BATCH_MAX_BYTES  Body cap of one POST /manifest/batch
+//                     request in bytes (default 64 MiB).
+//   TRINITY_CACHE_ENTRIES  Capacity of the in-process response
+//                     cache (default 1024 envelopes); 0 disables it.
+//   TRINITY_MAX_IN_FLIGHT  Concurrent /manifest computations (and
+//                     batch items) admitted (default 4); 0 removes
+//                     the limit.
+//   TRINITY_MAX_QUEUED  Computations allowed to wait for a slot
+//                     (default 32); the excess is shed with 503
+//                     and Retry-After.
+//   TRINITY_QUEUE_WAIT  Longest wait for a slot before shedding
+//                     (default 1s); also the Retry-After hint.
+//   TRINITY_REQUEST_TIMEOUT  Deadline of one computation (one
+//                     /manifest request or one batch item; default
+//                     30s).  An overrun answers 503.
+//   TRINITY_READ_HEADER_TIMEOUT, TRINITY_READ_TIMEOUT,
+//   TRINITY_WRITE_TIMEOUT, TRINITY_IDLE_TIMEOUT  http.Server
+//                     timeouts (defaults 5s, 30s, 60s, 120s).
+//   TRINITY_SHUTDOWN_TIMEOUT  How long SIGTERM / SIGINT waits for
+//                     in-flight requests to finish before closing
+//                     their connections (default 30s).
+//
+// All durations use Go syntax ("45s", "2m").
+//
+// Shutdown: on SIGTERM or SIGINT the server stops accepting new
+// connections and drains in-flight requests (http.Server.Shutdown),
+// so a Kubernetes rollout never cuts a computation mid-flight.  The
+// pod's terminationGracePeriodSeconds must exceed
+// TRINITY_SHUTDOWN_TIMEOUT.
 //
 // Flags:
 //   --version, -v     Print pinned versions as JSON and exit.
+//   --openapi         Print the OpenAPI 3.1 document served on
+//                     GET /openapi.json and exit; used to refresh
+//                     the published src/doc/openapi.json.
 //   --dev-cors        Enable wildcard CORS + OPTIONS preflight on
 //                     every wired route.  Required for the browser
 //                     test client at src/scripts/client.html; OFF
@@ -35,6 +77,13 @@
 //                     production deployments (see the docstring on
 //                     pkg/httpservice.withCORS for the threat
 //                     model).
+//   --debug           Mount the diagnostic POST /debug/<name> routes
+//                     (POST /debug/structure explains the Human
+//                     Design type and authority of a payload,
+//                     POST /debug/design-time reports the design-time
+//                     solver diagnostics, POST /debug/boundaries the
+//                     boundary-sensitivity report).  They are outside
+//                     the OpenAPI contract and OFF by default.
 //
 // CANON_DIRECTORY is no longer consulted: Phase 9 made the compiled
 // canon authoritative, and Phase 12 removed the legacy JSON
@@ -43,11 +92,18 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"mademanifest-engine/pkg/canon"
 	"mademanifest-engine/pkg/ephemeris"
@@ -57,8 +113,10 @@ import (
 func main() {
 	versionFlag := flag.Bool("version", false, "print pinned versions as JSON and exit")
 	flag.BoolVar(versionFlag, "v", false, "print pinned versions as JSON and exit")
+	openAPIFlag := flag.Bool("openapi", false, "print the OpenAPI document as JSON and exit")
 	devCORSFlag := flag.Bool("dev-cors", false,
 		"enable wildcard CORS + OPTIONS preflight (development only; do not enable in production)")
+	debugFlag := flag.Bool("debug", false, "mount the diagnostic /debug/ routes")
 	flag.Parse()
 
 	if *versionFlag {
@@ -69,19 +127,21 @@ func main() {
 		}
 		return
 	}
+	if *openAPIFlag {
+		doc, err := httpservice.OpenAPIDocument()
+		if err != nil {
+			log.Fatalf("build OpenAPI document: %v", err)
+		}
+		os.Stdout.Write(doc)
+		return
+	}
 
 	// Phase 9 boot-time self-checks.  The engine refuses to start
 	// when any of these fail; the alternative is silently serving
 	// non-canonical results to clients, which violates the canon
 	// determinism rules (trinity.org §"Determinism And Versioning").
-	if err := canon.SelfCheck(); err != nil {
-		log.Fatalf("canon self-check failed: %v", err)
-	}
-	if err := canon.AssertTZDBVersion(); err != nil {
-		log.Fatalf("tzdb version assertion failed: %v", err)
-	}
-	if err := ephemeris.ValidateEphePath(); err != nil {
-		log.Fatalf("ephemeris path validation failed: %v", err)
+	if err := httpservice.SelfCheck(); err != nil {
+		log.Fatal(err)
 	}
 
 	port := os.Getenv("PORT")
@@ -100,14 +160,120 @@ func main() {
 
 	handler := httpservice.New()
 	handler.DevCORS = devCORS
+	if *debugFlag || os.Getenv("TRINITY_DEBUG") == "1" {
+		log.Printf("debug routes enabled: /debug/%s", strings.Join(httpservice.DebugRoutes(), ", /debug/"))
+		handler.Debug = ephemeris.Default()
+	}
+	if v := os.Getenv("TRINITY_BATCH_MAX_ITEMS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			log.Fatalf("TRINITY_BATCH_MAX_ITEMS=%q: want a positive integer", v)
+		}
+		handler.BatchMaxItems = n
+	}
+	if v := os.Getenv("TRINITY_BATCH_MAX_BYTES"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			log.Fatalf("TRINITY_BATCH_MAX_BYTES=%q: want a positive integer", v)
+		}
+		handler.BatchMaxBytes = n
+	}
+
+	if v := os.Getenv("TRINITY_CACHE_ENTRIES"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			log.Fatalf("TRINITY_CACHE_ENTRIES=%q: want a non-negative integer", v)
+		}
+		handler.Cache = httpservice.NewResponseCache(n)
+	}
+
+	handler.Admission = httpservice.NewAdmission(
+		envCount("TRINITY_MAX_IN_FLIGHT", httpservice.DefaultMaxInFlight),
+		envCount("TRINITY_MAX_QUEUED", httpservice.DefaultMaxQueued),
+		envDuration("TRINITY_QUEUE_WAIT", httpservice.DefaultQueueWait))
+
+	handler.RequestTimeout = envDuration("TRINITY_REQUEST_TIMEOUT", httpservice.DefaultRequestTimeout)
 
 	mux := http.NewServeMux()
 	handler.Register(mux)
 
-	addr := ":" + port
-	log.Printf("HTTP service listening on %s (engine_version=%s canon_version=%s ephe_path=%s dev_cors=%v)",
-		addr, canon.EngineVersion, canon.CanonVersion, ephemeris.ResolvedEphePath(), devCORS)
-	if err := http.ListenAndServe(addr, mux); err != nil {
+	// The read / write timeouts bound one /manifest exchange;
+	// /manifest/batch pushes them out per item (see
+	// handleManifestBatch).  WriteTimeout must exceed
+	// TRINITY_REQUEST_TIMEOUT or a slow computation would lose its
+	// connection before its 503 envelope is written.
+	srv := &http.Server{
+		Addr:              ":" + port,
+		Handler:           mux,
+		ReadHeaderTimeout: envDuration("TRINITY_READ_HEADER_TIMEOUT", 5*time.Second),
+		ReadTimeout:       envDuration("TRINITY_READ_TIMEOUT", 30*time.Second),
+		WriteTimeout:      envDuration("TRINITY_WRITE_TIMEOUT", 60*time.Second),
+		IdleTimeout:       envDuration("TRINITY_IDLE_TIMEOUT", 120*time.Second),
+	}
+	shutdownTimeout := envDuration("TRINITY_SHUTDOWN_TIMEOUT", 30*time.Second)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		log.Printf("HTTP service listening on %s (engine_version=%s canon_version=%s ephe_path=%s dev_cors=%v)",
+			srv.Addr, canon.EngineVersion, canon.CanonVersion, ephemeris.ResolvedEphePath(), devCORS)
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		log.Fatalf("listen and serve: %v", err)
+	case <-ctx.Done():
+	}
+	stop()
+
+	// Graceful drain: Shutdown closes the listener, lets in-flight
+	// requests (including streaming batches) finish, and returns
+	// once every connection is idle.  Past shutdownTimeout the
+	// remaining connections are closed, which cancels their
+	// request contexts and so stops their computations.
+	log.Printf("shutdown signal received; draining in-flight requests (timeout %s)", shutdownTimeout)
+	drainCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(drainCtx); err != nil {
+		log.Printf("graceful shutdown incomplete: %v; closing remaining connections", err)
+		srv.Close()
+	}
+	if err := <-serveErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Fatalf("listen and serve: %v", err)
 	}
+	log.Printf("HTTP service stopped")
+}
+
+// envDuration reads a Go duration ("30s", "2m") from the named
+// environment variable, returning def when it is unset.  A value
+// that does not parse or is not positive is fatal, like every
+// other boot-time misconfiguration.
+func envDuration(name string, def time.Duration) time.Duration {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		log.Fatalf("%s=%q: want a positive duration such as 30s", name, v)
+	}
+	return d
+}
+
+// envCount reads a non-negative integer from the named environment
+// variable, returning def when it is unset.  Anything else is
+// fatal.
+func envCount(name string, def int) int {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		log.Fatalf("%s=%q: want a non-negative integer", name, v)
+	}
+	return n
 }
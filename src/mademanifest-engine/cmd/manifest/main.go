@@ -0,0 +1,151 @@
+// Package main is the offline mademanifest-engine CLI: it computes
+// Trinity envelopes from a file or stdin without booting the HTTP
+// service, so a chart can be scripted in a shell pipeline.
+//
+// Usage:
+//
+//	manifest [flags] [FILE]
+//
+// FILE (or stdin when absent or "-") holds one canonical payload,
+// or with --ndjson one payload per line.  Each envelope is written
+// to stdout as one line of JSON, byte-identical to the /manifest
+// response body for the same payload: httpservice.Offline runs the
+// same validator and pipeline as the service.  Diagnostics go to
+// stderr.
+//
+// The boot-time gates of cmd/httpserver (httpservice.SelfCheck)
+// run first; SE_EPHE_PATH selects the Swiss Ephemeris data
+// directory exactly as for the service.
+//
+// Exit status (output.ExitCodeForStatus) follows the HTTP status
+// /manifest would have answered with:
+//
+//	0  success
+//	1  execution_failure / canon_conflict (500), failed self-check,
+//	   unreadable FILE or unwritable stdout
+//	2  invalid_input / incomplete_input (400), or a usage error
+//	3  unsupported_input (422, 413)
+//	4  computation deadline exceeded (503)
+//
+// With --ndjson every line is answered and the exit status is the
+// one of the first payload that did not succeed.
+//
+// Flags:
+//
+//	--ndjson          Read one payload per non-blank line.
+//	--sections LIST   Comma-separated section selector, as the
+//	                  ?sections= query of /manifest.
+//	--timeout D       Deadline of one computation (default 30s).
+//	--explain NAME    Answer each payload with the trace of the debug
+//	                  route POST /debug/NAME instead of its envelope;
+//	                  "structure" explains the Human Design
+//	                  definition, type and authority, "design-time"
+//	                  reports the design-time solver diagnostics,
+//	                  "boundaries" the margin of every position to
+//	                  its sign, house, gate and line boundaries.
+//	                  Not combinable with --sections.
+//	--thresholds LIST With --explain boundaries: class:degrees
+//	                  overrides of the "near boundary" thresholds,
+//	                  e.g. gate:0.2,line:0.05.
+//	--window N        Answer each payload with its birth-time window
+//	                  of ±N minutes (1-720), as POST
+//	                  /manifest/window?window_minutes=N: every
+//	                  distinct chart over the window and where it
+//	                  starts and ends.  Not combinable with
+//	                  --sections or --explain.
+//	--version, -v     Print pinned versions as JSON and exit.
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+
+	"mademanifest-engine/pkg/canon"
+	"mademanifest-engine/pkg/httpservice"
+	"mademanifest-engine/pkg/trinity/output"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("manifest: ")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] [FILE]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	versionFlag := flag.Bool("version", false, "print pinned versions as JSON and exit")
+	flag.BoolVar(versionFlag, "v", false, "print pinned versions as JSON and exit")
+	ndjsonFlag := flag.Bool("ndjson", false, "read one payload per line and write one envelope per line")
+	sectionsFlag := flag.String("sections", "", "comma-separated sections to compute (default: all)")
+	timeoutFlag := flag.Duration("timeout", httpservice.DefaultRequestTimeout, "deadline of one computation")
+	explainFlag := flag.String("explain", "", "write the trace of a debug route instead of the envelope: "+
+		strings.Join(httpservice.DebugRoutes(), ", "))
+	thresholdsFlag := flag.String("thresholds", "",
+		"with --explain boundaries: class:degrees overrides of the near-boundary thresholds")
+	windowFlag := flag.Int("window", 0,
+		"answer with the distinct charts over ±N minutes around the birth time")
+	flag.Parse()
+
+	if *versionFlag {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(canon.Versions()); err != nil {
+			log.Fatalf("encode versions: %v", err)
+		}
+		return
+	}
+	if flag.NArg() > 1 || *timeoutFlag <= 0 || (*thresholdsFlag != "" && *explainFlag != "boundaries") {
+		flag.Usage()
+		os.Exit(output.ExitInvalid)
+	}
+
+	in := io.Reader(os.Stdin)
+	if name := flag.Arg(0); name != "" && name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	if err := httpservice.SelfCheck(); err != nil {
+		log.Fatal(err)
+	}
+
+	engine := httpservice.Offline{Sections: *sectionsFlag, Timeout: *timeoutFlag, Explain: *explainFlag,
+		Window: *windowFlag}
+	if *thresholdsFlag != "" {
+		engine.Query = url.Values{"thresholds": {*thresholdsFlag}}
+	}
+	out := bufio.NewWriter(os.Stdout)
+	exit := output.ExitSuccess
+	emit := func(body []byte, status int) error {
+		if exit == output.ExitSuccess {
+			exit = output.ExitCodeForStatus(status)
+		}
+		out.Write(body)
+		return out.WriteByte('\n')
+	}
+
+	ctx := context.Background()
+	if *ndjsonFlag {
+		if err := engine.NDJSON(ctx, in, emit); err != nil {
+			out.Flush()
+			log.Fatalf("read payloads: %v", err)
+		}
+	} else {
+		emit(engine.Envelope(ctx, in))
+	}
+	if err := out.Flush(); err != nil {
+		log.Fatalf("write envelopes: %v", err)
+	}
+	os.Exit(exit)
+}
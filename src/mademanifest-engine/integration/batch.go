@@ -0,0 +1,100 @@
+package integration
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"mademanifest-engine/pkg/golden"
+	"mademanifest-engine/pkg/httpservice"
+)
+
+// AssertManifestBatchMatchesSingle posts every golden-pack
+// input.json as one NDJSON request to POST /manifest/batch (the
+// fixture's category/name as correlation id) and asserts that each
+// streamed line carries the same HTTP status and byte-identical
+// envelope that POST /manifest returns for that input.  The batch
+// endpoint must be a transport change only, never a behavioural
+// one.
+func AssertManifestBatchMatchesSingle(t *testing.T, baseURL string) {
+	t.Helper()
+
+	packRoot := filepath.Join(RepoRoot(t), "src", "golden", "trinity")
+	fixtures, err := golden.LoadFixtures(packRoot)
+	if err != nil {
+		t.Fatalf("load golden pack at %s: %v", packRoot, err)
+	}
+
+	var body bytes.Buffer
+	inputs := make([][]byte, len(fixtures))
+	for i, f := range fixtures {
+		raw, err := f.LoadInput()
+		if err != nil {
+			t.Fatalf("read %s: %v", f.InputPath, err)
+		}
+		inputs[i] = raw
+		line, err := json.Marshal(httpservice.BatchItem{
+			ID:      f.RelativePath,
+			Payload: json.RawMessage(compact(t, raw)),
+		})
+		if err != nil {
+			t.Fatalf("encode batch item %s: %v", f.RelativePath, err)
+		}
+		body.Write(line)
+		body.WriteByte('\n')
+	}
+
+	status, raw, err := PostRaw(baseURL, "/manifest/batch", body.Bytes(), httpservice.NDJSONContentType)
+	if err != nil {
+		t.Fatalf("POST /manifest/batch: %v", err)
+	}
+	if status != http.StatusOK {
+		t.Fatalf("POST /manifest/batch status = %d; body = %s", status, raw)
+	}
+
+	var results []httpservice.BatchResult
+	sc := bufio.NewScanner(bytes.NewReader(raw))
+	sc.Buffer(nil, httpservice.MaxRequestBodyBytes)
+	for sc.Scan() {
+		var res httpservice.BatchResult
+		if err := json.Unmarshal(sc.Bytes(), &res); err != nil {
+			t.Fatalf("decode batch line %q: %v", sc.Text(), err)
+		}
+		results = append(results, res)
+	}
+	if len(results) != len(fixtures) {
+		t.Fatalf("batch returned %d lines for %d fixtures", len(results), len(fixtures))
+	}
+
+	for i, f := range fixtures {
+		res := results[i]
+		t.Run(f.RelativePath, func(t *testing.T) {
+			if res.Index != i || res.ID != f.RelativePath {
+				t.Fatalf("line %d correlates to index %d id %q", i, res.Index, res.ID)
+			}
+			wantStatus, wantBody, err := PostRaw(baseURL, "/manifest", inputs[i], "application/json")
+			if err != nil {
+				t.Fatalf("POST /manifest: %v", err)
+			}
+			if res.HTTPStatus != wantStatus {
+				t.Errorf("http_status = %d, /manifest answered %d", res.HTTPStatus, wantStatus)
+			}
+			if !bytes.Equal(res.Envelope, bytes.TrimSpace(wantBody)) {
+				t.Errorf("batch envelope differs from /manifest\nbatch:    %s\nmanifest: %s",
+					res.Envelope, wantBody)
+			}
+		})
+	}
+}
+
+func compact(t *testing.T, raw []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		t.Fatalf("compact JSON: %v", err)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,136 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"mademanifest-engine/pkg/golden"
+	"mademanifest-engine/pkg/trinity/output"
+)
+
+var (
+	cliBuildOnce sync.Once
+	cliBuildErr  error
+	cliPath      string
+)
+
+// BuildManifestCLI compiles cmd/manifest to a temp file exactly once
+// per test process and returns its path.
+func BuildManifestCLI(t testing.TB) string {
+	t.Helper()
+	cliBuildOnce.Do(func() {
+		dir, err := os.MkdirTemp("", "mademanifest-cli-*")
+		if err != nil {
+			cliBuildErr = fmt.Errorf("create temp dir: %w", err)
+			return
+		}
+		cliPath = filepath.Join(dir, "manifest")
+		cmd := exec.Command("go", "build", "-o", cliPath, "./cmd/manifest")
+		cmd.Dir = filepath.Join(RepoRoot(t), "src", "mademanifest-engine")
+		cmd.Env = append(os.Environ(), "CGO_LDFLAGS=-lm")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			cliBuildErr = fmt.Errorf("go build ./cmd/manifest: %w\n%s", err, out)
+		}
+	})
+	if cliBuildErr != nil {
+		t.Fatalf("build manifest CLI: %v", cliBuildErr)
+	}
+	return cliPath
+}
+
+// RunManifestCLI runs the CLI at bin with args and stdin against the
+// repo-local ephemeris, returning stdout and the exit code.
+func RunManifestCLI(t testing.TB, bin string, stdin []byte, args ...string) ([]byte, int) {
+	t.Helper()
+	cmd := exec.Command(bin, args...)
+	cmd.Env = append(os.Environ(), "SE_EPHE_PATH="+
+		filepath.Join(RepoRoot(t), "src", "ephemeris", "data", "REQUIRED_EPHEMERIS_FILES"))
+	cmd.Stdin = bytes.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr):
+		return stdout.Bytes(), exitErr.ExitCode()
+	case err != nil:
+		t.Fatalf("run %s: %v", bin, err)
+	}
+	if stderr.Len() > 0 {
+		t.Logf("%s stderr: %s", bin, stderr.Bytes())
+	}
+	return stdout.Bytes(), 0
+}
+
+// AssertCLIGoldenPack runs the golden pack through the offline CLI
+// at bin: every fixture once as a FILE argument, checked against its
+// expected.json with the exit code its status maps to, then all of
+// them in a single --ndjson run on stdin, which must answer every
+// line in order and exit with the first failure's code.
+func AssertCLIGoldenPack(t *testing.T, bin string) {
+	t.Helper()
+	fixtures, err := golden.LoadFixtures(filepath.Join(RepoRoot(t), "src", "golden", "trinity"))
+	if err != nil {
+		t.Fatalf("load golden pack: %v", err)
+	}
+
+	var ndjson bytes.Buffer
+	var envelopes [][]byte
+	firstFailure := output.ExitSuccess
+	for _, f := range fixtures {
+		stdout, code := RunManifestCLI(t, bin, nil, f.InputPath)
+		status := http.StatusOK
+		if golden.IsErrorCategory(f.Category) {
+			want, err := f.LoadExpectedError()
+			if err != nil {
+				t.Fatalf("load expected: %v", err)
+			}
+			status = output.StatusCodeForErrorType(want.Error.ErrorType)
+			assertGoldenErrorCase(t, f, status, bytes.TrimSuffix(stdout, []byte("\n")))
+		} else {
+			assertGoldenSuccessCase(t, f, status, bytes.TrimSuffix(stdout, []byte("\n")))
+		}
+		if want := output.ExitCodeForStatus(status); code != want {
+			t.Errorf("%s: exit code %d, want %d", f.RelativePath, code, want)
+		}
+		if firstFailure == output.ExitSuccess {
+			firstFailure = code
+		}
+
+		input, err := f.LoadInput()
+		if err != nil {
+			t.Fatalf("read %s: %v", f.InputPath, err)
+		}
+		if err := json.Compact(&ndjson, input); err != nil {
+			t.Fatalf("compact %s: %v", f.InputPath, err)
+		}
+		ndjson.WriteByte('\n')
+		envelopes = append(envelopes, stdout)
+	}
+
+	stdout, code := RunManifestCLI(t, bin, ndjson.Bytes(), "--ndjson")
+	lines := bytes.SplitAfter(stdout, []byte("\n"))
+	if len(lines) > 0 && len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) != len(envelopes) {
+		t.Fatalf("--ndjson answered %d lines for %d payloads", len(lines), len(envelopes))
+	}
+	for i, line := range lines {
+		if !bytes.Equal(line, envelopes[i]) {
+			t.Errorf("--ndjson line %d (%s) differs from the single-payload run:\n%s\nwant\n%s",
+				i, fixtures[i].RelativePath, line, envelopes[i])
+		}
+	}
+	if code != firstFailure {
+		t.Errorf("--ndjson exit code %d, want %d (the first failure's)", code, firstFailure)
+	}
+}
@@ -1,8 +1,12 @@
 package integration
 
 import (
+	"bytes"
 	"encoding/json"
+	"io"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -23,8 +27,13 @@ import (
 // plus the post-conditions on the canonical happy paths:
 //
 //   * GET /healthz               (HTTP 200, body == {"status":"ok"})
+//   * GET /readyz                (HTTP 200, body == {"status":"ready"})
 //   * GET /version with charset  (HTTP 200, ephe_path_resolved set)
 //   * POST /manifest with charset Content-Type (HTTP 200, success)
+//   * POST /manifest revalidation (strong ETag, HTTP 304 on a
+//     matching If-None-Match)
+//   * GET /openapi.json          (HTTP 200, byte-identical to the
+//     published src/doc/openapi.json)
 //
 // Shared by the local, docker, and k8s harness tests so every
 // runtime exercises the full contract through real network I/O.
@@ -130,6 +139,38 @@ func AssertHTTPContract(t *testing.T, baseURL string) {
 		}
 	})
 
+	t.Run("manifest_etag_not_modified", func(t *testing.T) {
+		body := `{"birth_date":"1990-04-09","birth_time":"18:04",` +
+			`"timezone":"Europe/Amsterdam","latitude":51.9167,"longitude":4.4}`
+		post := func(ifNoneMatch string) *http.Response {
+			req, err := http.NewRequest(http.MethodPost, baseURL+"/manifest", strings.NewReader(body))
+			if err != nil {
+				t.Fatalf("new request: %v", err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			if ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", ifNoneMatch)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("POST /manifest: %v", err)
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			return resp
+		}
+		first := post("")
+		etag := first.Header.Get("ETag")
+		if first.StatusCode != http.StatusOK || !strings.HasPrefix(etag, `"`) {
+			t.Fatalf("status = %d ETag = %q, want 200 and a strong ETag", first.StatusCode, etag)
+		}
+		again := post(etag)
+		if again.StatusCode != http.StatusNotModified || again.Header.Get("ETag") != etag {
+			t.Errorf("revalidation: status = %d ETag = %q, want 304 and %s",
+				again.StatusCode, again.Header.Get("ETag"), etag)
+		}
+	})
+
 	t.Run("healthz_liveness_only", func(t *testing.T) {
 		status, raw, err := GetJSON(baseURL, "/healthz")
 		if err != nil {
@@ -149,6 +190,38 @@ func AssertHTTPContract(t *testing.T, baseURL string) {
 			}
 		}
 	})
+
+	t.Run("readyz_canary_matches_oracle", func(t *testing.T) {
+		status, raw, err := GetJSON(baseURL, "/readyz")
+		if err != nil {
+			t.Fatalf("GET /readyz: %v", err)
+		}
+		if status != http.StatusOK {
+			t.Fatalf("status = %d, want 200; body = %s", status, raw)
+		}
+		if body := strings.TrimSpace(string(raw)); body != `{"status":"ready"}` {
+			t.Errorf("/readyz body = %q, want {\"status\":\"ready\"}", body)
+		}
+	})
+
+	t.Run("openapi_matches_published", func(t *testing.T) {
+		status, raw, err := GetJSON(baseURL, "/openapi.json")
+		if err != nil {
+			t.Fatalf("GET /openapi.json: %v", err)
+		}
+		if status != http.StatusOK {
+			t.Fatalf("status = %d, want 200; body = %s", status, raw)
+		}
+		published := filepath.Join(RepoRoot(t), "src", "doc", "openapi.json")
+		want, err := os.ReadFile(published)
+		if err != nil {
+			t.Fatalf("read %s: %v", published, err)
+		}
+		if !bytes.Equal(raw, want) {
+			t.Errorf("served contract differs from %s; the deployed build is stale "+
+				"or the document was not regenerated", published)
+		}
+	})
 }
 
 func assertErrorEnvelopeType(t *testing.T, raw []byte, want string) {
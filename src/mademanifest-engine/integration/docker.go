@@ -22,7 +22,7 @@ type DockerOptions struct {
 	Image string
 	// ExtraEnv is a list of KEY=VAL strings forwarded as "docker run -e".
 	ExtraEnv []string
-	// StartupTimeout caps how long PollHealthz is allowed to run.
+	// StartupTimeout caps how long PollReadyz is allowed to run.
 	// Default: 30s.
 	StartupTimeout time.Duration
 	// SkipBuild bypasses BuildDockerImage and assumes Image already
@@ -94,7 +94,7 @@ func StartDockerContainer(t testing.TB, opts DockerOptions) ServerHandle {
 	}
 
 	baseURL := fmt.Sprintf("http://127.0.0.1:%d", port)
-	if err := PollHealthz(baseURL, opts.StartupTimeout); err != nil {
+	if err := PollReadyz(baseURL, opts.StartupTimeout); err != nil {
 		dumpDockerLogs(t, containerID)
 		_ = exec.Command("docker", "rm", "-f", containerID).Run()
 		t.Fatalf("docker container %s did not become healthy: %v", containerID, err)
@@ -106,6 +106,43 @@ func TestDockerHarnessHTTPContract(t *testing.T) {
 	AssertHTTPContract(t, srv.BaseURL)
 }
 
+// TestDockerHarnessManifestBatchMatchesSingle is the batch
+// endpoint sentinel running against the production Docker image.
+func TestDockerHarnessManifestBatchMatchesSingle(t *testing.T) {
+	srv := StartDockerContainer(t, DockerOptions{})
+	t.Cleanup(srv.Shutdown)
+
+	AssertManifestBatchMatchesSingle(t, srv.BaseURL)
+}
+
+// TestDockerHarnessMetricsExposePipelineStages is the /metrics
+// sentinel running against the production Docker image.
+func TestDockerHarnessMetricsExposePipelineStages(t *testing.T) {
+	srv := StartDockerContainer(t, DockerOptions{})
+	t.Cleanup(srv.Shutdown)
+
+	AssertMetricsExposePipelineStages(t, srv.BaseURL)
+}
+
+// TestDockerHarnessManifestSectionsMatchFull is the ?sections=
+// sentinel running against the production Docker image.
+func TestDockerHarnessManifestSectionsMatchFull(t *testing.T) {
+	srv := StartDockerContainer(t, DockerOptions{})
+	t.Cleanup(srv.Shutdown)
+
+	AssertManifestSectionsMatchFull(t, srv.BaseURL)
+}
+
+// TestDockerHarnessLoadShedsCleanly bursts a production Docker
+// image started with a one-slot admission limiter: the overflow
+// must be refused with 503 + Retry-After, never left to time out.
+func TestDockerHarnessLoadShedsCleanly(t *testing.T) {
+	srv := StartDockerContainer(t, DockerOptions{ExtraEnv: ShedEnv})
+	t.Cleanup(srv.Shutdown)
+
+	AssertLoadShedsCleanly(t, srv.BaseURL)
+}
+
 // TestDockerHarnessTrinityGoldenPack is the Phase 11 sentinel
 // running against the production Docker image.
 func TestDockerHarnessTrinityGoldenPack(t *testing.T) {
@@ -254,8 +254,14 @@ func AssertNetworkPolicyEnforced(t *testing.T, namespace string) {
 // scale-up test takes minutes of sustained load.
 //
 // When enabled, the helper drives the service with a fan of
-// concurrent /manifest requests and asserts that the deployment's
-// replica count rises above the cold-start floor of 1.
+// concurrent /manifest requests (distinct payloads, so the response
+// cache cannot absorb them) and asserts that
+//
+//   * every answer is a success envelope or a clean admission shed
+//     (503 + Retry-After + execution_failure envelope): overload
+//     must never surface as client timeouts or connection errors;
+//   * the deployment's replica count rises above the cold-start
+//     floor of 1.
 func AssertHPAScalesUnderLoad(t *testing.T, namespace, baseURL string) {
 	t.Helper()
 	if os.Getenv("TRINITY_LOAD_TEST") != "1" {
@@ -264,24 +270,15 @@ func AssertHPAScalesUnderLoad(t *testing.T, namespace, baseURL string) {
 	if namespace == "" {
 		namespace = "default"
 	}
-	// Drive sustained load against /healthz from this process for
-	// 90 seconds; metrics-server samples every 15s and the HPA
-	// controller reconciles every 15s, so two sample windows is
-	// the minimum that can produce a scale event.
-	stop := time.After(90 * time.Second)
-	done := make(chan struct{})
-	go func() {
-		defer close(done)
-		for {
-			select {
-			case <-stop:
-				return
-			default:
-				_, _, _ = GetJSON(baseURL, "/healthz")
-			}
-		}
-	}()
-	<-done
+	// Sustain the load for 90 seconds; metrics-server samples
+	// every 15s and the HPA controller reconciles every 15s, so two
+	// sample windows is the minimum that can produce a scale event.
+	tally := DriveManifestLoad(baseURL, 32, 90*time.Second)
+	t.Logf("load: %d succeeded, %d shed, %d failed", tally.OK, tally.Shed, len(tally.Failures))
+	reportLoadFailures(t, tally)
+	if tally.OK == 0 {
+		t.Error("no request succeeded under load")
+	}
 
 	out, err := exec.Command("kubectl", "get", "deployment", "mademanifest",
 		"-n", namespace, "-o", "jsonpath={.status.replicas}").CombinedOutput()
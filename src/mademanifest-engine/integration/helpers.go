@@ -31,6 +31,7 @@ import (
 	"net/url"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 	"time"
 )
@@ -78,10 +79,22 @@ func RepoRoot(t testing.TB) string {
 // 2xx response or the deadline elapses.  It returns nil on success and a
 // descriptive error otherwise.
 func PollHealthz(baseURL string, timeout time.Duration) error {
+	return pollUntil2xx(baseURL, "/healthz", timeout)
+}
+
+// PollReadyz is PollHealthz against GET /readyz: it returns once the
+// server's canary computation matches the compiled-in oracle.  The
+// harnesses wait on it so no test runs against a server that is up
+// but cannot compute.
+func PollReadyz(baseURL string, timeout time.Duration) error {
+	return pollUntil2xx(baseURL, "/readyz", timeout)
+}
+
+func pollUntil2xx(baseURL, path string, timeout time.Duration) error {
 	deadline := time.Now().Add(timeout)
-	target, err := url.JoinPath(baseURL, "/healthz")
+	target, err := url.JoinPath(baseURL, path)
 	if err != nil {
-		return fmt.Errorf("build healthz URL: %w", err)
+		return fmt.Errorf("build %s URL: %w", path, err)
 	}
 	client := &http.Client{Timeout: 2 * time.Second}
 	var lastErr error
@@ -95,7 +108,7 @@ func PollHealthz(baseURL string, timeout time.Duration) error {
 			if resp.StatusCode >= 200 && resp.StatusCode < 300 {
 				return nil
 			}
-			lastErr = fmt.Errorf("healthz status %d", resp.StatusCode)
+			lastErr = fmt.Errorf("%s status %d", path, resp.StatusCode)
 		}
 		time.Sleep(100 * time.Millisecond)
 	}
@@ -108,12 +121,17 @@ func PollHealthz(baseURL string, timeout time.Duration) error {
 // PostJSON sends body (encoded as JSON unless it is already a []byte) to
 // baseURL+path with Content-Type: application/json and returns the HTTP
 // status code and response body bytes.  Transport errors are returned as
-// err; non-2xx status codes are not errors – callers decide.
+// err; non-2xx status codes are not errors – callers decide.  path
+// may carry a query string (e.g. "/manifest?sections=astrology").
 func PostJSON(baseURL, path string, body any) (int, []byte, error) {
+	path, query, _ := strings.Cut(path, "?")
 	target, err := url.JoinPath(baseURL, path)
 	if err != nil {
 		return 0, nil, fmt.Errorf("build URL: %w", err)
 	}
+	if query != "" {
+		target += "?" + query
+	}
 	var buf bytes.Buffer
 	switch v := body.(type) {
 	case nil:
@@ -62,6 +62,30 @@ func TestPollHealthzSucceedsOnLiveServer(t *testing.T) {
 	}
 }
 
+// TestPollReadyzWaitsForReadiness serves 503 on /readyz for the
+// first few probes and proves PollReadyz keeps polling until 200.
+func TestPollReadyzWaitsForReadiness(t *testing.T) {
+	var probes int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/readyz" {
+			http.NotFound(w, r)
+			return
+		}
+		if atomic.AddInt32(&probes, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"ready"}`))
+	}))
+	t.Cleanup(srv.Close)
+	if err := PollReadyz(srv.URL, 2*time.Second); err != nil {
+		t.Fatalf("PollReadyz returned: %v", err)
+	}
+	if got := atomic.LoadInt32(&probes); got != 3 {
+		t.Errorf("probes = %d, want 3", got)
+	}
+}
+
 func TestPollHealthzReturnsErrorOnDeadServer(t *testing.T) {
 	// Use a loopback port that nothing is bound to.  FreePort returns
 	// a port that was momentarily bound and then closed; there's a
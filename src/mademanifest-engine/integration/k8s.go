@@ -176,7 +176,7 @@ func StartKindCluster(opts K8sOptions) (ServerHandle, error) {
 		return ServerHandle{}, fmt.Errorf("kubectl rollout status: %w", err)
 	}
 
-	// 7. Open a port-forward and wait for /healthz.
+	// 7. Open a port-forward and wait for /readyz.
 	pfPort, err := freePortNoTB()
 	if err != nil {
 		runCleanupsOnError()
@@ -207,7 +207,7 @@ func StartKindCluster(opts K8sOptions) (ServerHandle, error) {
 	})
 
 	baseURL := fmt.Sprintf("http://127.0.0.1:%d", pfPort)
-	if err := PollHealthz(baseURL, opts.StartupTimeout); err != nil {
+	if err := PollReadyz(baseURL, opts.StartupTimeout); err != nil {
 		dumpKubectlDiagnostics(opts.Namespace, opts.DeploymentName)
 		runCleanupsOnError()
 		return ServerHandle{}, fmt.Errorf("kubernetes service did not become healthy: %w", err)
@@ -99,6 +99,24 @@ func TestK8sHarnessHTTPContract(t *testing.T) {
 	AssertHTTPContract(t, sharedK8s.BaseURL)
 }
 
+// TestK8sHarnessManifestBatchMatchesSingle is the batch endpoint
+// sentinel running against the kind-deployed service.
+func TestK8sHarnessManifestBatchMatchesSingle(t *testing.T) {
+	AssertManifestBatchMatchesSingle(t, sharedK8s.BaseURL)
+}
+
+// TestK8sHarnessMetricsExposePipelineStages is the /metrics
+// sentinel running against the kind-deployed service.
+func TestK8sHarnessMetricsExposePipelineStages(t *testing.T) {
+	AssertMetricsExposePipelineStages(t, sharedK8s.BaseURL)
+}
+
+// TestK8sHarnessManifestSectionsMatchFull is the ?sections=
+// sentinel running against the kind-deployed service.
+func TestK8sHarnessManifestSectionsMatchFull(t *testing.T) {
+	AssertManifestSectionsMatchFull(t, sharedK8s.BaseURL)
+}
+
 // TestK8sHarnessTrinityGoldenPack is the Phase 11 sentinel running
 // against the kind-deployed service.
 func TestK8sHarnessTrinityGoldenPack(t *testing.T) {
@@ -0,0 +1,148 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"mademanifest-engine/pkg/trinity/output"
+)
+
+// ShedEnv configures a server with one computation slot and one
+// queued waiter, so a modest burst is guaranteed to overflow the
+// admission limiter.
+var ShedEnv = []string{
+	"TRINITY_MAX_IN_FLIGHT=1",
+	"TRINITY_MAX_QUEUED=1",
+	"TRINITY_QUEUE_WAIT=50ms",
+}
+
+// LoadTally counts the outcomes of DriveManifestLoad.  Failures
+// lists every answer that was neither a success envelope nor a
+// clean shed: transport errors (client timeouts included), other
+// statuses, and 503s without an envelope or Retry-After.
+type LoadTally struct {
+	OK       int
+	Shed     int
+	Failures []string
+}
+
+// loadPayload is the Schiedam payload shifted by i minutes, so
+// every request of a load run misses the response cache and
+// reaches the ephemeris.
+func loadPayload(i int64) []byte {
+	return []byte(fmt.Sprintf(`{"birth_date":"1990-04-%02d","birth_time":"%02d:%02d",`+
+		`"timezone":"Europe/Amsterdam","latitude":51.9167,"longitude":4.4}`,
+		1+i/1440%28, i/60%24, i%60))
+}
+
+// DriveManifestLoad posts distinct payloads to /manifest from
+// workers concurrent clients for d and classifies every answer.
+// Each client gives up on a request after 10 seconds, well past
+// the default queue wait: a server that sheds cleanly never makes a
+// client wait that long.
+func DriveManifestLoad(baseURL string, workers int, d time.Duration) LoadTally {
+	target, err := url.JoinPath(baseURL, "/manifest")
+	if err != nil {
+		return LoadTally{Failures: []string{"build URL: " + err.Error()}}
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	var (
+		mu    sync.Mutex
+		tally LoadTally
+		next  atomic.Int64
+		wg    sync.WaitGroup
+	)
+	record := func(ok, shed bool, failure string) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case ok:
+			tally.OK++
+		case shed:
+			tally.Shed++
+		default:
+			tally.Failures = append(tally.Failures, failure)
+		}
+	}
+	stop := time.Now().Add(d)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for time.Now().Before(stop) {
+				resp, err := client.Post(target, "application/json",
+					bytes.NewReader(loadPayload(next.Add(1))))
+				if err != nil {
+					record(false, false, err.Error())
+					continue
+				}
+				raw, err := io.ReadAll(resp.Body)
+				resp.Body.Close()
+				switch {
+				case err != nil:
+					record(false, false, "read body: "+err.Error())
+				case resp.StatusCode == http.StatusOK:
+					record(true, false, "")
+				case resp.StatusCode == http.StatusServiceUnavailable:
+					record(false, cleanShed(resp, raw),
+						fmt.Sprintf("503 without Retry-After or envelope: %q %s",
+							resp.Header.Get("Retry-After"), raw))
+				default:
+					record(false, false, fmt.Sprintf("status %d: %s", resp.StatusCode, raw))
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return tally
+}
+
+// cleanShed reports whether a 503 is the admission limiter's
+// refusal: a positive Retry-After and an execution_failure
+// envelope.
+func cleanShed(resp *http.Response, raw []byte) bool {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 1 {
+		return false
+	}
+	var env output.ErrorEnvelope
+	return json.Unmarshal(raw, &env) == nil &&
+		env.Status == output.StatusError && env.Error.Type == output.ErrorExecutionFailure
+}
+
+// AssertLoadShedsCleanly bursts a server started with ShedEnv and
+// asserts the overflow is refused with 503 + Retry-After while the
+// admitted requests succeed – no client ever times out.
+func AssertLoadShedsCleanly(t testing.TB, baseURL string) {
+	t.Helper()
+	tally := DriveManifestLoad(baseURL, 16, 3*time.Second)
+	t.Logf("burst: %d succeeded, %d shed, %d failed", tally.OK, tally.Shed, len(tally.Failures))
+	reportLoadFailures(t, tally)
+	if tally.OK == 0 {
+		t.Error("no request succeeded during the burst")
+	}
+	if tally.Shed == 0 {
+		t.Error("no request was shed; the admission limiter did not engage")
+	}
+}
+
+// reportLoadFailures fails t with the first few unclean answers.
+func reportLoadFailures(t testing.TB, tally LoadTally) {
+	t.Helper()
+	for i, f := range tally.Failures {
+		if i == 5 {
+			t.Errorf("… and %d more", len(tally.Failures)-i)
+			break
+		}
+		t.Errorf("unclean answer under load: %s", f)
+	}
+}
@@ -22,7 +22,7 @@ type LocalServerOptions struct {
 	// ExtraEnv is appended to the subprocess environment after the
 	// defaults.  Later entries override earlier ones.
 	ExtraEnv []string
-	// StartupTimeout caps how long PollHealthz is allowed to run.
+	// StartupTimeout caps how long PollReadyz is allowed to run.
 	// Default: 15s.
 	StartupTimeout time.Duration
 	// BuildFlags are forwarded to "go build" during the one-time binary
@@ -109,7 +109,7 @@ func StartLocalServer(t testing.TB, opts LocalServerOptions) ServerHandle {
 	}
 
 	baseURL := fmt.Sprintf("http://127.0.0.1:%d", port)
-	if err := PollHealthz(baseURL, opts.StartupTimeout); err != nil {
+	if err := PollReadyz(baseURL, opts.StartupTimeout); err != nil {
 		_ = cmd.Process.Kill()
 		_ = cmd.Wait()
 		t.Fatalf("local server did not become healthy: %v", err)
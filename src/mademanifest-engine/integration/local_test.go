@@ -132,6 +132,44 @@ func TestLocalHarnessHTTPContract(t *testing.T) {
 	AssertHTTPContract(t, srv.BaseURL)
 }
 
+// TestLocalHarnessManifestBatchMatchesSingle streams the golden
+// pack through POST /manifest/batch and checks every line against
+// the /manifest answer for the same input.
+func TestLocalHarnessManifestBatchMatchesSingle(t *testing.T) {
+	srv := StartLocalServer(t, LocalServerOptions{})
+	t.Cleanup(srv.Shutdown)
+
+	AssertManifestBatchMatchesSingle(t, srv.BaseURL)
+}
+
+// TestLocalHarnessMetricsExposePipelineStages checks the GET
+// /metrics exposition after one canonical computation.
+func TestLocalHarnessMetricsExposePipelineStages(t *testing.T) {
+	srv := StartLocalServer(t, LocalServerOptions{})
+	t.Cleanup(srv.Shutdown)
+
+	AssertMetricsExposePipelineStages(t, srv.BaseURL)
+}
+
+// TestLocalHarnessManifestSectionsMatchFull checks the ?sections=
+// selector against the full envelope.
+func TestLocalHarnessManifestSectionsMatchFull(t *testing.T) {
+	srv := StartLocalServer(t, LocalServerOptions{})
+	t.Cleanup(srv.Shutdown)
+
+	AssertManifestSectionsMatchFull(t, srv.BaseURL)
+}
+
+// TestLocalHarnessLoadShedsCleanly bursts a local subprocess started
+// with a one-slot admission limiter: the overflow must be refused
+// with 503 + Retry-After, never left to time out.
+func TestLocalHarnessLoadShedsCleanly(t *testing.T) {
+	srv := StartLocalServer(t, LocalServerOptions{ExtraEnv: ShedEnv})
+	t.Cleanup(srv.Shutdown)
+
+	AssertLoadShedsCleanly(t, srv.BaseURL)
+}
+
 // TestLocalHarnessTrinityGoldenPack is the Phase 11 sentinel: the
 // full Trinity golden pack (3+5+5+5+2+3 fixtures) must round-trip
 // through the local subprocess HTTP surface without drift.
@@ -141,3 +179,11 @@ func TestLocalHarnessTrinityGoldenPack(t *testing.T) {
 
 	AssertTrinityGoldenPack(t, srv.BaseURL)
 }
+
+// TestLocalManifestCLIGoldenPack runs the whole golden pack through
+// the offline cmd/manifest binary, one payload per invocation and as
+// one NDJSON stream: same envelopes as the service, exit codes
+// mapped from the statuses.
+func TestLocalManifestCLIGoldenPack(t *testing.T) {
+	AssertCLIGoldenPack(t, BuildManifestCLI(t))
+}
@@ -0,0 +1,54 @@
+package integration
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// AssertMetricsExposePipelineStages posts the canonical Schiedam
+// payload and then scrapes GET /metrics, failing unless the
+// Prometheus exposition carries the request counter, the result
+// counter, a latency series for every trinityProcess stage, and the
+// design-time solver histograms.  It checks presence only: the
+// values depend on whatever traffic the runtime has already served.
+func AssertMetricsExposePipelineStages(t testing.TB, baseURL string) {
+	t.Helper()
+	inputBytes, err := os.ReadFile(filepath.Join(RepoRoot(t), "src", "golden", "trinity",
+		"baseline", "schiedam_1990_04_09_input.json"))
+	if err != nil {
+		t.Fatalf("read baseline input: %v", err)
+	}
+	if status, raw, err := PostManifest(baseURL, inputBytes, nil); err != nil || status != http.StatusOK {
+		t.Fatalf("POST /manifest: status %d err %v body %s", status, err, raw)
+	}
+
+	status, raw, err := GetJSON(baseURL, "/metrics")
+	if err != nil {
+		t.Fatalf("GET /metrics: %v", err)
+	}
+	if status != http.StatusOK {
+		t.Fatalf("GET /metrics status = %d; body = %s", status, raw)
+	}
+	body := string(raw)
+	for _, want := range []string{
+		`mademanifest_http_requests_total{route="/manifest",code="200"}`,
+		`mademanifest_manifest_results_total{route="/manifest",code="200",error_type="none"}`,
+		`mademanifest_http_requests_in_flight`,
+		`mademanifest_pipeline_stage_duration_seconds_count{stage="validation"}`,
+		`mademanifest_pipeline_stage_duration_seconds_count{stage="ephemeris_coverage"}`,
+		`mademanifest_pipeline_stage_duration_seconds_count{stage="astrology"}`,
+		`mademanifest_pipeline_stage_duration_seconds_count{stage="design_time"}`,
+		`mademanifest_pipeline_stage_duration_seconds_count{stage="activations"}`,
+		`mademanifest_pipeline_stage_duration_seconds_count{stage="structure"}`,
+		`mademanifest_pipeline_stage_duration_seconds_count{stage="gene_keys"}`,
+		`mademanifest_design_time_bisection_iterations_count`,
+		`mademanifest_design_time_bracket_expansions_count`,
+	} {
+		if !strings.Contains(body, want+" ") {
+			t.Errorf("/metrics is missing series %s", want)
+		}
+	}
+}
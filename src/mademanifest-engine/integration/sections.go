@@ -0,0 +1,62 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// AssertManifestSectionsMatchFull posts the canonical Schiedam
+// payload once without a selector and once per single-section
+// selector (?sections=astrology|human_design|gene_keys), failing
+// unless each partial response carries the matching sections
+// marker, exactly the selected section, and that section
+// byte-identical to the full envelope's.
+func AssertManifestSectionsMatchFull(t testing.TB, baseURL string) {
+	t.Helper()
+	inputBytes, err := os.ReadFile(filepath.Join(RepoRoot(t), "src", "golden", "trinity",
+		"baseline", "schiedam_1990_04_09_input.json"))
+	if err != nil {
+		t.Fatalf("read baseline input: %v", err)
+	}
+	var full map[string]json.RawMessage
+	if status, raw, err := PostManifest(baseURL, inputBytes, &full); err != nil || status != http.StatusOK {
+		t.Fatalf("POST /manifest: status %d err %v body %s", status, err, raw)
+	}
+
+	sections := []string{"astrology", "human_design", "gene_keys"}
+	for _, sel := range sections {
+		status, raw, err := PostJSON(baseURL, "/manifest?sections="+sel, inputBytes)
+		if err != nil || status != http.StatusOK {
+			t.Fatalf("POST /manifest?sections=%s: status %d err %v body %s", sel, status, err, raw)
+		}
+		var got map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &got); err != nil {
+			t.Fatalf("sections=%s: decode: %v", sel, err)
+		}
+		if want := `["` + sel + `"]`; string(got["sections"]) != want {
+			t.Errorf("sections=%s: marker = %s, want %s", sel, got["sections"], want)
+		}
+		for _, s := range sections {
+			raw, present := got[s]
+			switch {
+			case s == sel && !bytes.Equal(raw, full[s]):
+				t.Errorf("sections=%s: %s differs from the full envelope", sel, s)
+			case s != sel && present:
+				t.Errorf("sections=%s: unselected %s is present", sel, s)
+			}
+		}
+	}
+
+	status, raw, err := PostJSON(baseURL, "/manifest?sections=tarot", inputBytes)
+	if err != nil {
+		t.Fatalf("POST /manifest?sections=tarot: %v", err)
+	}
+	if status != http.StatusBadRequest || !strings.Contains(string(raw), `"invalid_input"`) {
+		t.Errorf("sections=tarot: status %d body %s, want 400 invalid_input", status, raw)
+	}
+}
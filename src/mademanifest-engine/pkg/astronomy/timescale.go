@@ -0,0 +1,56 @@
+package astronomy
+
+import (
+	"fmt"
+	"math"
+)
+
+// TimeScale names the time scale a Julian Day is expressed in.  The
+// engine works with two:
+//
+//   - UT: Universal Time.  Civil input (birth_date / birth_time /
+//     timezone) converts to UT, ConvertUTCToJulianDay returns a UT
+//     Julian Day, design_time_utc is emitted in UT, and house cusps
+//     and angles (swe_houses_ex) take a UT Julian Day because they
+//     depend on the Earth's rotation.
+//   - TT: Terrestrial Time, the uniform scale the planetary and lunar
+//     ephemerides are tabulated in.  swe_calc expects TT (Swiss
+//     Ephemeris calls it ET).
+//
+// The two differ by Delta T = TT - UT, about 57 seconds in 1990.
+// Feeding a UT Julian Day to swe_calc evaluates every body Delta T
+// too early; for the Moon (~0.55 arc-seconds per second of time)
+// that is roughly half an arc-minute, enough to flip an HD line
+// near a boundary.
+//
+// Convention: every Julian Day that crosses a package boundary in
+// this engine is UT unless its name carries a TT suffix.  The single
+// UT -> TT conversion happens in pkg/ephemeris immediately before
+// swe_calc.
+type TimeScale string
+
+const (
+	UT TimeScale = "UT"
+	TT TimeScale = "TT"
+)
+
+// DeltaTFunc returns Delta T = TT - UT, in days, at the given UT
+// Julian Day.  The production implementation is
+// ephemeris.Ephemeris.DeltaT, which uses the Swiss Ephemeris Delta T
+// model consistent with the loaded ephemeris files.
+type DeltaTFunc func(jdUT float64) (float64, error)
+
+// UTToTT converts a UT Julian Day to TT using deltaT.
+func UTToTT(jdUT float64, deltaT DeltaTFunc) (float64, error) {
+	if deltaT == nil {
+		return 0, fmt.Errorf("astronomy: delta T function is nil")
+	}
+	dt, err := deltaT(jdUT)
+	if err != nil {
+		return 0, fmt.Errorf("delta T at JD %.6f UT: %w", jdUT, err)
+	}
+	if math.IsNaN(dt) || math.IsInf(dt, 0) {
+		return 0, fmt.Errorf("delta T at JD %.6f UT is not finite", jdUT)
+	}
+	return jdUT + dt, nil
+}
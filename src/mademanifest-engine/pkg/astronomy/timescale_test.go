@@ -0,0 +1,32 @@
+package astronomy
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestUTToTTAddsDeltaT(t *testing.T) {
+	const jdUT = 2451545.0
+	const dt = 63.8 / 86400.0
+	got, err := UTToTT(jdUT, func(float64) (float64, error) { return dt, nil })
+	if err != nil {
+		t.Fatalf("UTToTT: %v", err)
+	}
+	if got != jdUT+dt {
+		t.Errorf("UTToTT = %.9f, want %.9f", got, jdUT+dt)
+	}
+}
+
+func TestUTToTTRejectsBadDeltaT(t *testing.T) {
+	boom := errors.New("boom")
+	if _, err := UTToTT(2451545.0, func(float64) (float64, error) { return 0, boom }); !errors.Is(err, boom) {
+		t.Errorf("err = %v, want wrapped %v", err, boom)
+	}
+	if _, err := UTToTT(2451545.0, func(float64) (float64, error) { return math.NaN(), nil }); err == nil {
+		t.Error("expected error for NaN delta T")
+	}
+	if _, err := UTToTT(2451545.0, nil); err == nil {
+		t.Error("expected error for nil delta T function")
+	}
+}
@@ -16,7 +16,7 @@ const (
 	// by this build.  Bumps on any change to scope, calculation,
 	// mapping, output, precedence, formatting, or input behaviour
 	// (per Document 08 § versioning).
-	CanonVersion = "trinity-v1-rev-0"
+	CanonVersion = "trinity-v1-rev-4"
 
 	// MappingVersion is the revision of the mapping canon (gate
 	// order, channel table, center list, channel-to-center map,
@@ -29,7 +29,7 @@ const (
 	// A5 (invalid vs unsupported boundary) is governed by the
 	// Document 04 strict input contract + D08 (minute precision
 	// boundary).  A6 (IANA canonical names only) is governed by D24.
-	InputSchemaVersion = "trinity-v1-rev-0"
+	InputSchemaVersion = "trinity-v1-rev-3"
 
 	// SourceStackVersion is the combined revision of the
 	// authoritative external sources (Swiss Ephemeris + IANA tzdb).
@@ -39,7 +39,7 @@ const (
 
 	// EngineVersion is this build's own implementation revision.
 	// Bumps on any production code change, not just canon revisions.
-	EngineVersion = "v1.0.0-trinity"
+	EngineVersion = "v1.32.0-trinity"
 )
 
 const (
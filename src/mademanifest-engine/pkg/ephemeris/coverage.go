@@ -0,0 +1,155 @@
+package ephemeris
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// requiredEphemerisFiles lists the Swiss Ephemeris data files the
+// engine needs: planets (sepl), Moon (semo) and the main asteroids
+// including Chiron (seas).  Their intersection is the date range
+// over which the engine can compute every canonical body without
+// libswe falling back to the Moshier analytic ephemeris.
+var requiredEphemerisFiles = []string{
+	"sepl_18.se1",
+	"semo_18.se1",
+	"seas_18.se1",
+}
+
+// Coverage is the Julian Day window over which every required .se1
+// file has data.  The bounds are read from the file headers and are
+// expressed in Terrestrial Time (the time scale the files are
+// tabulated in), so StartJD / EndJD are labelled _tt on the wire.
+type Coverage struct {
+	StartJD   float64 `json:"start_jd_tt"`
+	EndJD     float64 `json:"end_jd_tt"`
+	StartDate string  `json:"start_date"`
+	EndDate   string  `json:"end_date"`
+}
+
+// coverageMarginDays shrinks the file window on both sides before
+// comparing engine Julian Days against it.  The engine computes JDs
+// in UT while the files are tabulated in TT; Delta T stays far
+// below one day over the whole bundle range, so a one-day margin
+// keeps every comparison on the safe side without modelling Delta T
+// here.
+const coverageMarginDays = 1.0
+
+// Contains reports whether jd (UT) lies inside the coverage window
+// after applying the UT/TT safety margin.
+func (c Coverage) Contains(jd float64) bool {
+	return jd >= c.StartJD+coverageMarginDays && jd <= c.EndJD-coverageMarginDays
+}
+
+// String renders the window as calendar dates for diagnostics.
+func (c Coverage) String() string {
+	return fmt.Sprintf("%s .. %s", c.StartDate, c.EndDate)
+}
+
+var (
+	coverageOnce sync.Once
+	coverage     Coverage
+	coverageErr  error
+)
+
+// EphemerisCoverage returns the coverage window of the ephemeris
+// bundle at ResolvedEphePath().  The headers are read once per
+// process; ValidateEphePath triggers the read at boot so a missing
+// or unreadable file refuses to start the service.
+func EphemerisCoverage() (Coverage, error) {
+	coverageOnce.Do(func() {
+		coverage, coverageErr = LoadCoverage(ResolvedEphePath())
+	})
+	return coverage, coverageErr
+}
+
+// LoadCoverage reads the header of every file in
+// requiredEphemerisFiles under dir and returns the intersection of
+// their date ranges.
+func LoadCoverage(dir string) (Coverage, error) {
+	start, end := math.Inf(-1), math.Inf(1)
+	for _, name := range requiredEphemerisFiles {
+		path := filepath.Join(dir, name)
+		s, e, err := readSE1Range(path)
+		if err != nil {
+			return Coverage{}, fmt.Errorf("ephemeris file %q: %w", path, err)
+		}
+		start = math.Max(start, s)
+		end = math.Min(end, e)
+	}
+	if start >= end {
+		return Coverage{}, fmt.Errorf("ephemeris files in %q have no common date range", dir)
+	}
+	return Coverage{
+		StartJD:   start,
+		EndJD:     end,
+		StartDate: jdToDate(start),
+		EndDate:   jdToDate(end),
+	}, nil
+}
+
+// se1EndianTest is the magic value libswe writes after the text
+// header ("abc" packed into an int32, SEI_FILE_TEST_ENDIAN).
+const se1EndianTest = 0x616263
+
+// readSE1Range extracts the tfstart / tfend Julian Days from a .se1
+// file header.  The layout mirrors read_const() in sweph.c: three
+// CRLF-terminated text lines (version, file name, copyright), then
+// int32 endian test, int32 file length, int32 DE number, and the
+// two float64 bounds.  The endian test value tells us the byte
+// order the file was written in.
+func readSE1Range(path string) (start, end float64, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	for i := 0; i < 3; i++ {
+		line, err := r.ReadBytes('\n')
+		if err != nil {
+			return 0, 0, fmt.Errorf("read header line %d: %w", i+1, err)
+		}
+		if i == 0 && !bytes.HasPrefix(line, []byte("SWISSEPH")) {
+			return 0, 0, errors.New("not a Swiss Ephemeris file (missing SWISSEPH header)")
+		}
+	}
+
+	var raw [28]byte
+	if _, err := io.ReadFull(r, raw[:]); err != nil {
+		return 0, 0, fmt.Errorf("read binary header: %w", err)
+	}
+	var order binary.ByteOrder
+	switch {
+	case binary.LittleEndian.Uint32(raw[0:4]) == se1EndianTest:
+		order = binary.LittleEndian
+	case binary.BigEndian.Uint32(raw[0:4]) == se1EndianTest:
+		order = binary.BigEndian
+	default:
+		return 0, 0, errors.New("corrupt header: endian test value mismatch")
+	}
+	start = math.Float64frombits(order.Uint64(raw[12:20]))
+	end = math.Float64frombits(order.Uint64(raw[20:28]))
+	if math.IsNaN(start) || math.IsNaN(end) || start >= end {
+		return 0, 0, fmt.Errorf("corrupt header: date range [%v, %v]", start, end)
+	}
+	return start, end, nil
+}
+
+// jdToDate renders a Julian Day as a proleptic-Gregorian YYYY-MM-DD
+// date for diagnostics.
+func jdToDate(jd float64) string {
+	const unixEpochJD = 2440587.5
+	secs := (jd - unixEpochJD) * 86400.0
+	return time.Unix(int64(math.Floor(secs)), 0).UTC().Format("2006-01-02")
+}
@@ -0,0 +1,58 @@
+package ephemeris
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestEphemerisCoverageMatchesBundle pins the coverage window of the
+// bundled sepl_18 / semo_18 / seas_18 files: the intersection of
+// their headers runs from 1800-01-01 (sepl/seas start) to
+// 2400-01-10 (sepl end).
+func TestEphemerisCoverageMatchesBundle(t *testing.T) {
+	cov, err := EphemerisCoverage()
+	if err != nil {
+		t.Fatalf("EphemerisCoverage: %v", err)
+	}
+	if cov.StartJD != 2378496.5 {
+		t.Errorf("StartJD = %.6f, want 2378496.5", cov.StartJD)
+	}
+	if cov.EndJD < 2597651.35 || cov.EndJD > 2597651.37 {
+		t.Errorf("EndJD = %.6f, want ~2597651.359", cov.EndJD)
+	}
+	if cov.StartDate != "1800-01-01" || cov.EndDate != "2400-01-10" {
+		t.Errorf("dates = %s, want 1800-01-01 .. 2400-01-10", cov)
+	}
+
+	for _, tc := range []struct {
+		jd   float64
+		want bool
+	}{
+		{2447991.2, true},          // 1990 baseline
+		{cov.StartJD + 0.5, false}, // inside the UT/TT margin
+		{cov.StartJD - 10, false},
+		{cov.EndJD + 10, false},
+	} {
+		if got := cov.Contains(tc.jd); got != tc.want {
+			t.Errorf("Contains(%.3f) = %v, want %v", tc.jd, got, tc.want)
+		}
+	}
+}
+
+// TestLoadCoverageRejectsCorruptHeader proves a truncated or foreign
+// file is reported at boot rather than discovered mid-request.
+func TestLoadCoverageRejectsCorruptHeader(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range requiredEphemerisFiles {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("not an ephemeris\r\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := LoadCoverage(dir); err == nil {
+		t.Fatal("expected error for corrupt headers; got nil")
+	}
+	if _, err := LoadCoverage(t.TempDir()); err == nil {
+		t.Fatal("expected error for missing files; got nil")
+	}
+}
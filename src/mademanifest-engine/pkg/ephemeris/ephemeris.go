@@ -2,21 +2,337 @@ package ephemeris
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
-	"log"
 	"math"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 
-	"github.com/mshafiee/swephgo"
+	"mademanifest-engine/pkg/astronomy"
 	"mademanifest-engine/pkg/sweph"
 )
 
-var swe_initialized = false
 const requiredSwissEphVersion = "2.10.03"
 
+// CalcFlags and HousesFlags are the Swiss Ephemeris flags of every
+// swe_calc and swe_houses_ex call the canonical pipeline makes.  An
+// ephemeristest.Recording stores them, so a recording taken under
+// different flags is refused at replay.  VectorFlags add
+// SEFLG_SPEED for the full position vectors of Ephemeris.Vector,
+// which only the non-canonical extensions read.
+const (
+	CalcFlags   = sweph.SEFLG_SWIEPH
+	HousesFlags = sweph.SEFLG_SWIEPH | sweph.SEFLG_NONUT
+	VectorFlags = CalcFlags | sweph.SEFLG_SPEED
+)
+
+// Vector is the six-element position swe_calc returns under
+// VectorFlags: geocentric ecliptic longitude and latitude in degrees
+// and distance in AU, then their speeds per day.  A negative
+// LongitudeSpeed is a retrograde body.
+type Vector struct {
+	Longitude      float64
+	Latitude       float64
+	Distance       float64
+	LongitudeSpeed float64
+	LatitudeSpeed  float64
+	DistanceSpeed  float64
+}
+
+// Ephemeris owns every call into the Swiss Ephemeris C library.
+//
+// Concurrency model: libswe keeps its mutable state (the ephemeris
+// path, open .se1 file handles, the tidal-acceleration setting and
+// a per-body position cache) in a single struct that, on Linux, is
+// declared thread-local (TLS __thread in sweodef.h).  A mutex alone
+// is therefore not enough: goroutines migrate between OS threads,
+// so a call serialised by a mutex can still land on a thread where
+// swe_set_ephe_path was never run and silently use libswe's
+// compiled-in default path.  Ephemeris instead owns one dedicated
+// goroutine locked to one OS thread (runtime.LockOSThread).  That
+// goroutine runs the one-time initialisation (swe_set_ephe_path
+// plus the pinned version check) and then executes every cgo call,
+// one at a time, in arrival order.  Callers block until their call
+// has run, so concurrent /manifest requests are safe and see
+// exactly the same libswe state as a serial run.
+//
+// There is exactly one Ephemeris per process, obtained through
+// Default(); the worker is started lazily on first use.  The
+// package-level helpers (CalculatePositions, GetPlanetLongAtTime,
+// ...) delegate to it.  It is the production Provider; the C calls
+// themselves live in libswe_cgo.go, so a CGO_ENABLED=0 build still
+// compiles and every call then fails with the initialisation error.
+//
+// Failures are returned as Go errors, never as panics or
+// log.Fatal: an initialisation failure is remembered and returned
+// from every subsequent call, and a failed swe_calc surfaces as a
+// *CalcError carrying the library's own diagnostic text.
+type Ephemeris struct {
+	once    sync.Once
+	calls   chan func()
+	initErr error
+}
+
+var defaultEphemeris = &Ephemeris{}
+
+// Default returns the process-wide Ephemeris.
+func Default() *Ephemeris {
+	return defaultEphemeris
+}
+
+// CalcError reports a Swiss Ephemeris swe_calc failure for one body
+// at one Julian Day.  Message is the library's serr text verbatim,
+// which names the missing or corrupt .se1 file when the failure is
+// a data-file problem; the HTTP handler detects this type with
+// errors.As so operators can tell a data-file problem from an
+// engine bug.
+type CalcError struct {
+	Body        string  // engine body name ("sun", "north_node_true", ...)
+	BodyID      int     // Swiss Ephemeris body constant (sweph.SE_*)
+	JulianDay   float64 // Julian Day requested by the caller (UT)
+	JulianDayTT float64 // Julian Day passed to swe_calc (TT)
+	Code        int     // swe_calc return value (negative on failure)
+	Message     string  // swe_calc serr text
+}
+
+func (e *CalcError) Error() string {
+	return fmt.Sprintf("swiss ephemeris: calc %s (body %d) at JD %.6f UT (%.6f TT) failed (code %d): %s",
+		e.Body, e.BodyID, e.JulianDay, e.JulianDayTT, e.Code, e.Message)
+}
+
+// HousesError reports a house system that is undefined at the
+// requested moment and place.  Placidus (and Koch, Gauquelin) house
+// cusps do not exist inside the polar circles, where part of the
+// ecliptic never rises or sets: from |latitude| >= 90° minus the
+// obliquity of the ecliptic (about 66.56°), and occasionally just
+// outside it, where the cusp iteration fails to converge.
+// swe_houses_ex then returns ERR and silently fills in Porphyry
+// cusps; Houses discards them and returns a *HousesError instead,
+// which the request pipeline classifies as unsupported_input.
+type HousesError struct {
+	JulianDay float64 // Julian Day requested by the caller (UT)
+	Latitude  float64
+	Longitude float64
+	HSys      int // Swiss Ephemeris house-system letter
+}
+
+func (e *HousesError) Error() string {
+	return fmt.Sprintf("swiss ephemeris: house system %q undefined at latitude %v (JD %.6f UT): "+
+		"within the polar circle, swe_houses_ex fell back to Porphyry",
+		rune(e.HSys), e.Latitude, e.JulianDay)
+}
+
+// Init starts the ephemeris thread if it is not running yet and
+// returns the outcome of its one-time libswe set-up.
+// cmd/httpserver calls it at boot so a version mismatch refuses to
+// start the service instead of failing the first request.
+func (e *Ephemeris) Init() error {
+	return e.do(func() {})
+}
+
+// start launches the ephemeris thread and waits until its one-time
+// set-up has finished.  Called exactly once, through e.once.
+func (e *Ephemeris) start() {
+	e.calls = make(chan func())
+	ready := make(chan struct{})
+	go func() {
+		// Never unlocked: this OS thread holds libswe's
+		// thread-local state for the lifetime of the process.
+		runtime.LockOSThread()
+		e.initErr = requireLibswe()
+		if e.initErr == nil {
+			sweSetEphePath(resolveEphemerisPath())
+			e.initErr = requireSwissEphVersion()
+		}
+		close(ready)
+		for call := range e.calls {
+			call()
+		}
+	}()
+	<-ready
+}
+
+// do runs f on the ephemeris thread and waits for it to return.
+// f must only touch libswe and its own captured variables.
+func (e *Ephemeris) do(f func()) error {
+	e.once.Do(e.start)
+	if e.initErr != nil {
+		return e.initErr
+	}
+	done := make(chan struct{})
+	e.calls <- func() {
+		defer close(done)
+		f()
+	}
+	<-done
+	return nil
+}
+
+// Longitude returns the geocentric ecliptic longitude in degrees of
+// the Swiss Ephemeris body astre at julianDay.
+//
+// julianDay is UT, like every engine Julian Day (see
+// astronomy.TimeScale).  swe_calc expects TT, so Longitude converts
+// with astronomy.UTToTT and the Swiss Ephemeris Delta T model before
+// calling it; callers never handle TT themselves.
+//
+// A negative swe_calc return code is reported as a *CalcError.  So
+// is a successful call whose returned flags lack SEFLG_SWIEPH: that
+// means libswe could not read the .se1 data files and silently fell
+// back to the Moshier analytic ephemeris, which the canon does not
+// allow (Document 08 pins the bundled data files).
+func (e *Ephemeris) Longitude(julianDay float64, astre int) (float64, error) {
+	xx, err := e.calc(julianDay, astre, CalcFlags)
+	if err != nil {
+		return 0, err
+	}
+	return xx[0], nil // longitude in degrees
+}
+
+// Vector returns the full position vector of Swiss Ephemeris body
+// astre at julianDay (UT), computed under VectorFlags.  The time
+// scale and the failures are those of Longitude.
+func (e *Ephemeris) Vector(julianDay float64, astre int) (Vector, error) {
+	xx, err := e.calc(julianDay, astre, VectorFlags)
+	if err != nil {
+		return Vector{}, err
+	}
+	return Vector{
+		Longitude:      xx[0],
+		Latitude:       xx[1],
+		Distance:       xx[2],
+		LongitudeSpeed: xx[3],
+		LatitudeSpeed:  xx[4],
+		DistanceSpeed:  xx[5],
+	}, nil
+}
+
+// calc runs swe_calc for astre at julianDay (UT) under flags and
+// returns its six-element result.
+func (e *Ephemeris) calc(julianDay float64, astre, flags int) ([6]float64, error) {
+	xx := make([]float64, 6) // longitude, latitude, distance, then their speeds
+	serr := make([]byte, 256)
+	var (
+		jdTT    float64
+		ttErr   error
+		errCode int32
+	)
+	if err := e.do(func() {
+		jdTT, ttErr = astronomy.UTToTT(julianDay, deltaTOnThread)
+		if ttErr != nil {
+			return
+		}
+		errCode = sweCalc(jdTT, astre, flags, xx, serr)
+	}); err != nil {
+		return [6]float64{}, err
+	}
+	if ttErr != nil {
+		return [6]float64{}, ttErr
+	}
+	if errCode < 0 || errCode&sweph.SEFLG_SWIEPH == 0 {
+		msg := cString(serr)
+		if msg == "" && errCode >= 0 {
+			msg = "ephemeris data files unavailable (fell back to Moshier)"
+		}
+		return [6]float64{}, &CalcError{
+			Body:        bodyName(astre),
+			BodyID:      astre,
+			JulianDay:   julianDay,
+			JulianDayTT: jdTT,
+			Code:        int(errCode),
+			Message:     msg,
+		}
+	}
+	return [6]float64(xx), nil
+}
+
+// DeltaT returns Delta T = TT - UT in days at the UT Julian Day
+// jdUT, from the Swiss Ephemeris model (swe_deltat_ex with the
+// SEFLG_SWIEPH tidal acceleration, i.e. consistent with the loaded
+// .se1 files).  It satisfies astronomy.DeltaTFunc.
+func (e *Ephemeris) DeltaT(jdUT float64) (float64, error) {
+	var (
+		dt    float64
+		dtErr error
+	)
+	if err := e.do(func() {
+		dt, dtErr = deltaTOnThread(jdUT)
+	}); err != nil {
+		return 0, err
+	}
+	return dt, dtErr
+}
+
+// deltaTOnThread is the body of DeltaT.  It must run on the
+// ephemeris thread (inside e.do).
+func deltaTOnThread(jdUT float64) (float64, error) {
+	serr := make([]byte, 256)
+	dt := sweDeltatEx(jdUT, sweph.SEFLG_SWIEPH, serr)
+	if msg := cString(serr); msg != "" {
+		return 0, fmt.Errorf("swiss ephemeris: delta T at JD %.6f UT: %s", jdUT, msg)
+	}
+	return dt, nil
+}
+
+// Houses computes the house cusps and angles for the given moment
+// and geographic position with house system hsys (a Swiss Ephemeris
+// house-system letter, e.g. 'P' for Placidus).  cusps[0] is the
+// cusp of house 1; asc and mc are the ascendant and midheaven.
+// Longitudes are returned as produced by swe_houses_ex (already in
+// [0, 360)).
+//
+// julianDay is UT and is passed through unchanged: swe_houses_ex
+// expects UT because houses follow the Earth's rotation (sidereal
+// time), and derives the TT it needs for the ecliptic internally.
+//
+// A house system swe_houses_ex cannot compute at this latitude is
+// reported as a *HousesError, never as the Porphyry substitute.
+func (e *Ephemeris) Houses(julianDay, lat, lon float64, hsys int) (cusps [12]float64, asc, mc float64, err error) {
+	raw := make([]float64, 13) // indices 1..12 used; 0 unused
+	ascmc := make([]float64, 10)
+	var code int32
+	if err := e.do(func() {
+		code = sweHousesEx(julianDay, HousesFlags,
+			lat, lon, hsys, raw, ascmc)
+	}); err != nil {
+		return cusps, 0, 0, err
+	}
+	if code < 0 {
+		return cusps, 0, 0, &HousesError{JulianDay: julianDay, Latitude: lat, Longitude: lon, HSys: hsys}
+	}
+	copy(cusps[:], raw[1:13])
+	return cusps, ascmc[0], ascmc[1], nil
+}
+
+// cString returns the NUL-terminated prefix of a C output buffer,
+// trimmed of surrounding whitespace.
+func cString(buf []byte) string {
+	n := bytes.IndexByte(buf, 0)
+	if n < 0 {
+		n = len(buf)
+	}
+	return strings.TrimSpace(string(buf[:n]))
+}
+
+// bodyName maps a Swiss Ephemeris body constant back to the first
+// engine name that uses it, for diagnostics.
+func bodyName(astre int) string {
+	for _, a := range asterConstants {
+		if a.Constant == astre {
+			return a.Name
+		}
+	}
+	for _, a := range extensionAsters {
+		if a.Constant == astre {
+			return a.Name
+		}
+	}
+	return fmt.Sprintf("body#%d", astre)
+}
+
 // resolveEphemerisPath returns the directory the engine should pass
 // to swephgo.SetEphePath.  Order of preference:
 //
@@ -86,9 +402,10 @@ func ResolvedEphePath() string {
 
 // ValidateEphePath probes the resolved ephemeris path on boot and
 // returns an error if it is unusable.  "Usable" means the path
-// resolves to an existing directory; we do not crack open the .se1
-// files here because the swephgo loader does that lazily on the
-// first ephemeris call (and will log.Fatal on failure).
+// resolves to an existing directory holding every required .se1
+// file with a readable header; the headers also yield the date
+// coverage window (EphemerisCoverage) that the request pipeline
+// uses to reject births the bundle cannot compute.
 //
 // httpserver/main.go calls this immediately after canon.SelfCheck
 // so an obviously-misconfigured deployment fails fast at boot
@@ -102,47 +419,41 @@ func ValidateEphePath() error {
 	if !info.IsDir() {
 		return fmt.Errorf("ephemeris path %q is not a directory", abs)
 	}
+	if _, err := EphemerisCoverage(); err != nil {
+		return err
+	}
 	return nil
 }
 
-func requireSwissEphVersion() {
-	buf := make([]byte, 256)
-	swephgo.Version(buf)
-	n := bytes.IndexByte(buf, 0)
-	if n < 0 {
-		n = len(buf)
-	}
-	version := strings.TrimSpace(string(buf[:n]))
-	if version == "" {
-		log.Fatal("Swiss Ephemeris version check failed: empty version string")
-	}
-	if version != requiredSwissEphVersion {
-		log.Fatalf("Swiss Ephemeris version mismatch: got %q, want %q", version, requiredSwissEphVersion)
+// Version returns the version string the linked libswe reports,
+// which the boot check has already held against the canon pin.
+func (e *Ephemeris) Version() (string, error) {
+	var version string
+	if err := e.do(func() {
+		version = libraryVersionOnThread()
+	}); err != nil {
+		return "", err
 	}
+	return version, nil
 }
 
-func longitude(julianDay float64, astre int) float64 {
-	if !swe_initialized {
-		ephePath := resolveEphemerisPath()
-		swephgo.SetEphePath([]byte(ephePath + "\x00"))
-		requireSwissEphVersion()
-		swe_initialized = true
-	}
+// Coverage returns the date window of the bundled data files; see
+// EphemerisCoverage.
+func (e *Ephemeris) Coverage() (Coverage, error) {
+	return EphemerisCoverage()
+}
 
-	// Prepare output slices
-	xx := make([]float64, 6)     // x[0]=longitude, x[1]=latitude, x[2]=distance, etc.
-	serr := make([]byte, 256)
-	// Call swephgo.Calc
-	errCode := swephgo.Calc(julianDay, astre,
-		sweph.SEFLG_SWIEPH,
-		xx, serr)
-	if errCode < 0 {
-		// handle error if needed, e.g. log.Fatal or return NaN
-		log.Printf("swephgo.Calc error: %+v", string(serr))
-		panic("swephgo.Calc failed with error code " + fmt.Sprint(errCode))
+// requireSwissEphVersion verifies the linked libswe reports the
+// canon-pinned version (Document 08).
+func requireSwissEphVersion() error {
+	version := libraryVersionOnThread()
+	if version == "" {
+		return errors.New("Swiss Ephemeris version check failed: empty version string")
 	}
-
-	return xx[0] // longitude in degrees
+	if version != requiredSwissEphVersion {
+		return fmt.Errorf("Swiss Ephemeris version mismatch: got %q, want %q", version, requiredSwissEphVersion)
+	}
+	return nil
 }
 
 var asterConstants = []struct {
@@ -180,25 +491,58 @@ var asterConstants = []struct {
 	{"north_node_true",  sweph.SE_TRUE_NODE},
 }
 
-func CalculatePositions(julianDay float64) map[string]float64 {
-	// Using Swiss Ephemeris to compute positions of astronomical bodies
-	positions := make(map[string]float64)
-	for _, aster := range asterConstants {
-		positions[aster.Name] = longitude(julianDay, aster.Constant)
-	}
-	return positions
+// extensionAsters are the bodies only the non-canonical extensions
+// compute (pkg/trinity/extended): the four main-belt asteroids of
+// seas_18.se1, which also holds Chiron, and the lunar apogee – Black
+// Moon Lilith – mean and osculating ("true").  BodyLongitude and
+// BodyVector know their names, but Positions, the canonical set,
+// never computes them.
+var extensionAsters = []struct {
+	Name     string
+	Constant int
+}{
+	{"ceres", sweph.SE_CERES},
+	{"pallas", sweph.SE_PALLAS},
+	{"juno", sweph.SE_JUNO},
+	{"vesta", sweph.SE_VESTA},
+	{"lilith_mean", sweph.SE_MEAN_APOG},
+	{"lilith_true", sweph.SE_OSCU_APOG},
+}
+
+// CalculatePositions returns the longitude of every body in
+// asterConstants at julianDay, keyed by engine name, from the Swiss
+// Ephemeris.  The first ephemeris failure aborts the computation and
+// is returned.
+func CalculatePositions(julianDay float64) (map[string]float64, error) {
+	return Positions(defaultEphemeris, julianDay)
 }
 
-func AsterConstantByName(name string) int {
+// AsterConstantByName returns the Swiss Ephemeris body constant for
+// an engine body name, canonical or extension, or an error for a
+// name the engine does not know.
+func AsterConstantByName(name string) (int, error) {
 	for _, a := range asterConstants {
 		if a.Name == name {
-			return a.Constant
+			return a.Constant, nil
 		}
 	}
-	panic("unknown aster: " + name)
+	for _, a := range extensionAsters {
+		if a.Name == name {
+			return a.Constant, nil
+		}
+	}
+	return 0, fmt.Errorf("ephemeris: unknown body %q", name)
 }
 
 // GetPlanetLongAtTime returns the geocentric ecliptic longitude in
+// degrees for the named body at the given Julian Day, from the
+// process-wide Ephemeris.  See (*Ephemeris).BodyLongitude for the
+// body names and the node policy they select.
+func GetPlanetLongAtTime(julianDay float64, astre string) (float64, error) {
+	return defaultEphemeris.BodyLongitude(julianDay, astre)
+}
+
+// BodyLongitude returns the geocentric ecliptic longitude in
 // degrees for the named body at the given Julian Day.  Node policy
 // is selected by the body name:
 //
@@ -213,13 +557,53 @@ func AsterConstantByName(name string) int {
 // runtime; that switch was incompatible with the canon's
 // per-domain policy (astrology must always be mean, HD must always
 // be true) and broke determinism across deployments.
-func GetPlanetLongAtTime(julianDay float64, astre string) float64 {
+//
+// Unknown body names and Swiss Ephemeris failures (*CalcError) are
+// returned as errors.
+func (e *Ephemeris) BodyLongitude(julianDay float64, astre string) (float64, error) {
+	name := astre
+	offset := 0.0
 	switch astre {
 	case "south_node":
-		return math.Mod(180.0+longitude(julianDay, AsterConstantByName("north_node")), 360.0)
+		name, offset = "north_node", 180.0
 	case "south_node_true":
-		return math.Mod(180.0+longitude(julianDay, AsterConstantByName("north_node_true")), 360.0)
-	default:
-		return longitude(julianDay, AsterConstantByName(astre))
+		name, offset = "north_node_true", 180.0
+	}
+	body, err := AsterConstantByName(name)
+	if err != nil {
+		return 0, err
+	}
+	long, err := e.Longitude(julianDay, body)
+	if err != nil {
+		return 0, err
+	}
+	return math.Mod(offset+long, 360.0), nil
+}
+
+// BodyVector returns the full position vector of the named body at
+// the given Julian Day, with the body names of BodyLongitude.  A
+// south node is the north node's vector reflected through the
+// Earth: longitude + 180° and the opposite latitude, moving at the
+// same speed in longitude.
+func (e *Ephemeris) BodyVector(julianDay float64, astre string) (Vector, error) {
+	name, south := astre, false
+	switch astre {
+	case "south_node":
+		name, south = "north_node", true
+	case "south_node_true":
+		name, south = "north_node_true", true
+	}
+	body, err := AsterConstantByName(name)
+	if err != nil {
+		return Vector{}, err
+	}
+	v, err := e.Vector(julianDay, body)
+	if err != nil {
+		return Vector{}, err
+	}
+	if south {
+		v.Longitude = math.Mod(v.Longitude+180.0, 360.0)
+		v.Latitude, v.LatitudeSpeed = -v.Latitude, -v.LatitudeSpeed
 	}
+	return v, nil
 }
@@ -1,14 +1,27 @@
+//go:build cgo
+
 package ephemeris
 
 import (
+	"errors"
+	"fmt"
+	"math"
+	"runtime"
+	"sync"
 	"testing"
+
+	"github.com/mshafiee/swephgo"
+	"mademanifest-engine/pkg/sweph"
 )
 
 func TestCalculatePositions(t *testing.T) {
 
 	// Test with a sample Julian Day
 	julianDay := 2447902.5 // Sample day
-	positions := CalculatePositions(julianDay)
+	positions, err := CalculatePositions(julianDay)
+	if err != nil {
+		t.Fatalf("CalculatePositions: %v", err)
+	}
 
 	// Verify that positions map is not nil
 	if positions == nil {
@@ -25,3 +38,196 @@ func TestCalculatePositions(t *testing.T) {
 		}
 	}
 }
+
+// TestGetPlanetLongAtTimeUnknownBody proves an unknown body name is
+// reported as an error rather than a panic.
+func TestGetPlanetLongAtTimeUnknownBody(t *testing.T) {
+	if _, err := GetPlanetLongAtTime(2447902.5, "vulcan"); err == nil {
+		t.Fatal("expected error for unknown body; got nil")
+	}
+}
+
+// TestLongitudeReportsCalcError proves a swe_calc failure surfaces
+// as a *CalcError carrying the body id, JD and serr text.  Body id
+// 10000+99999 is an asteroid number no bundled .se1 file covers.
+func TestLongitudeReportsCalcError(t *testing.T) {
+	const jd = 2447902.5
+	const body = 10000 + 99999 // SE_AST_OFFSET + uncovered minor planet
+	_, err := Default().Longitude(jd, body)
+	var calcErr *CalcError
+	if !errors.As(err, &calcErr) {
+		t.Fatalf("err = %v (%T), want *CalcError", err, err)
+	}
+	if calcErr.BodyID != body || calcErr.JulianDay != jd {
+		t.Errorf("CalcError = %+v, want body %d at JD %v", calcErr, body, jd)
+	}
+	if calcErr.Message == "" {
+		t.Errorf("CalcError.Message empty; want the swe_calc serr text")
+	}
+}
+
+// TestHousesReportsPolarLatitude: inside the Arctic circle
+// swe_houses_ex cannot compute Placidus and substitutes Porphyry;
+// Houses must report a *HousesError instead, while an equal-house
+// system stays defined at the same place.
+func TestHousesReportsPolarLatitude(t *testing.T) {
+	const jd = 2449890.0              // 1995-06-21 12:00 UT
+	const lat, lon = 78.2232, 15.6267 // Longyearbyen, Svalbard
+	_, _, _, err := Default().Houses(jd, lat, lon, 'P')
+	var housesErr *HousesError
+	if !errors.As(err, &housesErr) {
+		t.Fatalf("err = %v (%T), want *HousesError", err, err)
+	}
+	if housesErr.Latitude != lat || housesErr.HSys != 'P' || housesErr.JulianDay != jd {
+		t.Errorf("HousesError = %+v", housesErr)
+	}
+	if _, _, _, err := Default().Houses(jd, lat, lon, 'E'); err != nil {
+		t.Errorf("equal houses at %v: %v", lat, err)
+	}
+	if _, _, _, err := Default().Houses(jd, 64.1466, -21.9426, 'P'); err != nil {
+		t.Errorf("Placidus at Reykjavik: %v", err)
+	}
+}
+
+// TestLongitudeIndependentOfCallerThread guards the concurrency
+// model.  libswe keeps its state in thread-local storage, so a call
+// made from an OS thread that never ran swe_set_ephe_path would fall
+// back to libswe's compiled-in default path (or whatever
+// SE_EPHE_PATH says at that moment).  Clearing SE_EPHE_PATH and
+// calling from several fresh, locked OS threads proves every call
+// runs on the initialised ephemeris thread: Chiron needs
+// seas_18.se1 and would fail anywhere else.
+func TestLongitudeIndependentOfCallerThread(t *testing.T) {
+	if err := Default().Init(); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	want, err := Default().Longitude(2447902.5, sweph.SE_CHIRON)
+	if err != nil {
+		t.Fatalf("Longitude(chiron): %v", err)
+	}
+	t.Setenv("SE_EPHE_PATH", "")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			runtime.LockOSThread()
+			// Not unlocked: the thread is discarded when the
+			// goroutine exits, taking any libswe TLS with it.
+			got, err := Default().Longitude(2447902.5, sweph.SE_CHIRON)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if got != want {
+				errs <- fmt.Errorf("chiron = %.9f, want %.9f", got, want)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+// TestLongitudeUsesTerrestrialTime pins the UT -> TT conversion in
+// front of swe_calc and quantifies the shift it introduced.  At the
+// Schiedam baseline birth (1990-04-09 16:04 UT) Delta T is ~57 s;
+// the Moon moves ~0.55"/s, so evaluating it at the UT Julian Day
+// (the pre-fix behaviour) reads it ~0.008 degrees (~29") too early.
+// The regression_sentinel/moon_tt_gate_boundary_1990_03_02 golden
+// fixture pins a birth where that shift moves the personality Moon
+// from gate 23 line 6 to gate 8 line 1.
+func TestLongitudeUsesTerrestrialTime(t *testing.T) {
+	const jdUT = 2447991.169444 // 1990-04-09 16:04 UT
+
+	dt, err := Default().DeltaT(jdUT)
+	if err != nil {
+		t.Fatalf("DeltaT: %v", err)
+	}
+	if secs := dt * 86400; secs < 56.5 || secs > 57.5 {
+		t.Errorf("Delta T = %.3f s, want ~57 s for 1990", secs)
+	}
+
+	moon, err := Default().Longitude(jdUT, sweph.SE_MOON)
+	if err != nil {
+		t.Fatalf("Longitude(moon): %v", err)
+	}
+	// Longitude must equal a raw swe_calc evaluation at the TT
+	// Julian Day, and differ from the UT-fed evaluation by the
+	// Moon's motion over Delta T.
+	var atTT, atUT float64
+	if err := Default().do(func() {
+		xx := make([]float64, 6)
+		swephgo.Calc(jdUT+dt, sweph.SE_MOON, sweph.SEFLG_SWIEPH, xx, make([]byte, 256))
+		atTT = xx[0]
+		swephgo.Calc(jdUT, sweph.SE_MOON, sweph.SEFLG_SWIEPH, xx, make([]byte, 256))
+		atUT = xx[0]
+	}); err != nil {
+		t.Fatalf("raw swe_calc: %v", err)
+	}
+
+	if moon != atTT {
+		t.Errorf("Longitude(moon) = %.9f, want swe_calc at TT = %.9f", moon, atTT)
+	}
+	if shift := moon - atUT; shift < 0.0075 || shift > 0.0085 {
+		t.Errorf("Moon TT-vs-UT shift = %.6f deg, want ~0.008 deg", shift)
+	}
+}
+
+// TestPositionsLeaveOutExtensionBodies: the canonical position set
+// is the thirteen bodies of asterConstants only; an extension body
+// added to it would change every recorded golden-pack call.
+func TestPositionsLeaveOutExtensionBodies(t *testing.T) {
+	positions, err := CalculatePositions(2447991.169444)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range extensionAsters {
+		if _, ok := positions[a.Name]; ok {
+			t.Errorf("Positions computes extension body %s", a.Name)
+		}
+	}
+	if len(positions) != len(asterConstants) {
+		t.Errorf("%d positions for %d canonical bodies", len(positions), len(asterConstants))
+	}
+}
+
+// TestBodyVectorSpeeds: the SEFLG_SPEED vector carries the canonical
+// longitude and a speed that is the slope of the longitude, with the
+// sign of the motion: Pluto was retrograde on 1990-04-09 and the
+// mean node always is.  The south node moves with the north node.
+// The extension bodies (asteroids, lunar apogee) resolve as well.
+func TestBodyVectorSpeeds(t *testing.T) {
+	const jdUT = 2447991.169444 // 1990-04-09 16:04 UT
+	const step = 1.0 / 24
+	for _, body := range []string{"sun", "moon", "mercury", "pluto", "north_node_mean", "north_node_true", "south_node",
+		"ceres", "vesta", "lilith_mean", "lilith_true"} {
+		v, err := Default().BodyVector(jdUT, body)
+		if err != nil {
+			t.Fatalf("BodyVector(%s): %v", body, err)
+		}
+		long, err := Default().BodyLongitude(jdUT, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(v.Longitude-long) > 1e-9 {
+			t.Errorf("%s: vector longitude %.12f, canonical %.12f", body, v.Longitude, long)
+		}
+		before, _ := Default().BodyLongitude(jdUT-step, body)
+		after, _ := Default().BodyLongitude(jdUT+step, body)
+		slope := math.Remainder(after-before, 360) / (2 * step)
+		if math.Abs(v.LongitudeSpeed-slope) > 1e-4*math.Max(1, math.Abs(slope)) {
+			t.Errorf("%s: speed %.6f °/day, longitude slope %.6f", body, v.LongitudeSpeed, slope)
+		}
+	}
+	for body, retrograde := range map[string]bool{"sun": false, "mercury": false, "pluto": true, "north_node_mean": true} {
+		v, _ := Default().BodyVector(jdUT, body)
+		if (v.LongitudeSpeed < 0) != retrograde {
+			t.Errorf("%s: speed %.6f °/day, retrograde %v", body, v.LongitudeSpeed, retrograde)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+// Package ephemeristest provides a table-backed ephemeris.Provider
+// for hermetic tests of the calculation packages.  A Fake answers
+// from recorded samples only: it needs neither cgo, libswe nor the
+// .se1 data files, so a test built on it runs under
+// CGO_ENABLED=0 and gives the same answer on every machine.
+package ephemeristest
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"mademanifest-engine/pkg/ephemeris"
+)
+
+// Sample is one tabulated body longitude.
+type Sample struct {
+	JD        float64 `json:"jd"`
+	Longitude float64 `json:"longitude"`
+}
+
+// HouseSample is one tabulated house computation.  Undefined marks
+// a house system the provider cannot compute there (a polar
+// latitude): the sample then answers with an *ephemeris.HousesError
+// and the cusps are unused.
+type HouseSample struct {
+	JD        float64     `json:"jd"`
+	Latitude  float64     `json:"latitude"`
+	Longitude float64     `json:"longitude"`
+	HSys      int         `json:"hsys"`
+	Cusps     [12]float64 `json:"cusps"`
+	Ascendant float64     `json:"ascendant"`
+	Midheaven float64     `json:"midheaven"`
+	Undefined bool        `json:"undefined,omitempty"`
+}
+
+// answer returns the sample as Provider.Houses results.
+func (h HouseSample) answer() (cusps [12]float64, asc, mc float64, err error) {
+	if h.Undefined {
+		return cusps, 0, 0, &ephemeris.HousesError{JulianDay: h.JD, Latitude: h.Latitude,
+			Longitude: h.Longitude, HSys: h.HSys}
+	}
+	return h.Cusps, h.Ascendant, h.Midheaven, nil
+}
+
+// Fake is a table-backed ephemeris.VectorProvider.
+//
+//   - BodyLongitude answers an exact sample, or interpolates
+//     linearly between the two samples bracketing the Julian Day
+//     (taking the short way round across 0/360).  Outside the
+//     tabulated range it fails: a Fake never extrapolates.  A
+//     south node without samples of its own is derived from the
+//     matching north node + 180°, as ephemeris.Ephemeris does.
+//   - BodyVector answers BodyLongitude's longitude with the slope
+//     of the samples around it as its speed.
+//   - Houses answers only an exact (JD, latitude, longitude, hsys)
+//     match, failing with an *ephemeris.HousesError for an
+//     Undefined sample.
+//   - Coverage returns Window and Version returns Release.
+//
+// Bodies must be sorted by JD within each body; AddLongitude keeps
+// them sorted.  A Fake is safe for concurrent use once built.
+type Fake struct {
+	Bodies     map[string][]Sample `json:"bodies"`
+	HouseTable []HouseSample       `json:"houses"`
+	Window     ephemeris.Coverage  `json:"coverage"`
+	Release    string              `json:"version"`
+}
+
+var _ ephemeris.VectorProvider = (*Fake)(nil)
+
+// AddLongitude tabulates body at jd.
+func (f *Fake) AddLongitude(body string, jd, longitude float64) {
+	if f.Bodies == nil {
+		f.Bodies = make(map[string][]Sample)
+	}
+	samples := f.Bodies[body]
+	i := sort.Search(len(samples), func(i int) bool { return samples[i].JD >= jd })
+	if i < len(samples) && samples[i].JD == jd {
+		samples[i].Longitude = longitude
+		return
+	}
+	samples = append(samples, Sample{})
+	copy(samples[i+1:], samples[i:])
+	samples[i] = Sample{JD: jd, Longitude: longitude}
+	f.Bodies[body] = samples
+}
+
+// AddHouses tabulates one house computation.
+func (f *Fake) AddHouses(h HouseSample) {
+	f.HouseTable = append(f.HouseTable, h)
+}
+
+// BodyLongitude implements ephemeris.Provider.
+func (f *Fake) BodyLongitude(julianDay float64, body string) (float64, error) {
+	if _, ok := f.Bodies[body]; !ok {
+		switch body {
+		case "south_node", "south_node_true":
+			north := "north_node"
+			if body == "south_node_true" {
+				north = "north_node_true"
+			}
+			long, err := f.BodyLongitude(julianDay, north)
+			if err != nil {
+				return 0, err
+			}
+			return math.Mod(long+180, 360), nil
+		}
+		return 0, fmt.Errorf("ephemeristest: no samples for body %q", body)
+	}
+	samples := f.Bodies[body]
+	i := sort.Search(len(samples), func(i int) bool { return samples[i].JD >= julianDay })
+	switch {
+	case i < len(samples) && samples[i].JD == julianDay:
+		return samples[i].Longitude, nil
+	case i == 0 || i == len(samples):
+		return 0, fmt.Errorf("ephemeristest: JD %.6f is outside the %d samples of %q", julianDay, len(samples), body)
+	}
+	a, b := samples[i-1], samples[i]
+	long := a.Longitude + shortArc(a.Longitude, b.Longitude)*(julianDay-a.JD)/(b.JD-a.JD)
+	long = math.Mod(long, 360)
+	if long < 0 {
+		long += 360
+	}
+	return long, nil
+}
+
+// BodyVector implements ephemeris.VectorProvider.  The longitude is
+// BodyLongitude's and the longitude speed the slope of the samples
+// it interpolates between (at a sample, of the interval it starts,
+// or ends for the last one); latitude, distance and their speeds are
+// not tabulated and are zero.  A south node without samples of its
+// own moves with its north node.
+func (f *Fake) BodyVector(julianDay float64, body string) (ephemeris.Vector, error) {
+	long, err := f.BodyLongitude(julianDay, body)
+	if err != nil {
+		return ephemeris.Vector{}, err
+	}
+	samples, ok := f.Bodies[body]
+	switch {
+	case !ok && body == "south_node":
+		samples = f.Bodies["north_node"]
+	case !ok && body == "south_node_true":
+		samples = f.Bodies["north_node_true"]
+	}
+	if len(samples) < 2 {
+		return ephemeris.Vector{}, fmt.Errorf("ephemeristest: %q needs two samples for a speed", body)
+	}
+	i := sort.Search(len(samples), func(i int) bool { return samples[i].JD > julianDay })
+	i = min(max(i, 1), len(samples)-1)
+	a, b := samples[i-1], samples[i]
+	return ephemeris.Vector{Longitude: long, LongitudeSpeed: shortArc(a.Longitude, b.Longitude) / (b.JD - a.JD)}, nil
+}
+
+// shortArc returns the arc from a to b the short way round 0/360.
+func shortArc(a, b float64) float64 {
+	delta := b - a
+	if delta > 180 {
+		delta -= 360
+	} else if delta < -180 {
+		delta += 360
+	}
+	return delta
+}
+
+// Houses implements ephemeris.Provider.
+func (f *Fake) Houses(julianDay, lat, lon float64, hsys int) (cusps [12]float64, asc, mc float64, err error) {
+	for _, h := range f.HouseTable {
+		if h.JD == julianDay && h.Latitude == lat && h.Longitude == lon && h.HSys == hsys {
+			return h.answer()
+		}
+	}
+	return cusps, 0, 0, fmt.Errorf("ephemeristest: no houses tabulated for JD %.6f at (%v, %v) system %q",
+		julianDay, lat, lon, rune(hsys))
+}
+
+// Coverage implements ephemeris.Provider.
+func (f *Fake) Coverage() (ephemeris.Coverage, error) {
+	return f.Window, nil
+}
+
+// Version implements ephemeris.Provider.
+func (f *Fake) Version() (string, error) {
+	return f.Release, nil
+}
@@ -0,0 +1,117 @@
+package ephemeristest
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"mademanifest-engine/pkg/ephemeris"
+)
+
+// TestFakeBodyLongitude covers exact samples, interpolation across
+// the 0/360 seam, the derived south node and the refusal to
+// extrapolate.
+func TestFakeBodyLongitude(t *testing.T) {
+	var f Fake
+	f.AddLongitude("moon", 20, 10)
+	f.AddLongitude("moon", 10, 350) // out of order on purpose
+	f.AddLongitude("north_node_true", 10, 100)
+
+	for _, tc := range []struct {
+		body string
+		jd   float64
+		want float64
+	}{
+		{"moon", 10, 350},
+		{"moon", 20, 10},
+		{"moon", 12.5, 355},
+		{"moon", 17.5, 5},
+		{"south_node_true", 10, 280},
+	} {
+		got, err := f.BodyLongitude(tc.jd, tc.body)
+		if err != nil {
+			t.Errorf("%s at %v: %v", tc.body, tc.jd, err)
+			continue
+		}
+		if math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("%s at %v = %v, want %v", tc.body, tc.jd, got, tc.want)
+		}
+	}
+
+	for _, tc := range []struct {
+		body string
+		jd   float64
+		msg  string
+	}{
+		{"moon", 9, "outside"},
+		{"moon", 21, "outside"},
+		{"sun", 10, `"sun"`},
+		{"south_node", 10, `"north_node"`},
+	} {
+		if _, err := f.BodyLongitude(tc.jd, tc.body); err == nil || !strings.Contains(err.Error(), tc.msg) {
+			t.Errorf("%s at %v: err = %v, want one mentioning %s", tc.body, tc.jd, err, tc.msg)
+		}
+	}
+}
+
+// TestFakeBodyVector: the speed is the slope of the samples around
+// the Julian Day, the short way round 0/360.
+func TestFakeBodyVector(t *testing.T) {
+	var f Fake
+	f.AddLongitude("moon", 10, 350)
+	f.AddLongitude("moon", 20, 10)
+	f.AddLongitude("moon", 30, 0)
+	f.AddLongitude("north_node_true", 10, 100)
+	f.AddLongitude("north_node_true", 20, 99)
+
+	for _, tc := range []struct {
+		body        string
+		jd          float64
+		long, speed float64
+	}{
+		{"moon", 10, 350, 2},
+		{"moon", 15, 0, 2},
+		{"moon", 20, 10, -1},
+		{"moon", 30, 0, -1},
+		{"south_node_true", 15, 279.5, -0.1},
+	} {
+		v, err := f.BodyVector(tc.jd, tc.body)
+		if err != nil {
+			t.Errorf("%s at %v: %v", tc.body, tc.jd, err)
+			continue
+		}
+		if math.Abs(v.Longitude-tc.long) > 1e-9 || math.Abs(v.LongitudeSpeed-tc.speed) > 1e-9 {
+			t.Errorf("%s at %v = %+v, want longitude %v speed %v", tc.body, tc.jd, v, tc.long, tc.speed)
+		}
+	}
+	if _, err := f.BodyVector(35, "moon"); err == nil {
+		t.Error("BodyVector extrapolated")
+	}
+}
+
+// TestFakeHousesMatchExactly: houses are answered only for the
+// tabulated moment, place and system.
+func TestFakeHousesMatchExactly(t *testing.T) {
+	var f Fake
+	h := HouseSample{JD: 10, Latitude: 52, Longitude: 4, HSys: 'P', Ascendant: 123, Midheaven: 45}
+	h.Cusps[0] = 123
+	f.AddHouses(h)
+
+	cusps, asc, mc, err := f.Houses(10, 52, 4, 'P')
+	if err != nil || cusps != h.Cusps || asc != 123 || mc != 45 {
+		t.Errorf("Houses = %v %v %v %v, want the tabulated sample", cusps, asc, mc, err)
+	}
+	if _, _, _, err := f.Houses(10, 52, 4, 'K'); err == nil {
+		t.Error("Houses answered for an untabulated house system")
+	}
+	if _, _, _, err := f.Houses(10.5, 52, 4, 'P'); err == nil {
+		t.Error("Houses answered for an untabulated moment")
+	}
+
+	f.AddHouses(HouseSample{JD: 10, Latitude: 78, Longitude: 15, HSys: 'P', Undefined: true})
+	var undefined *ephemeris.HousesError
+	if _, _, _, err := f.Houses(10, 78, 15, 'P'); !errors.As(err, &undefined) || undefined.Latitude != 78 {
+		t.Errorf("undefined sample: err = %v, want *ephemeris.HousesError at latitude 78", err)
+	}
+}
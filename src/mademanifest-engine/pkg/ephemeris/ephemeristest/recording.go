@@ -0,0 +1,341 @@
+package ephemeristest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+
+	"mademanifest-engine/pkg/ephemeris"
+)
+
+// RecordingFormat is the layout version of a Recording.  Bump it on
+// any incompatible change; NewReplay refuses other formats.
+const RecordingFormat = 1
+
+// Recording is the ephemeris side of a set of computations: every
+// body longitude and house computation a Provider answered, plus
+// the identity of that provider.  Replaying it reproduces the
+// computations bit for bit without the provider, and comparing it
+// with a fresh recording (Diff) pins the numeric inputs of the
+// mapping functions independently of the envelopes built from them.
+//
+// Longitudes and Houses are sorted (by body then JD, and by JD,
+// latitude, longitude, house system), so the JSON form of a
+// recording is stable and diffs cleanly.
+type Recording struct {
+	Format      int                `json:"format"`
+	Version     string             `json:"version"`
+	CalcFlags   int                `json:"calc_flags"`
+	HousesFlags int                `json:"houses_flags"`
+	Coverage    ephemeris.Coverage `json:"coverage"`
+	Longitudes  []LongitudeCall    `json:"longitudes"`
+	Houses      []HouseSample      `json:"houses"`
+}
+
+// LongitudeCall is one recorded BodyLongitude answer.
+type LongitudeCall struct {
+	JD        float64 `json:"jd"`
+	Body      string  `json:"body"`
+	Longitude float64 `json:"longitude"`
+}
+
+type longitudeKey struct {
+	jd   float64
+	body string
+}
+
+type houseKey struct {
+	jd, lat, lon float64
+	hsys         int
+}
+
+func (h HouseSample) key() houseKey {
+	return houseKey{h.JD, h.Latitude, h.Longitude, h.HSys}
+}
+
+// LoadRecording reads a recording written by WriteFile.
+func LoadRecording(path string) (Recording, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return Recording{}, err
+	}
+	var rec Recording
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&rec); err != nil {
+		return Recording{}, fmt.Errorf("decode recording %s: %w", path, err)
+	}
+	return rec, nil
+}
+
+// WriteFile writes rec as indented JSON.
+func (rec Recording) WriteFile(path string) error {
+	raw, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(raw, '\n'), 0o644)
+}
+
+// Diff lists how live departs from rec: header fields first, then
+// every call whose answer changed, every recorded call live did not
+// make and every live call missing from rec.  Values compare
+// exactly; an empty result means the two are bit-for-bit equal.
+func (rec Recording) Diff(live Recording) []string {
+	var diffs []string
+	header := func(field string, want, got any) {
+		if want != got {
+			diffs = append(diffs, fmt.Sprintf("%s: recorded %v, live %v", field, want, got))
+		}
+	}
+	header("format", rec.Format, live.Format)
+	header("version", rec.Version, live.Version)
+	header("calc_flags", rec.CalcFlags, live.CalcFlags)
+	header("houses_flags", rec.HousesFlags, live.HousesFlags)
+	header("coverage", rec.Coverage, live.Coverage)
+
+	liveLongs := make(map[longitudeKey]float64, len(live.Longitudes))
+	for _, c := range live.Longitudes {
+		liveLongs[longitudeKey{c.JD, c.Body}] = c.Longitude
+	}
+	for _, c := range rec.Longitudes {
+		k := longitudeKey{c.JD, c.Body}
+		got, ok := liveLongs[k]
+		switch {
+		case !ok:
+			diffs = append(diffs, fmt.Sprintf("BodyLongitude(%q, JD %s): recorded but not called", c.Body, num(c.JD)))
+		case got != c.Longitude:
+			diffs = append(diffs, fmt.Sprintf("BodyLongitude(%q, JD %s) = %s, recorded %s", c.Body, num(c.JD), num(got), num(c.Longitude)))
+		}
+		delete(liveLongs, k)
+	}
+	for _, c := range live.Longitudes {
+		if _, ok := liveLongs[longitudeKey{c.JD, c.Body}]; ok {
+			diffs = append(diffs, fmt.Sprintf("BodyLongitude(%q, JD %s): called but not recorded", c.Body, num(c.JD)))
+		}
+	}
+
+	liveHouses := make(map[houseKey]HouseSample, len(live.Houses))
+	for _, h := range live.Houses {
+		liveHouses[h.key()] = h
+	}
+	for _, h := range rec.Houses {
+		got, ok := liveHouses[h.key()]
+		switch {
+		case !ok:
+			diffs = append(diffs, fmt.Sprintf("%s: recorded but not called", h.call()))
+		case got != h:
+			diffs = append(diffs, fmt.Sprintf("%s = %s, recorded %s", h.call(), got.result(), h.result()))
+		}
+		delete(liveHouses, h.key())
+	}
+	for _, h := range live.Houses {
+		if _, ok := liveHouses[h.key()]; ok {
+			diffs = append(diffs, fmt.Sprintf("%s: called but not recorded", h.call()))
+		}
+	}
+	return diffs
+}
+
+// num renders a float in the shortest form that reads back as the
+// same float, never in exponent notation.
+func num(x float64) string {
+	return strconv.FormatFloat(x, 'f', -1, 64)
+}
+
+func (h HouseSample) result() string {
+	if h.Undefined {
+		return "undefined"
+	}
+	return fmt.Sprintf("%v asc %v mc %v", h.Cusps, h.Ascendant, h.Midheaven)
+}
+
+func (h HouseSample) call() string {
+	return fmt.Sprintf("Houses(JD %s, %v, %v, %q)", num(h.JD), h.Latitude, h.Longitude, rune(h.HSys))
+}
+
+// Recorder is an ephemeris.Provider that forwards every call to an
+// underlying provider and records each successful answer, and each
+// *ephemeris.HousesError as an Undefined house sample.  Other
+// failures are not recorded: replaying them fails as an unrecorded
+// call, which is the same outcome.  A Recorder is safe for
+// concurrent use.
+type Recorder struct {
+	inner ephemeris.Provider
+
+	mu         sync.Mutex
+	longitudes map[longitudeKey]float64
+	houses     map[houseKey]HouseSample
+	coverage   ephemeris.Coverage
+}
+
+var _ ephemeris.Provider = (*Recorder)(nil)
+
+// NewRecorder returns a Recorder in front of p.
+func NewRecorder(p ephemeris.Provider) *Recorder {
+	return &Recorder{
+		inner:      p,
+		longitudes: make(map[longitudeKey]float64),
+		houses:     make(map[houseKey]HouseSample),
+	}
+}
+
+// BodyLongitude implements ephemeris.Provider.
+func (r *Recorder) BodyLongitude(julianDay float64, body string) (float64, error) {
+	long, err := r.inner.BodyLongitude(julianDay, body)
+	if err == nil {
+		r.mu.Lock()
+		r.longitudes[longitudeKey{julianDay, body}] = long
+		r.mu.Unlock()
+	}
+	return long, err
+}
+
+// Houses implements ephemeris.Provider.
+func (r *Recorder) Houses(julianDay, lat, lon float64, hsys int) (cusps [12]float64, asc, mc float64, err error) {
+	cusps, asc, mc, err = r.inner.Houses(julianDay, lat, lon, hsys)
+	var undefined *ephemeris.HousesError
+	if err == nil || errors.As(err, &undefined) {
+		h := HouseSample{JD: julianDay, Latitude: lat, Longitude: lon, HSys: hsys,
+			Cusps: cusps, Ascendant: asc, Midheaven: mc, Undefined: err != nil}
+		r.mu.Lock()
+		r.houses[h.key()] = h
+		r.mu.Unlock()
+	}
+	return cusps, asc, mc, err
+}
+
+// Coverage implements ephemeris.Provider.
+func (r *Recorder) Coverage() (ephemeris.Coverage, error) {
+	cov, err := r.inner.Coverage()
+	if err == nil {
+		r.mu.Lock()
+		r.coverage = cov
+		r.mu.Unlock()
+	}
+	return cov, err
+}
+
+// Version implements ephemeris.Provider.
+func (r *Recorder) Version() (string, error) {
+	return r.inner.Version()
+}
+
+// Recording returns everything recorded so far, stamped with the
+// underlying provider's version and the engine's Swiss Ephemeris
+// flags.
+func (r *Recorder) Recording() (Recording, error) {
+	version, err := r.inner.Version()
+	if err != nil {
+		return Recording{}, fmt.Errorf("provider version: %w", err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rec := Recording{
+		Format:      RecordingFormat,
+		Version:     version,
+		CalcFlags:   ephemeris.CalcFlags,
+		HousesFlags: ephemeris.HousesFlags,
+		Coverage:    r.coverage,
+		Longitudes:  make([]LongitudeCall, 0, len(r.longitudes)),
+		Houses:      make([]HouseSample, 0, len(r.houses)),
+	}
+	for k, long := range r.longitudes {
+		rec.Longitudes = append(rec.Longitudes, LongitudeCall{JD: k.jd, Body: k.body, Longitude: long})
+	}
+	sort.Slice(rec.Longitudes, func(i, j int) bool {
+		a, b := rec.Longitudes[i], rec.Longitudes[j]
+		if a.Body != b.Body {
+			return a.Body < b.Body
+		}
+		return a.JD < b.JD
+	})
+	for _, h := range r.houses {
+		rec.Houses = append(rec.Houses, h)
+	}
+	sort.Slice(rec.Houses, func(i, j int) bool {
+		a, b := rec.Houses[i], rec.Houses[j]
+		switch {
+		case a.JD != b.JD:
+			return a.JD < b.JD
+		case a.Latitude != b.Latitude:
+			return a.Latitude < b.Latitude
+		case a.Longitude != b.Longitude:
+			return a.Longitude < b.Longitude
+		}
+		return a.HSys < b.HSys
+	})
+	return rec, nil
+}
+
+// Replay is an ephemeris.Provider answering from a Recording only.
+// Unlike a Fake it never interpolates: a call the recording does
+// not hold fails with an error naming it, so a computation that
+// drifted from the recorded one fails loudly instead of computing
+// on approximated inputs.  A Replay is safe for concurrent use.
+type Replay struct {
+	rec        Recording
+	longitudes map[longitudeKey]float64
+	houses     map[houseKey]HouseSample
+}
+
+var _ ephemeris.Provider = (*Replay)(nil)
+
+// NewReplay indexes rec for replay.  It refuses a recording of
+// another format, or one taken under Swiss Ephemeris flags the
+// engine no longer uses.
+func NewReplay(rec Recording) (*Replay, error) {
+	if rec.Format != RecordingFormat {
+		return nil, fmt.Errorf("ephemeristest: recording format %d, want %d", rec.Format, RecordingFormat)
+	}
+	if rec.CalcFlags != ephemeris.CalcFlags || rec.HousesFlags != ephemeris.HousesFlags {
+		return nil, fmt.Errorf("ephemeristest: recording taken with calc/houses flags %d/%d, engine uses %d/%d",
+			rec.CalcFlags, rec.HousesFlags, ephemeris.CalcFlags, ephemeris.HousesFlags)
+	}
+	r := &Replay{
+		rec:        rec,
+		longitudes: make(map[longitudeKey]float64, len(rec.Longitudes)),
+		houses:     make(map[houseKey]HouseSample, len(rec.Houses)),
+	}
+	for _, c := range rec.Longitudes {
+		r.longitudes[longitudeKey{c.JD, c.Body}] = c.Longitude
+	}
+	for _, h := range rec.Houses {
+		r.houses[h.key()] = h
+	}
+	return r, nil
+}
+
+// BodyLongitude implements ephemeris.Provider.
+func (r *Replay) BodyLongitude(julianDay float64, body string) (float64, error) {
+	long, ok := r.longitudes[longitudeKey{julianDay, body}]
+	if !ok {
+		return 0, fmt.Errorf("ephemeristest: replay: BodyLongitude(%q, JD %s) was not recorded", body, num(julianDay))
+	}
+	return long, nil
+}
+
+// Houses implements ephemeris.Provider.
+func (r *Replay) Houses(julianDay, lat, lon float64, hsys int) (cusps [12]float64, asc, mc float64, err error) {
+	h, ok := r.houses[houseKey{julianDay, lat, lon, hsys}]
+	if !ok {
+		h = HouseSample{JD: julianDay, Latitude: lat, Longitude: lon, HSys: hsys}
+		return cusps, 0, 0, fmt.Errorf("ephemeristest: replay: %s was not recorded", h.call())
+	}
+	return h.answer()
+}
+
+// Coverage implements ephemeris.Provider.
+func (r *Replay) Coverage() (ephemeris.Coverage, error) {
+	return r.rec.Coverage, nil
+}
+
+// Version implements ephemeris.Provider.
+func (r *Replay) Version() (string, error) {
+	return r.rec.Version, nil
+}
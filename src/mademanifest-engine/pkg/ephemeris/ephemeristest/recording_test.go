@@ -0,0 +1,130 @@
+package ephemeristest
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"mademanifest-engine/pkg/ephemeris"
+)
+
+func recordedFake(t *testing.T) Recording {
+	t.Helper()
+	f := &Fake{Window: ephemeris.Coverage{StartJD: 0, EndJD: 100}, Release: "fake-1"}
+	f.AddLongitude("sun", 10, 280)
+	f.AddLongitude("sun", 20, 290)
+	f.AddHouses(HouseSample{JD: 15, Latitude: 52, Longitude: 4, HSys: 'P', Ascendant: 7})
+	f.AddHouses(HouseSample{JD: 15, Latitude: 78, Longitude: 15, HSys: 'P', Undefined: true})
+
+	r := NewRecorder(f)
+	for _, jd := range []float64{12.5, 10, 12.5} {
+		if _, err := r.BodyLongitude(jd, "sun"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := r.BodyLongitude(30, "sun"); err == nil {
+		t.Fatal("Recorder hid the provider's failure")
+	}
+	if _, _, _, err := r.Houses(15, 52, 4, 'P'); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err := r.Houses(15, 78, 15, 'P'); err == nil {
+		t.Fatal("Recorder hid the undefined house system")
+	}
+	if _, err := r.Coverage(); err != nil {
+		t.Fatal(err)
+	}
+	rec, err := r.Recording()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rec
+}
+
+// TestRecorderCapturesSuccessfulCalls: each distinct successful call
+// is recorded once, in sorted order, with the provider's identity.
+func TestRecorderCapturesSuccessfulCalls(t *testing.T) {
+	rec := recordedFake(t)
+	want := []LongitudeCall{{JD: 10, Body: "sun", Longitude: 280}, {JD: 12.5, Body: "sun", Longitude: 282.5}}
+	if !reflect.DeepEqual(rec.Longitudes, want) {
+		t.Errorf("longitudes = %+v, want %+v", rec.Longitudes, want)
+	}
+	if len(rec.Houses) != 2 || rec.Houses[0].Ascendant != 7 || rec.Houses[0].Undefined || !rec.Houses[1].Undefined {
+		t.Errorf("houses = %+v", rec.Houses)
+	}
+	if rec.Format != RecordingFormat || rec.Version != "fake-1" || rec.Coverage.EndJD != 100 ||
+		rec.CalcFlags != ephemeris.CalcFlags || rec.HousesFlags != ephemeris.HousesFlags {
+		t.Errorf("header = %+v", rec)
+	}
+}
+
+// TestReplayAnswersOnlyRecordedCalls round-trips a recording through
+// its file form and replays it: recorded calls answer bit for bit,
+// anything else fails naming the call.
+func TestReplayAnswersOnlyRecordedCalls(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recording.json")
+	if err := recordedFake(t).WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	rec, err := LoadRecording(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay, err := NewReplay(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if long, err := replay.BodyLongitude(12.5, "sun"); err != nil || long != 282.5 {
+		t.Errorf("BodyLongitude(12.5) = %v, %v", long, err)
+	}
+	if _, err := replay.BodyLongitude(15, "sun"); err == nil || !strings.Contains(err.Error(), `"sun", JD 15`) {
+		t.Errorf("unrecorded longitude: err = %v", err)
+	}
+	if _, asc, _, err := replay.Houses(15, 52, 4, 'P'); err != nil || asc != 7 {
+		t.Errorf("Houses = %v, %v", asc, err)
+	}
+	var undefined *ephemeris.HousesError
+	if _, _, _, err := replay.Houses(15, 78, 15, 'P'); !errors.As(err, &undefined) {
+		t.Errorf("undefined houses: err = %v, want *ephemeris.HousesError", err)
+	}
+	if _, _, _, err := replay.Houses(15, 52, 4, 'K'); err == nil || !strings.Contains(err.Error(), "'K'") {
+		t.Errorf("unrecorded houses: err = %v", err)
+	}
+	if v, _ := replay.Version(); v != "fake-1" {
+		t.Errorf("Version = %q", v)
+	}
+
+	rec.CalcFlags++
+	if _, err := NewReplay(rec); err == nil {
+		t.Error("NewReplay accepted a recording taken with other flags")
+	}
+}
+
+// TestRecordingDiffNamesEveryDeparture covers a changed header, a
+// changed value, a missing call and an extra call.
+func TestRecordingDiffNamesEveryDeparture(t *testing.T) {
+	rec := recordedFake(t)
+	if d := rec.Diff(recordedFake(t)); len(d) != 0 {
+		t.Fatalf("identical recordings differ: %v", d)
+	}
+
+	live := recordedFake(t)
+	live.Version = "fake-2"
+	live.Longitudes[0].Longitude = 280.0000001
+	live.Longitudes[1].JD = 13
+	live.Houses[0].Cusps[3] = 1
+	live.Houses[1].Undefined = false
+	want := []string{
+		"version: recorded fake-1, live fake-2",
+		`BodyLongitude("sun", JD 10) = 280.0000001, recorded 280`,
+		`BodyLongitude("sun", JD 12.5): recorded but not called`,
+		`BodyLongitude("sun", JD 13): called but not recorded`,
+		"Houses(JD 15, 52, 4, 'P') = [0 0 0 1 0 0 0 0 0 0 0 0] asc 7 mc 0, recorded [0 0 0 0 0 0 0 0 0 0 0 0] asc 7 mc 0",
+		"Houses(JD 15, 78, 15, 'P') = [0 0 0 0 0 0 0 0 0 0 0 0] asc 0 mc 0, recorded undefined",
+	}
+	if got := rec.Diff(live); !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,40 @@
+//go:build cgo
+
+package ephemeris
+
+import "github.com/mshafiee/swephgo"
+
+// libswe_cgo.go is the only file of the package that calls into the
+// Swiss Ephemeris C library.  Each function below must run on the
+// ephemeris thread (inside Ephemeris.do); see the concurrency model
+// on Ephemeris.  libswe_nocgo.go stands in for it in CGO_ENABLED=0
+// builds.
+
+// requireLibswe reports whether the C library is linked in.
+func requireLibswe() error {
+	return nil
+}
+
+func sweSetEphePath(path string) {
+	swephgo.SetEphePath([]byte(path + "\x00"))
+}
+
+func sweCalc(jdTT float64, astre, flags int, xx []float64, serr []byte) int32 {
+	return swephgo.Calc(jdTT, astre, flags, xx, serr)
+}
+
+func sweDeltatEx(jdUT float64, flags int, serr []byte) float64 {
+	return swephgo.DeltatEx(jdUT, flags, serr)
+}
+
+func sweHousesEx(jdUT float64, flags int, lat, lon float64, hsys int, cusps, ascmc []float64) int32 {
+	return swephgo.HousesEx(jdUT, flags, lat, lon, hsys, cusps, ascmc)
+}
+
+// libraryVersionOnThread returns the version string of the linked
+// libswe.
+func libraryVersionOnThread() string {
+	buf := make([]byte, 256)
+	swephgo.Version(buf)
+	return cString(buf)
+}
@@ -0,0 +1,35 @@
+//go:build !cgo
+
+package ephemeris
+
+import "errors"
+
+// errNoLibswe is the initialisation error of the Swiss Ephemeris in
+// a CGO_ENABLED=0 build.  Such a build still compiles every
+// calculation package; it computes through a Provider that does not
+// need libswe, such as ephemeristest.Fake.
+var errNoLibswe = errors.New("ephemeris: built without cgo; the Swiss Ephemeris is unavailable")
+
+// requireLibswe fails the ephemeris thread's set-up, so Ephemeris.do
+// returns errNoLibswe and the stand-ins below are never reached.
+func requireLibswe() error {
+	return errNoLibswe
+}
+
+func sweSetEphePath(string) {}
+
+func sweCalc(float64, int, int, []float64, []byte) int32 {
+	return -1
+}
+
+func sweDeltatEx(float64, int, []byte) float64 {
+	return 0
+}
+
+func sweHousesEx(float64, int, float64, float64, int, []float64, []float64) int32 {
+	return -1
+}
+
+func libraryVersionOnThread() string {
+	return ""
+}
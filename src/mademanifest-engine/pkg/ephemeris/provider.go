@@ -0,0 +1,60 @@
+package ephemeris
+
+// Provider is the ephemeris a calculation runs against.  The
+// calculation packages (pkg/trinity/astro, pkg/trinity/hd) take one
+// as a parameter instead of reaching for the process-wide Swiss
+// Ephemeris, so they can be exercised hermetically: production
+// passes Default(), tests pass a table-backed
+// ephemeristest.Fake and run without cgo, libswe or .se1 files.
+//
+// Every Julian Day is UT (see astronomy.TimeScale); a Provider that
+// needs TT converts internally, as Ephemeris.Longitude does.
+type Provider interface {
+	// BodyLongitude returns the geocentric ecliptic longitude in
+	// degrees of an engine body name ("sun", "north_node_true",
+	// "south_node", ...) at julianDay.  Unknown bodies are errors.
+	BodyLongitude(julianDay float64, body string) (float64, error)
+
+	// Houses returns the twelve cusps (cusps[0] is house 1), the
+	// ascendant and the midheaven for house system hsys (a Swiss
+	// Ephemeris house-system letter, e.g. 'P').  A system that is
+	// undefined at this latitude fails with a *HousesError.
+	Houses(julianDay, lat, lon float64, hsys int) (cusps [12]float64, asc, mc float64, err error)
+
+	// Coverage returns the Julian Day window the provider can
+	// compute; births outside it are rejected before any other
+	// call is made.
+	Coverage() (Coverage, error)
+
+	// Version identifies the ephemeris implementation and data.
+	Version() (string, error)
+}
+
+// VectorProvider is a Provider that also returns full position
+// vectors (Vector), speeds included, for the non-canonical
+// extensions.  The canonical pipeline never needs one, so the
+// recording replayed by the golden pack does not implement it.
+type VectorProvider interface {
+	Provider
+
+	// BodyVector returns the position vector of an engine body
+	// name at julianDay, with the names of BodyLongitude.
+	BodyVector(julianDay float64, body string) (Vector, error)
+}
+
+var _ VectorProvider = (*Ephemeris)(nil)
+
+// Positions returns the longitude from p of every body in
+// asterConstants at julianDay, keyed by engine name.  The first
+// failure aborts the computation and is returned.
+func Positions(p Provider, julianDay float64) (map[string]float64, error) {
+	positions := make(map[string]float64, len(asterConstants))
+	for _, aster := range asterConstants {
+		long, err := p.BodyLongitude(julianDay, aster.Name)
+		if err != nil {
+			return nil, err
+		}
+		positions[aster.Name] = long
+	}
+	return positions, nil
+}
@@ -17,10 +17,11 @@
 //   * Success cases use semantic JSON equality, ignoring the
 //     metadata block (which depends on the build's EngineVersion).
 //     metadata is asserted separately to equal output.CurrentMetadata().
-//   * Error cases compare error_type plus envelope shape (status,
-//     metadata, non-empty message).  A4 (RESOLVED, Document 12 D23):
-//     fixtures assert error_type only; the message field must be
-//     present and non-empty but its wording is not authoritative.
+//   * Error cases compare error_type (plus error_code when the
+//     fixture pins one) and envelope shape (status, metadata,
+//     non-empty message).  A4 (RESOLVED, Document 12 D23): the
+//     message field must be present and non-empty but its wording
+//     is not authoritative.
 package golden
 
 import (
@@ -177,14 +178,21 @@ type ExpectedSuccess struct {
 }
 
 // ExpectedError is the JSON shape of an error-case expected.json.
-// A4 (RESOLVED, Document 12 D23): the runner asserts only error_type
-// and the envelope shape (status field, metadata block, non-empty
-// message); message wording is informational and not authoritative.
+// A4 (RESOLVED, Document 12 D23): the runner asserts only error_type,
+// error_code when the fixture pins one, and the envelope shape
+// (status field, metadata block, non-empty message); message
+// wording is informational and not authoritative.
 type ExpectedError struct {
-	Status string `json:"status"`
-	Error  struct {
-		ErrorType string `json:"error_type"`
-	} `json:"error"`
+	Status string              `json:"status"`
+	Error  ExpectedErrorObject `json:"error"`
+}
+
+// ExpectedErrorObject is the error object of an error-case
+// expected.json.  ErrorCode is optional; fixtures only pin it for
+// rejections that carry one (e.g. the DST local-time cases).
+type ExpectedErrorObject struct {
+	ErrorType string `json:"error_type"`
+	ErrorCode string `json:"error_code,omitempty"`
 }
 
 // LoadExpectedSuccess decodes a success-case expected.json.  Use
@@ -261,10 +269,12 @@ func CompareSuccess(got output.SuccessEnvelope, want ExpectedSuccess) error {
 }
 
 // CompareError asserts that got is a well-formed Trinity error
-// envelope whose error_type matches want.Error.ErrorType.  A4
-// (RESOLVED, Document 12 D23): message text is *not* compared; only
-// error_type and the structural shape (status field, non-empty
-// message, canonical metadata block) are authoritative.
+// envelope whose error_type matches want.Error.ErrorType (and whose
+// error_code matches want.Error.ErrorCode when the fixture pins
+// one).  A4 (RESOLVED, Document 12 D23): message text is *not*
+// compared; only error_type, error_code and the structural shape
+// (status field, non-empty message, canonical metadata block) are
+// authoritative.
 //
 // CurrentMetadata is taken as the canonical metadata block and
 // passed in by the caller so this function does not depend on the
@@ -278,6 +288,10 @@ func CompareError(got output.ErrorEnvelope, want ExpectedError, currentMetadata
 		return fmt.Errorf("error_type: got %q, want %q",
 			got.Error.Type, want.Error.ErrorType)
 	}
+	if want.Error.ErrorCode != "" && got.Error.Code != want.Error.ErrorCode {
+		return fmt.Errorf("error_code: got %q, want %q",
+			got.Error.Code, want.Error.ErrorCode)
+	}
 	if got.Error.Message == "" {
 		return errors.New("error.message is empty; canon requires non-empty message")
 	}
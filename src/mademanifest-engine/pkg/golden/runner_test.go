@@ -303,7 +303,7 @@ func TestCompareErrorAssertsErrorTypeOnly(t *testing.T) {
 	}
 	want := ExpectedError{
 		Status: string(output.StatusError),
-		Error:  struct{ ErrorType string `json:"error_type"` }{ErrorType: output.ErrorInvalidInput},
+		Error:  ExpectedErrorObject{ErrorType: output.ErrorInvalidInput},
 	}
 	if err := CompareError(got, want, current); err != nil {
 		t.Errorf("CompareError: %v", err)
@@ -324,7 +324,7 @@ func TestCompareErrorRejectsTypeMismatch(t *testing.T) {
 	}
 	want := ExpectedError{
 		Status: string(output.StatusError),
-		Error:  struct{ ErrorType string `json:"error_type"` }{ErrorType: output.ErrorIncompleteInput},
+		Error:  ExpectedErrorObject{ErrorType: output.ErrorIncompleteInput},
 	}
 	err := CompareError(got, want, current)
 	if err == nil || !strings.Contains(err.Error(), "error_type") {
@@ -332,6 +332,38 @@ func TestCompareErrorRejectsTypeMismatch(t *testing.T) {
 	}
 }
 
+// TestCompareErrorRejectsCodeMismatch pins error_code as
+// authoritative (D23) when the fixture names one: the same
+// error_type with a different error_code must fail, while a fixture
+// without error_code accepts any code.
+func TestCompareErrorRejectsCodeMismatch(t *testing.T) {
+	current := output.CurrentMetadata()
+	got := output.ErrorEnvelope{
+		Status:   output.StatusError,
+		Metadata: current,
+		Error: output.Error{
+			Type:    output.ErrorInvalidInput,
+			Code:    "ambiguous_local_time",
+			Message: "local time occurs twice",
+		},
+	}
+	want := ExpectedError{
+		Status: string(output.StatusError),
+		Error: ExpectedErrorObject{
+			ErrorType: output.ErrorInvalidInput,
+			ErrorCode: "nonexistent_local_time",
+		},
+	}
+	err := CompareError(got, want, current)
+	if err == nil || !strings.Contains(err.Error(), "error_code") {
+		t.Fatalf("CompareError = %v; want error_code mismatch", err)
+	}
+	want.Error.ErrorCode = ""
+	if err := CompareError(got, want, current); err != nil {
+		t.Errorf("CompareError without pinned code: %v", err)
+	}
+}
+
 // TestCompareErrorRejectsEmptyMessage covers the canon rule that
 // error.message must be non-empty (A4 RESOLVED, D23: text is
 // informational but cannot be elided).
@@ -347,7 +379,7 @@ func TestCompareErrorRejectsEmptyMessage(t *testing.T) {
 	}
 	want := ExpectedError{
 		Status: string(output.StatusError),
-		Error:  struct{ ErrorType string `json:"error_type"` }{ErrorType: output.ErrorInvalidInput},
+		Error:  ExpectedErrorObject{ErrorType: output.ErrorInvalidInput},
 	}
 	err := CompareError(got, want, current)
 	if err == nil || !strings.Contains(err.Error(), "message") {
@@ -375,7 +407,7 @@ func TestCompareErrorRejectsMetadataDrift(t *testing.T) {
 	}
 	want := ExpectedError{
 		Status: string(output.StatusError),
-		Error:  struct{ ErrorType string `json:"error_type"` }{ErrorType: output.ErrorInvalidInput},
+		Error:  ExpectedErrorObject{ErrorType: output.ErrorInvalidInput},
 	}
 	err := CompareError(got, want, current)
 	if err == nil || !strings.Contains(err.Error(), "metadata") {
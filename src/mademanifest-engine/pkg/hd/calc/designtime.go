@@ -29,6 +29,7 @@
 package calc
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math"
@@ -60,6 +61,15 @@ const (
 	// in the canonical case.
 	initialBracketHalfWidthDays = 5.0
 
+	// DesignLookbackDays is how far before birth the solver first
+	// evaluates the Sun: the lower edge of the initial bracket.
+	// Callers that must guarantee ephemeris data for the whole
+	// design-time search (the ephemeris coverage gate) check
+	// birthJD - DesignLookbackDays.  Bracket expansion can reach
+	// further back only for the pathological Sun rates it exists
+	// to absorb.
+	DesignLookbackDays = SunOffsetDeg + initialBracketHalfWidthDays
+
 	// secondsPerDay is the Julian Day to second conversion factor.
 	secondsPerDay = 86400.0
 
@@ -86,24 +96,29 @@ const (
 // SunLongitudeFunc returns the geocentric ecliptic Sun longitude in
 // degrees, normalised to the canonical [0, 360) range, at a given
 // Julian Day.  Implementations are expected to be deterministic:
-// repeated calls with the same input return the same output.
-type SunLongitudeFunc func(jd float64) float64
+// repeated calls with the same input return the same output.  A
+// non-nil error (e.g. a Swiss Ephemeris data-file failure) aborts
+// the solve; the solver returns it wrapped with the offending JD.
+type SunLongitudeFunc func(jd float64) (float64, error)
 
 // Diagnostics captures auxiliary information about a solver run.
-// Production code (the HTTP handler) discards this; tests use it
-// to enforce the canonical iteration discipline (forbidden-shortcut
-// detection, monotonic bracket shrinkage, final-width bound).
+// Tests use it to enforce the canonical iteration discipline
+// (forbidden-shortcut detection, monotonic bracket shrinkage,
+// final-width bound); the HTTP service exports the iteration and
+// expansion counts as metrics and the whole record on its
+// POST /debug/design-time route.  It never reaches the response
+// envelope.
 type Diagnostics struct {
 	// SunFuncCalls is the total number of SunLongitudeFunc
 	// invocations made during the solve, including the initial
 	// target capture, bracket-establishment evaluations, and every
 	// bisection step.
-	SunFuncCalls int
+	SunFuncCalls int `json:"sun_func_calls"`
 
 	// BracketIterations is the number of bisection-loop passes
 	// (one mid-evaluation per pass).  Distinct from SunFuncCalls
 	// because the initial-bracket setup also contributes calls.
-	BracketIterations int
+	BracketIterations int `json:"bracket_iterations"`
 
 	// BracketExpansions is the number of times the initial bracket
 	// had to be widened beyond the canonical 10-day window before
@@ -111,33 +126,33 @@ type Diagnostics struct {
 	// is zero; non-zero values flag input regimes where the Sun's
 	// instantaneous rate drives the 88° offset outside the nominal
 	// 88-day distance.
-	BracketExpansions int
+	BracketExpansions int `json:"bracket_expansions"`
 
 	// FinalBracketDays is the bracket width (upper - lower) at the
 	// moment the loop exited, in Julian Days.  The canonical exit
 	// rule guarantees this is < StopBracketSeconds / 86400 unless
 	// the early |diff| stop fired first, in which case it is the
 	// width at the iteration that triggered the early exit.
-	FinalBracketDays float64
+	FinalBracketDays float64 `json:"final_bracket_days"`
 
 	// FinalAbsDiffDeg is the absolute Sun-longitude difference from
 	// the target at the chosen Julian Day.  The canonical exit rule
 	// guarantees this is < StopAbsSunDiffDeg unless the width-stop
 	// fired first.
-	FinalAbsDiffDeg float64
+	FinalAbsDiffDeg float64 `json:"final_abs_diff_deg"`
 
 	// FinalLowerJD and FinalUpperJD are the lower and upper bounds
 	// of the final search interval at the moment the loop exited.
 	// Exposed so canon-A3/D22 regression sentinels can assert that
 	// the returned JD equals FinalLowerJD exactly (any midpoint or
 	// upper-bound regression would emit a different value).
-	FinalLowerJD float64
-	FinalUpperJD float64
+	FinalLowerJD float64 `json:"final_lower_jd"`
+	FinalUpperJD float64 `json:"final_upper_jd"`
 
 	// ExitReason is the human-readable name of the stop condition
 	// that terminated the loop: "abs_diff_threshold",
 	// "bracket_width_threshold", or "max_iterations".
-	ExitReason string
+	ExitReason string `json:"exit_reason"`
 }
 
 // SolveDesignTime returns the Julian Day at which the Sun longitude
@@ -151,33 +166,61 @@ type Diagnostics struct {
 // to populate human_design.system.design_time_utc.  Tests that need
 // to inspect the iteration discipline use SolveDesignTimeWithDiagnostics
 // instead.
-func SolveDesignTime(birthJD float64, sun SunLongitudeFunc) (float64, error) {
-	jd, _, err := solveDesignTime(birthJD, sun)
+//
+// ctx is checked before every Sun evaluation, in the bracket and in
+// the bisection loop alike, so a cancelled request or an expired
+// deadline stops the solve within one ephemeris call.  The returned
+// error wraps ctx.Err().
+func SolveDesignTime(ctx context.Context, birthJD float64, sun SunLongitudeFunc) (float64, error) {
+	jd, _, err := solveDesignTime(ctx, birthJD, sun)
 	return jd, err
 }
 
-// SolveDesignTimeWithDiagnostics is the test-facing entry point.  It
-// returns the same Julian Day SolveDesignTime would, plus a
-// Diagnostics record summarising the solver's iteration discipline.
-// Production code never depends on the diagnostic values – the
-// canon does not require them in the response envelope.
-func SolveDesignTimeWithDiagnostics(birthJD float64, sun SunLongitudeFunc) (float64, Diagnostics, error) {
-	return solveDesignTime(birthJD, sun)
+// SolveDesignTimeWithDiagnostics returns the same Julian Day
+// SolveDesignTime would, plus a Diagnostics record summarising the
+// solver's iteration discipline.  The returned value never depends
+// on whether diagnostics are requested – the canon does not require
+// them in the response envelope, and the engine only uses them for
+// tests and metrics.
+func SolveDesignTimeWithDiagnostics(ctx context.Context, birthJD float64, sun SunLongitudeFunc) (float64, Diagnostics, error) {
+	return solveDesignTime(ctx, birthJD, sun)
 }
 
 // solveDesignTime is the shared implementation behind both public
 // entry points.
-func solveDesignTime(birthJD float64, sun SunLongitudeFunc) (float64, Diagnostics, error) {
+func solveDesignTime(ctx context.Context, birthJD float64, sun SunLongitudeFunc) (float64, Diagnostics, error) {
 	if sun == nil {
 		return 0, Diagnostics{}, errors.New("designtime: sun longitude function is nil")
 	}
 
 	diag := Diagnostics{}
 
-	birthSun := sun(birthJD)
+	if err := ctx.Err(); err != nil {
+		return 0, diag, fmt.Errorf("designtime: %w", err)
+	}
+	birthSun, err := sun(birthJD)
 	diag.SunFuncCalls++
+	if err != nil {
+		return 0, diag, fmt.Errorf("designtime: sun longitude at birth JD %.6f: %w", birthJD, err)
+	}
 	target := normalizeDeg(birthSun - SunOffsetDeg)
 
+	// diffAt evaluates the Sun at jd, counts the call, and returns
+	// its signed difference from the target.  Every bracket and
+	// bisection step goes through here, so this is where the solve
+	// honours cancellation.
+	diffAt := func(jd float64) (float64, error) {
+		if err := ctx.Err(); err != nil {
+			return 0, fmt.Errorf("designtime: abandoned at JD %.6f: %w", jd, err)
+		}
+		long, err := sun(jd)
+		diag.SunFuncCalls++
+		if err != nil {
+			return 0, fmt.Errorf("designtime: sun longitude at JD %.6f: %w", jd, err)
+		}
+		return signedDiffDeg(long, target), nil
+	}
+
 	// Initial bracket: ~88 days before birth, ±5 days.  Backward
 	// search direction is enforced by clamping the upper bound at
 	// or before birth.
@@ -187,10 +230,14 @@ func solveDesignTime(birthJD float64, sun SunLongitudeFunc) (float64, Diagnostic
 		upper = birthJD
 	}
 
-	diffLower := signedDiffDeg(sun(lower), target)
-	diag.SunFuncCalls++
-	diffUpper := signedDiffDeg(sun(upper), target)
-	diag.SunFuncCalls++
+	diffLower, err := diffAt(lower)
+	if err != nil {
+		return 0, diag, err
+	}
+	diffUpper, err := diffAt(upper)
+	if err != nil {
+		return 0, diag, err
+	}
 
 	if diffLower == 0 {
 		diag.FinalBracketDays = upper - lower
@@ -229,15 +276,17 @@ func solveDesignTime(birthJD float64, sun SunLongitudeFunc) (float64, Diagnostic
 	for diffLower*diffUpper > 0 && diag.BracketExpansions < maxBracketExpansion {
 		if diffLower > 0 {
 			lower -= bracketExpansionStepDays
-			diffLower = signedDiffDeg(sun(lower), target)
-			diag.SunFuncCalls++
+			if diffLower, err = diffAt(lower); err != nil {
+				return 0, diag, err
+			}
 		} else {
 			upper += bracketExpansionStepDays
 			if upper > birthJD {
 				upper = birthJD
 			}
-			diffUpper = signedDiffDeg(sun(upper), target)
-			diag.SunFuncCalls++
+			if diffUpper, err = diffAt(upper); err != nil {
+				return 0, diag, err
+			}
 		}
 		diag.BracketExpansions++
 	}
@@ -257,8 +306,10 @@ func solveDesignTime(birthJD float64, sun SunLongitudeFunc) (float64, Diagnostic
 	for i := 0; i < maxBisectionIterations; i++ {
 		diag.BracketIterations++
 		mid := (lower + upper) / 2.0
-		diff := signedDiffDeg(sun(mid), target)
-		diag.SunFuncCalls++
+		diff, err := diffAt(mid)
+		if err != nil {
+			return 0, diag, err
+		}
 
 		if math.Abs(diff) < StopAbsSunDiffDeg {
 			diag.FinalBracketDays = upper - lower
@@ -281,8 +332,10 @@ func solveDesignTime(birthJD float64, sun SunLongitudeFunc) (float64, Diagnostic
 			// We re-evaluate sun(lower) so FinalAbsDiffDeg in the
 			// diagnostics reflects the returned point, not the
 			// last-visited midpoint.
-			finalDiff := signedDiffDeg(sun(lower), target)
-			diag.SunFuncCalls++
+			finalDiff, err := diffAt(lower)
+			if err != nil {
+				return 0, diag, err
+			}
 			diag.FinalBracketDays = upper - lower
 			diag.FinalAbsDiffDeg = math.Abs(finalDiff)
 			diag.FinalLowerJD = lower
@@ -296,8 +349,10 @@ func solveDesignTime(birthJD float64, sun SunLongitudeFunc) (float64, Diagnostic
 	// maxBisectionIterations), so we return the lower bound for
 	// consistency with the canonical width-stop rule rather than
 	// inventing a midpoint convention here.
-	finalDiff := signedDiffDeg(sun(lower), target)
-	diag.SunFuncCalls++
+	finalDiff, err := diffAt(lower)
+	if err != nil {
+		return 0, diag, err
+	}
 	diag.FinalBracketDays = upper - lower
 	diag.FinalAbsDiffDeg = math.Abs(finalDiff)
 	diag.FinalLowerJD = lower
@@ -1,6 +1,8 @@
 package calc
 
 import (
+	"context"
+	"errors"
 	"math"
 	"sort"
 	"testing"
@@ -11,13 +13,13 @@ import (
 // wraps into [0, 360) on read.  Used by the synthetic tests below to
 // hit the bisection loop without pulling in Swiss Ephemeris.
 func linearSun(jd0, baseDeg, slopeDegPerDay float64) SunLongitudeFunc {
-	return func(jd float64) float64 {
+	return func(jd float64) (float64, error) {
 		raw := baseDeg + (jd-jd0)*slopeDegPerDay
 		r := math.Mod(raw, 360.0)
 		if r < 0 {
 			r += 360.0
 		}
-		return r
+		return r, nil
 	}
 }
 
@@ -30,7 +32,7 @@ type countingSun struct {
 	calls []float64
 }
 
-func (c *countingSun) sun(jd float64) float64 {
+func (c *countingSun) sun(jd float64) (float64, error) {
 	c.calls = append(c.calls, jd)
 	return c.inner(jd)
 }
@@ -48,7 +50,7 @@ func TestSolveDesignTimeRecoversLinearTarget(t *testing.T) {
 		toleranceDays = StopBracketSeconds / secondsPerDay
 	)
 	sun := linearSun(birthJD, baseDeg, slope)
-	got, diag, err := SolveDesignTimeWithDiagnostics(birthJD, sun)
+	got, diag, err := SolveDesignTimeWithDiagnostics(context.Background(), birthJD, sun)
 	if err != nil {
 		t.Fatalf("SolveDesignTime: %v", err)
 	}
@@ -81,7 +83,7 @@ func TestSolveDesignTimeMonotonicBracketShrinks(t *testing.T) {
 		slope   = 50.0
 	)
 	cs := &countingSun{inner: linearSun(birthJD, baseDeg, slope)}
-	_, diag, err := SolveDesignTimeWithDiagnostics(birthJD, cs.sun)
+	_, diag, err := SolveDesignTimeWithDiagnostics(context.Background(), birthJD, cs.sun)
 	if err != nil {
 		t.Fatalf("SolveDesignTime: %v", err)
 	}
@@ -191,7 +193,7 @@ func TestSolveDesignTimeSubSecondStopReturnsLowerBound(t *testing.T) {
 		slope   = 50.0
 	)
 	sun := linearSun(birthJD, baseDeg, slope)
-	got, diag, err := SolveDesignTimeWithDiagnostics(birthJD, sun)
+	got, diag, err := SolveDesignTimeWithDiagnostics(context.Background(), birthJD, sun)
 	if err != nil {
 		t.Fatalf("SolveDesignTime: %v", err)
 	}
@@ -242,7 +244,7 @@ func TestSolveDesignTimeRequiresManyIterations(t *testing.T) {
 		slope   = 50.0
 	)
 	cs := &countingSun{inner: linearSun(birthJD, baseDeg, slope)}
-	_, diag, err := SolveDesignTimeWithDiagnostics(birthJD, cs.sun)
+	_, diag, err := SolveDesignTimeWithDiagnostics(context.Background(), birthJD, cs.sun)
 	if err != nil {
 		t.Fatalf("SolveDesignTime: %v", err)
 	}
@@ -270,7 +272,7 @@ func TestSolveDesignTimeBackwardOnly(t *testing.T) {
 		slope   = 1.0
 	)
 	cs := &countingSun{inner: linearSun(birthJD, baseDeg, slope)}
-	_, _, err := SolveDesignTimeWithDiagnostics(birthJD, cs.sun)
+	_, _, err := SolveDesignTimeWithDiagnostics(context.Background(), birthJD, cs.sun)
 	if err != nil {
 		t.Fatalf("SolveDesignTime: %v", err)
 	}
@@ -290,11 +292,63 @@ func TestSolveDesignTimeBackwardOnly(t *testing.T) {
 // TestSolveDesignTimeRejectsNilSunFunc guards the public entry against
 // nil callback misuse, which would otherwise panic deep in the loop.
 func TestSolveDesignTimeRejectsNilSunFunc(t *testing.T) {
-	if _, err := SolveDesignTime(2447991.0, nil); err == nil {
+	if _, err := SolveDesignTime(context.Background(), 2447991.0, nil); err == nil {
 		t.Fatal("expected error for nil sun func; got nil")
 	}
 }
 
+// TestSolveDesignTimePropagatesSunFuncError proves an ephemeris
+// failure mid-bisection aborts the solve with the callback's error
+// preserved for errors.Is / errors.As, instead of silently
+// bisecting on a garbage longitude.
+func TestSolveDesignTimePropagatesSunFuncError(t *testing.T) {
+	const birthJD = 2447991.0
+	errEphemeris := errors.New("synthetic ephemeris failure")
+	inner := linearSun(birthJD, 100.0, 50.0)
+	calls := 0
+	sun := func(jd float64) (float64, error) {
+		calls++
+		if calls == 10 {
+			return 0, errEphemeris
+		}
+		return inner(jd)
+	}
+	_, diag, err := SolveDesignTimeWithDiagnostics(context.Background(), birthJD, sun)
+	if !errors.Is(err, errEphemeris) {
+		t.Fatalf("err = %v, want wrapped %v", err, errEphemeris)
+	}
+	if diag.SunFuncCalls != 10 {
+		t.Errorf("SunFuncCalls = %d, want 10 (solver must stop at the failing call)",
+			diag.SunFuncCalls)
+	}
+}
+
+// TestSolveDesignTimeHonoursCancellation cancels the context from
+// inside the Sun callback mid-bisection and proves the solver stops
+// at the next evaluation with context.Canceled in its error chain.
+func TestSolveDesignTimeHonoursCancellation(t *testing.T) {
+	const birthJD = 2447991.0
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	inner := linearSun(birthJD, 100.0, 0.97)
+	calls := 0
+	sun := func(jd float64) (float64, error) {
+		calls++
+		if calls == 5 {
+			cancel()
+		}
+		return inner(jd)
+	}
+	_, diag, err := SolveDesignTimeWithDiagnostics(ctx, birthJD, sun)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want wrapped context.Canceled", err)
+	}
+	if diag.SunFuncCalls != 5 {
+		t.Errorf("SunFuncCalls = %d, want 5 (solver must stop after cancellation)",
+			diag.SunFuncCalls)
+	}
+}
+
 func TestNormalizeDeg(t *testing.T) {
 	cases := []struct {
 		in, want float64
@@ -67,3 +67,21 @@ func MapToGateLine(longitudeDeg float64) (gate, line int) {
 
 	return canon.GateOrder[gateIndex], lineIndex + 1
 }
+
+// GateLineMargins returns how far longitudeDeg lies from the nearest
+// gate boundary and from the nearest line boundary, in degrees: the
+// smallest shift, in either direction, that would change the gate or
+// the line MapToGateLine returns.  Every gate boundary is also a
+// line boundary, so lineMargin <= gateMargin.  A longitude exactly
+// on a boundary has margin 0.
+func GateLineMargins(longitudeDeg float64) (gateMargin, lineMargin float64) {
+	r := normalizeDeg(longitudeDeg - canon.MandalaAnchorDeg)
+	return boundaryMargin(r, canon.GateWidthDeg), boundaryMargin(r, canon.LineWidthDeg)
+}
+
+// boundaryMargin is the distance from x to the nearest multiple of
+// width.
+func boundaryMargin(x, width float64) float64 {
+	off := math.Mod(x, width)
+	return math.Min(off, width-off)
+}
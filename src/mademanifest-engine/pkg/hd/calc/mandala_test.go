@@ -135,3 +135,44 @@ func TestMapToGateLineNoFractionalLeak(t *testing.T) {
 		t.Errorf("MapToGateLine(0.0) line = %d, must be in 1..6", line)
 	}
 }
+
+// TestGateLineMarginsMatchMapToGateLine checks the margins against
+// the lookup itself: shifting a longitude by just under its margin
+// either way keeps its gate and line, and shifting by just over
+// changes one of them.
+func TestGateLineMarginsMatchMapToGateLine(t *testing.T) {
+	if g, l := GateLineMargins(canon.MandalaAnchorDeg); g != 0 || l != 0 {
+		t.Errorf("GateLineMargins(anchor) = %v, %v; want 0, 0", g, l)
+	}
+	if g, l := GateLineMargins(canon.MandalaAnchorDeg + 1.5*canon.LineWidthDeg); math.Abs(g-1.5*canon.LineWidthDeg) > 1e-9 ||
+		math.Abs(l-0.5*canon.LineWidthDeg) > 1e-9 {
+		t.Errorf("GateLineMargins(anchor + 1.5 lines) = %v, %v", g, l)
+	}
+	const eps = 1e-7
+	for long := 0.05; long < 360; long += 0.37 {
+		gate, line := MapToGateLine(long)
+		gateMargin, lineMargin := GateLineMargins(long)
+		if lineMargin > gateMargin {
+			t.Fatalf("%v: line margin %v above gate margin %v", long, lineMargin, gateMargin)
+		}
+		if lineMargin < 2*eps {
+			continue // on a boundary; the sweep does not probe inside it
+		}
+		for _, d := range []float64{lineMargin - eps, -(lineMargin - eps)} {
+			if g, l := MapToGateLine(long + d); g != gate || l != line {
+				t.Fatalf("%v%+v: gate %d line %d changed from %d.%d within the margin", long, d, g, l, gate, line)
+			}
+		}
+		g1, l1 := MapToGateLine(long + lineMargin + eps)
+		g2, l2 := MapToGateLine(long - lineMargin - eps)
+		if (g1 == gate && l1 == line) && (g2 == gate && l2 == line) {
+			t.Fatalf("%v: neither side of margin %v changes gate %d line %d", long, lineMargin, gate, line)
+		}
+		if gateMargin > lineMargin+eps {
+			continue
+		}
+		if g1 == gate && g2 == gate {
+			t.Fatalf("%v: neither side of gate margin %v changes gate %d", long, gateMargin, gate)
+		}
+	}
+}
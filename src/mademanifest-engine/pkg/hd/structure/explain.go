@@ -0,0 +1,168 @@
+package structure
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"mademanifest-engine/pkg/canon"
+	"mademanifest-engine/pkg/trinity/output"
+)
+
+// Trace records how Compute derives definition, type and authority
+// from an activation set: the intermediate values of the decision
+// tree, so a disputed type or authority can be checked without
+// re-deriving it by hand.  It is diagnostic output and never part
+// of the canonical envelope.
+//
+// Explain builds it from the same helpers Compute calls
+// (activeChannels, connectedComponents, typeDecision,
+// authorityRules), so the trace cannot disagree with the envelope.
+type Trace struct {
+	// ActiveGates is the union of personality and design gates,
+	// ascending.
+	ActiveGates []int `json:"active_gates"`
+	// Channels are the canon channels whose two gates are active.
+	Channels []output.HDChannel `json:"channels"`
+	// DefinedCenters are the centers touched by an active channel,
+	// in canon.CenterOrder.
+	DefinedCenters []string `json:"defined_centers"`
+	// Components are the connected components of the defined
+	// centers, as connectedComponents returns them.
+	Components [][]string  `json:"components"`
+	Definition string      `json:"definition"`
+	Throat     ThroatTrace `json:"throat"`
+	// TypeStep is the step of the trinity.org type decision tree
+	// that fired, e.g. "2b: sacral defined, no motor connects to
+	// throat".
+	TypeStep string `json:"type_step"`
+	Type     string `json:"type"`
+	// AuthorityRules is the authority priority list, highest first,
+	// each evaluated for this chart; the first that holds fired.
+	AuthorityRules []AuthorityRuleTrace `json:"authority_rules"`
+	Authority      string               `json:"authority"`
+}
+
+// ThroatTrace is the motor-to-throat evidence behind the type.
+type ThroatTrace struct {
+	Defined bool `json:"defined"`
+	// Component is the component containing the throat; empty
+	// when the throat is undefined.
+	Component []string `json:"component"`
+	// Motors are the canon.MotorCenters inside that component, in
+	// canon.CenterOrder.
+	Motors []string `json:"motors"`
+}
+
+// AuthorityRuleTrace is one entry of the authority priority list.
+type AuthorityRuleTrace struct {
+	Authority string `json:"authority"`
+	Condition string `json:"condition"`
+	Holds     bool   `json:"holds"`
+	Fired     bool   `json:"fired"`
+}
+
+// Explain traces the structural derivation of an activation set.
+// Unlike Compute it does not need the four pillar activations: the
+// profile and incarnation cross are read straight off them and have
+// nothing to explain.
+func Explain(personality, design []output.HDActivation) Trace {
+	activeGates := activeGateSet(personality, design)
+	channels := activeChannels(activeGates)
+	defined := centerStateMap(channels)
+	components := connectedComponents(channels, defined)
+	hdType, step := typeDecision(defined, components)
+
+	t := Trace{
+		ActiveGates:    make([]int, 0, len(activeGates)),
+		Channels:       channels,
+		DefinedCenters: []string{},
+		Components:     components,
+		Definition:     definitionClass(len(components)),
+		Throat:         ThroatTrace{Component: []string{}, Motors: []string{}},
+		TypeStep:       step,
+		Type:           hdType,
+		AuthorityRules: make([]AuthorityRuleTrace, 0, len(authorityRules)),
+		Authority:      authorityFor(hdType, defined),
+	}
+	for g := range activeGates {
+		t.ActiveGates = append(t.ActiveGates, g)
+	}
+	sort.Ints(t.ActiveGates)
+	for _, id := range canon.CenterOrder {
+		if defined[id] {
+			t.DefinedCenters = append(t.DefinedCenters, id)
+		}
+	}
+	if i := componentContaining("throat", components); i >= 0 {
+		t.Throat.Defined = true
+		t.Throat.Component = components[i]
+		for _, c := range components[i] {
+			if isMotor(c) {
+				t.Throat.Motors = append(t.Throat.Motors, c)
+			}
+		}
+	}
+	fired := false
+	for _, r := range authorityRules {
+		holds := r.holds(hdType, defined)
+		t.AuthorityRules = append(t.AuthorityRules, AuthorityRuleTrace{
+			Authority: r.authority,
+			Condition: r.condition,
+			Holds:     holds,
+			Fired:     holds && !fired,
+		})
+		fired = fired || holds
+	}
+	return t
+}
+
+func isMotor(center string) bool {
+	for _, m := range canon.MotorCenters {
+		if center == m {
+			return true
+		}
+	}
+	return false
+}
+
+// Summary renders the trace as a few lines of plain text.
+func (t Trace) Summary() string {
+	var b strings.Builder
+	ids := make([]string, len(t.Channels))
+	for i, c := range t.Channels {
+		ids[i] = fmt.Sprintf("%s (%s-%s)", c.ChannelID, c.CenterA, c.CenterB)
+	}
+	fmt.Fprintf(&b, "Active channels: %s\n", orNone(strings.Join(ids, ", ")))
+	fmt.Fprintf(&b, "Defined centers: %s\n", orNone(strings.Join(t.DefinedCenters, ", ")))
+	comps := make([]string, len(t.Components))
+	for i, c := range t.Components {
+		comps[i] = "{" + strings.Join(c, ", ") + "}"
+	}
+	fmt.Fprintf(&b, "Definition: %s (%d component(s): %s)\n",
+		t.Definition, len(t.Components), orNone(strings.Join(comps, " ")))
+	switch {
+	case !t.Throat.Defined:
+		b.WriteString("Throat: undefined\n")
+	case len(t.Throat.Motors) == 0:
+		b.WriteString("Throat: defined, connected to no motor\n")
+	default:
+		fmt.Fprintf(&b, "Throat: defined, connected to motor(s) %s\n", strings.Join(t.Throat.Motors, ", "))
+	}
+	fmt.Fprintf(&b, "Type: %s (step %s)\n", t.Type, t.TypeStep)
+	for _, r := range t.AuthorityRules {
+		if r.Fired {
+			fmt.Fprintf(&b, "Authority: %s (first rule that holds: %s)\n", t.Authority, r.Condition)
+			return b.String()
+		}
+	}
+	fmt.Fprintf(&b, "Authority: %s (no rule holds; canon fallback)\n", t.Authority)
+	return b.String()
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
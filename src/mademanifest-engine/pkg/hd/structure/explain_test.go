@@ -0,0 +1,107 @@
+package structure
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestExplainManifestingGeneratorEmotional traces 21-45 (ego ↔
+// throat) plus 6-59 (solar_plexus ↔ sacral): the throat reaches the
+// ego motor and sacral is defined, so step 2a fires; emotional and
+// sacral both hold and only emotional, the higher rule, fires.
+func TestExplainManifestingGeneratorEmotional(t *testing.T) {
+	personality := gatesActivations(21, 6)
+	design := gatesActivations(45, 59, 11)
+	tr := Explain(personality, design)
+
+	if want := []int{6, 11, 21, 45, 59}; !reflect.DeepEqual(tr.ActiveGates, want) {
+		t.Errorf("ActiveGates = %v, want %v", tr.ActiveGates, want)
+	}
+	if len(tr.Channels) != 2 || tr.Definition != "split" {
+		t.Errorf("Channels = %+v, Definition = %q; want two channels, split", tr.Channels, tr.Definition)
+	}
+	want := ThroatTrace{Defined: true, Component: []string{"throat", "ego"}, Motors: []string{"ego"}}
+	if !reflect.DeepEqual(tr.Throat, want) {
+		t.Errorf("Throat = %+v, want %+v", tr.Throat, want)
+	}
+	if tr.Type != "manifesting_generator" || !strings.HasPrefix(tr.TypeStep, "2a:") {
+		t.Errorf("Type = %q by %q, want manifesting_generator by step 2a", tr.Type, tr.TypeStep)
+	}
+	var holding, firing []string
+	for _, r := range tr.AuthorityRules {
+		if r.Holds {
+			holding = append(holding, r.Authority)
+		}
+		if r.Fired {
+			firing = append(firing, r.Authority)
+		}
+	}
+	if !reflect.DeepEqual(holding, []string{"emotional", "sacral"}) ||
+		!reflect.DeepEqual(firing, []string{"emotional"}) || tr.Authority != "emotional" {
+		t.Errorf("authority rules hold %v, fire %v, authority %q", holding, firing, tr.Authority)
+	}
+	summary := tr.Summary()
+	for _, line := range []string{
+		"Definition: split (2 component(s): {throat, ego} {solar_plexus, sacral})",
+		"Throat: defined, connected to motor(s) ego",
+		"Authority: emotional (first rule that holds: solar_plexus defined)",
+	} {
+		if !strings.Contains(summary, line) {
+			t.Errorf("Summary lacks %q:\n%s", line, summary)
+		}
+	}
+}
+
+// TestExplainReflector: no channel, so every list is empty (not
+// null) and the lunar rule fires.
+func TestExplainReflector(t *testing.T) {
+	tr := Explain(gatesActivations(1, 2, 3), nil)
+	if len(tr.Channels) != 0 || tr.DefinedCenters == nil || tr.Components == nil ||
+		tr.Throat.Defined || tr.Throat.Motors == nil {
+		t.Errorf("trace = %+v", tr)
+	}
+	if tr.Type != "reflector" || tr.Authority != "lunar" || !strings.HasPrefix(tr.TypeStep, "1:") {
+		t.Errorf("Type %q by %q, authority %q", tr.Type, tr.TypeStep, tr.Authority)
+	}
+	if !strings.Contains(tr.Summary(), "Active channels: none") {
+		t.Errorf("Summary:\n%s", tr.Summary())
+	}
+}
+
+// TestExplainAgreesWithCompute draws random activation sets and
+// checks the trace reaches Compute's definition, type and authority
+// through exactly one fired rule.
+func TestExplainAgreesWithCompute(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		gates := make([]int, 4+rng.Intn(22))
+		for j := range gates {
+			gates[j] = 1 + rng.Intn(64)
+		}
+		personality, design := gatesActivations(gates[:2]...), gatesActivations(gates[2:]...)
+		res, err := Compute(personality, design)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tr := Explain(personality, design)
+		if !reflect.DeepEqual(tr.Channels, res.Channels) || tr.Definition != res.Definition ||
+			tr.Type != res.Type || tr.Authority != res.Authority {
+			t.Fatalf("gates %v: trace %s/%s/%s, Compute %s/%s/%s", gates,
+				tr.Definition, tr.Type, tr.Authority, res.Definition, res.Type, res.Authority)
+		}
+		fired := 0
+		for _, r := range tr.AuthorityRules {
+			if r.Fired {
+				fired++
+				if r.Authority != res.Authority {
+					t.Fatalf("gates %v: rule %q fired, authority %q", gates, r.Authority, res.Authority)
+				}
+			}
+		}
+		if fired != 1 {
+			t.Fatalf("gates %v: %d authority rules fired", gates, fired)
+		}
+	}
+}
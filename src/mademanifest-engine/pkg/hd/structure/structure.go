@@ -26,6 +26,18 @@ import (
 	"mademanifest-engine/pkg/trinity/output"
 )
 
+// The canonical value sets of the structural string fields, in the
+// order the package doc lists them.  They document what
+// definitionClass, typeFor, authorityFor and emitCenters can return
+// and feed the enumerations of the published OpenAPI contract.
+var (
+	CenterStates = []string{"defined", "undefined"}
+	Definitions  = []string{"none", "single", "split", "triple_split", "quadruple_split"}
+	Types        = []string{"reflector", "generator", "manifesting_generator", "manifestor", "projector"}
+	Authorities  = []string{"emotional", "sacral", "splenic", "ego_manifested",
+		"ego_projected", "self_projected", "mental", "lunar"}
+)
+
 // Result bundles the seven structural sub-fields the HTTP handler
 // drops into the success envelope.  Field order matches the canon
 // HumanDesignOut struct so Result can be applied to an envelope
@@ -71,18 +83,7 @@ func Compute(personality, design []output.HDActivation) (Result, error) {
 		return Result{}, fmt.Errorf("structure: missing design earth activation")
 	}
 
-	// Combined active gate set: union of personality and design
-	// gates.  Lines are irrelevant for channel detection; only gate
-	// numbers participate in the canonical channel table.
-	activeGates := make(map[int]bool, len(personality)+len(design))
-	for _, a := range personality {
-		activeGates[a.Gate] = true
-	}
-	for _, a := range design {
-		activeGates[a.Gate] = true
-	}
-
-	channels := activeChannels(activeGates)
+	channels := activeChannels(activeGateSet(personality, design))
 	centerStates := centerStateMap(channels)
 	centers := emitCenters(centerStates)
 	components := connectedComponents(channels, centerStates)
@@ -108,6 +109,21 @@ func Compute(personality, design []output.HDActivation) (Result, error) {
 	}, nil
 }
 
+// activeGateSet is the combined active gate set: the union of
+// personality and design gates.  Lines are irrelevant for channel
+// detection; only gate numbers participate in the canonical channel
+// table.
+func activeGateSet(personality, design []output.HDActivation) map[int]bool {
+	activeGates := make(map[int]bool, len(personality)+len(design))
+	for _, a := range personality {
+		activeGates[a.Gate] = true
+	}
+	for _, a := range design {
+		activeGates[a.Gate] = true
+	}
+	return activeGates
+}
+
 // byObjectID indexes an activation slice by its object_id.  The
 // activation arrays the engine produces always have unique
 // object_ids, so the map values are unambiguous.
@@ -283,51 +299,79 @@ func definitionClass(componentCount int) string {
 // ego), via a chain of active channels.  We compute that on the
 // component graph rather than re-walking the channel list.
 func typeFor(defined map[string]bool, components [][]string) string {
+	hdType, _ := typeDecision(defined, components)
+	return hdType
+}
+
+// typeDecision is typeFor together with the step of the decision
+// tree that produced the type, for Explain.
+func typeDecision(defined map[string]bool, components [][]string) (hdType, step string) {
 	if len(components) == 0 {
-		return "reflector"
+		return "reflector", "1: definition is none"
 	}
 	throatComponent := componentContaining("throat", components)
 	if defined["sacral"] {
 		if throatComponent >= 0 && componentContainsAnyMotor(components[throatComponent]) {
-			return "manifesting_generator"
+			return "manifesting_generator", "2a: sacral defined and a motor connects to throat"
 		}
-		return "generator"
+		return "generator", "2b: sacral defined, no motor connects to throat"
 	}
 	if throatComponent >= 0 && componentContainsNonSacralMotor(components[throatComponent]) {
-		return "manifestor"
+		return "manifestor", "3: sacral undefined and a non-sacral motor connects to throat"
 	}
-	return "projector"
+	return "projector", "4: sacral undefined, no motor connects to throat"
+}
+
+// authorityRule is one entry of the authority priority list.
+type authorityRule struct {
+	authority string
+	condition string // the rule in words, for Explain
+	holds     func(hdType string, defined map[string]bool) bool
+}
+
+// authorityRules is trinity.org §"Authority derivation priority"
+// (lines 326-334), highest priority first.
+var authorityRules = []authorityRule{
+	{"emotional", "solar_plexus defined", func(_ string, d map[string]bool) bool {
+		return d["solar_plexus"]
+	}},
+	{"sacral", "type is generator or manifesting_generator", func(t string, _ map[string]bool) bool {
+		return t == "generator" || t == "manifesting_generator"
+	}},
+	{"splenic", "spleen defined", func(_ string, d map[string]bool) bool {
+		return d["spleen"]
+	}},
+	{"ego_manifested", "type is manifestor and ego defined", func(t string, d map[string]bool) bool {
+		return t == "manifestor" && d["ego"]
+	}},
+	{"ego_projected", "type is projector and ego defined", func(t string, d map[string]bool) bool {
+		return t == "projector" && d["ego"]
+	}},
+	{"self_projected", "type is projector and g defined", func(t string, d map[string]bool) bool {
+		return t == "projector" && d["g"]
+	}},
+	{"mental", "type is projector", func(t string, _ map[string]bool) bool {
+		return t == "projector"
+	}},
+	{"lunar", "type is reflector", func(t string, _ map[string]bool) bool {
+		return t == "reflector"
+	}},
 }
 
 // authorityFor implements trinity.org §"Authority derivation
 // priority" (lines 326-334).  The first matching rule wins.
 func authorityFor(hdType string, defined map[string]bool) string {
-	switch {
-	case defined["solar_plexus"]:
-		return "emotional"
-	case hdType == "generator" || hdType == "manifesting_generator":
-		return "sacral"
-	case defined["spleen"]:
-		return "splenic"
-	case hdType == "manifestor" && defined["ego"]:
-		return "ego_manifested"
-	case hdType == "projector" && defined["ego"]:
-		return "ego_projected"
-	case hdType == "projector" && defined["g"]:
-		return "self_projected"
-	case hdType == "projector":
-		return "mental"
-	case hdType == "reflector":
-		return "lunar"
-	default:
-		// Per the canon priority list, this branch is unreachable:
-		// every type maps to at least one of the eight values
-		// above.  We fall back to "lunar" defensively to keep the
-		// output strictly inside the canon-allowed set if a future
-		// canon revision introduces a new type without updating
-		// this priority list.
-		return "lunar"
+	for _, r := range authorityRules {
+		if r.holds(hdType, defined) {
+			return r.authority
+		}
 	}
+	// Per the canon priority list this is unreachable: every type
+	// maps to at least one of the eight values above.  We fall back
+	// to "lunar" defensively to keep the output strictly inside the
+	// canon-allowed set if a future canon revision introduces a new
+	// type without updating this priority list.
+	return "lunar"
 }
 
 // componentContaining returns the index of the component containing
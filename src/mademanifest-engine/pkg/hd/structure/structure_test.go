@@ -493,6 +493,41 @@ func TestComputeMissingPillarErrors(t *testing.T) {
 	}
 }
 
+// TestStructuralValuesStayInPublishedSets drives the classifiers
+// over every input they branch on and checks each result is listed
+// in the exported value set the OpenAPI contract enumerates.
+func TestStructuralValuesStayInPublishedSets(t *testing.T) {
+	in := func(v string, set []string) bool {
+		for _, s := range set {
+			if v == s {
+				return true
+			}
+		}
+		return false
+	}
+	for n := 0; n <= 9; n++ {
+		if v := definitionClass(n); !in(v, Definitions) {
+			t.Errorf("definitionClass(%d) = %q, not in Definitions", n, v)
+		}
+	}
+	for _, hdType := range Types {
+		for mask := 0; mask < 1<<len(canon.CenterOrder); mask++ {
+			defined := map[string]bool{}
+			for i, c := range canon.CenterOrder {
+				defined[c] = mask&(1<<i) != 0
+			}
+			if v := authorityFor(hdType, defined); !in(v, Authorities) {
+				t.Fatalf("authorityFor(%s, %v) = %q, not in Authorities", hdType, defined, v)
+			}
+		}
+	}
+	for _, c := range emitCenters(map[string]bool{"g": true}) {
+		if !in(c.State, CenterStates) {
+			t.Errorf("center %s state %q not in CenterStates", c.CenterID, c.State)
+		}
+	}
+}
+
 // silence unused-helper warning when developing; requiredPillars is
 // kept for future tests that need a clean pillar foundation
 // distinct from the channel-driving gates.
@@ -0,0 +1,172 @@
+package httpservice
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// Admission defaults.  Every Swiss Ephemeris call is serialised on
+// the one ephemeris worker thread, so beyond a few concurrent
+// computations extra goroutines only lengthen the worker's queue:
+// DefaultMaxInFlight bounds the computations without letting the
+// backlog grow unbounded, while validation and cache hits, which run
+// before a slot is taken (admit), stay parallel.  A canonical
+// computation takes a few milliseconds, so DefaultMaxQueued waiters
+// drain well inside DefaultQueueWait on a healthy pod.  All three
+// are configurable per Handler (cmd/httpserver reads
+// TRINITY_MAX_IN_FLIGHT / TRINITY_MAX_QUEUED / TRINITY_QUEUE_WAIT).
+const (
+	DefaultMaxInFlight = 4
+	DefaultMaxQueued   = 32
+	DefaultQueueWait   = time.Second
+)
+
+// Shed reasons, the reason label of
+// mademanifest_admission_rejections_total.
+const (
+	shedQueueFull    = "queue_full"
+	shedQueueTimeout = "queue_timeout"
+)
+
+// Admission bounds the manifest computations one process runs at a
+// time.  At most maxInFlight computations hold a slot; up to
+// maxQueued more wait for one, each for at most wait.  A request
+// that finds the queue full, or whose wait expires, is shed with an
+// *OverloadError, which /manifest answers with HTTP 503, an
+// execution_failure envelope and a Retry-After header – a fast,
+// explicit refusal the client or the ingress can retry elsewhere,
+// instead of a goroutine pile-up that ends in timeouts.
+//
+// /manifest requests and /manifest/batch items compete for the same
+// slots; /readyz never does, so a saturated pod stays ready and the
+// HPA, not the readiness probe, absorbs the load.  No slot is taken
+// before the request body has been read (Handler.run), so a slow
+// upload never holds one.  The Trinity processors take theirs once
+// the payload has been validated and missed the response cache
+// (admit): a rejected or cached payload never waits for one.  A
+// custom Processor is admitted before it runs (admitted).
+//
+// A nil *Admission admits everything.
+type Admission struct {
+	slots     chan struct{}
+	queued    atomic.Int64
+	maxQueued int64
+	wait      time.Duration
+}
+
+// NewAdmission returns a limiter admitting maxInFlight concurrent
+// computations with maxQueued waiters of at most wait each, or nil
+// (no limit) when maxInFlight <= 0.
+func NewAdmission(maxInFlight, maxQueued int, wait time.Duration) *Admission {
+	if maxInFlight <= 0 {
+		return nil
+	}
+	return &Admission{
+		slots:     make(chan struct{}, maxInFlight),
+		maxQueued: int64(max(maxQueued, 0)),
+		wait:      wait,
+	}
+}
+
+// OverloadError is the Processor-level error of a shed request.
+// RetryAfter is the hint sent in the Retry-After header.
+type OverloadError struct {
+	Reason     string
+	RetryAfter time.Duration
+}
+
+func (e *OverloadError) Error() string {
+	switch e.Reason {
+	case shedQueueFull:
+		return "engine at capacity: admission queue full"
+	default:
+		return "engine at capacity: no computation slot freed within the queue wait"
+	}
+}
+
+// retryAfterSeconds renders RetryAfter as the delta-seconds form of
+// the Retry-After header, at least 1.
+func (e *OverloadError) retryAfterSeconds() string {
+	return fmt.Sprint(max(1, int(math.Ceil(e.RetryAfter.Seconds()))))
+}
+
+// acquire takes a computation slot, queueing for at most a.wait.
+// The returned release must be called once the computation is
+// done.  A done ctx ends the wait with ctx's error, which the
+// handler already answers with 503.
+func (a *Admission) acquire(ctx context.Context) (release func(), err error) {
+	if a == nil {
+		return func() {}, nil
+	}
+	release = func() {
+		<-a.slots
+		defaultMetrics.admissionInFlight.Add(-1)
+	}
+	select {
+	case a.slots <- struct{}{}:
+		defaultMetrics.admissionInFlight.Add(1)
+		return release, nil
+	default:
+	}
+
+	if a.queued.Add(1) > a.maxQueued {
+		a.queued.Add(-1)
+		return nil, a.shed(shedQueueFull)
+	}
+	defaultMetrics.admissionQueued.Add(1)
+	defer func() {
+		a.queued.Add(-1)
+		defaultMetrics.admissionQueued.Add(-1)
+	}()
+	timer := time.NewTimer(a.wait)
+	defer timer.Stop()
+	select {
+	case a.slots <- struct{}{}:
+		defaultMetrics.admissionInFlight.Add(1)
+		return release, nil
+	case <-timer.C:
+		return nil, a.shed(shedQueueTimeout)
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for a computation slot: %w", ctx.Err())
+	}
+}
+
+type admissionCtxKey struct{}
+
+// withAdmission attaches a to ctx for the Processor of the request,
+// like withCache.
+func withAdmission(ctx context.Context, a *Admission) context.Context {
+	return context.WithValue(ctx, admissionCtxKey{}, a)
+}
+
+// admit takes a computation slot from the limiter attached to ctx
+// (withAdmission), or admits at once when there is none.  The
+// Trinity processors call it once the payload has been validated.
+func admit(ctx context.Context) (release func(), err error) {
+	a, _ := ctx.Value(admissionCtxKey{}).(*Admission)
+	return a.acquire(ctx)
+}
+
+// admitted returns p holding a computation slot while it runs, for
+// a Processor that does not call admit itself.  p sees no limiter,
+// so a custom Process wrapping a Trinity processor takes one slot,
+// not two.
+func admitted(p Processor) Processor {
+	return func(ctx context.Context, bodyReader io.Reader) ([]byte, int, error) {
+		release, err := admit(ctx)
+		if err != nil {
+			return nil, 0, err
+		}
+		defer release()
+		return p(withAdmission(ctx, nil), bodyReader)
+	}
+}
+
+func (a *Admission) shed(reason string) error {
+	defaultMetrics.admissionRejections.Inc(reason)
+	return &OverloadError{Reason: reason, RetryAfter: a.wait}
+}
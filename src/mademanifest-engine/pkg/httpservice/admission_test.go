@@ -0,0 +1,303 @@
+package httpservice
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"mademanifest-engine/pkg/trinity/output"
+)
+
+// gatedProcessor is a Processor that reports each call on started
+// and blocks until release is closed.
+func gatedProcessor() (p Processor, started chan struct{}, release chan struct{}) {
+	started, release = make(chan struct{}, 16), make(chan struct{})
+	p = func(ctx context.Context, r io.Reader) ([]byte, int, error) {
+		started <- struct{}{}
+		<-release
+		return []byte(`{"status":"success"}`), http.StatusOK, nil
+	}
+	return p, started, release
+}
+
+// postAsync issues POST /manifest on h in the background.
+func postAsync(h Handler) <-chan *httptest.ResponseRecorder {
+	done := make(chan *httptest.ResponseRecorder, 1)
+	go func() {
+		req := httptest.NewRequest(http.MethodPost, "/manifest", strings.NewReader(canonicalBaseline))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		h.handleManifest(rec, req)
+		done <- rec
+	}()
+	return done
+}
+
+// assertShed checks a /manifest answer is the 503 overload refusal.
+func assertShed(t *testing.T, rec *httptest.ResponseRecorder, retryAfter string) {
+	t.Helper()
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503; body %s", rec.Code, rec.Body)
+	}
+	if got := rec.Header().Get("Retry-After"); got != retryAfter {
+		t.Errorf("Retry-After = %q, want %q", got, retryAfter)
+	}
+	var env output.ErrorEnvelope
+	if err := json.Unmarshal(rec.Body.Bytes(), &env); err != nil {
+		t.Fatalf("decode envelope: %v", err)
+	}
+	if env.Status != output.StatusError || env.Error.Type != output.ErrorExecutionFailure ||
+		!strings.Contains(env.Error.Message, "capacity") {
+		t.Errorf("envelope = %+v, want an execution_failure naming the capacity limit", env)
+	}
+}
+
+// TestAdmissionShedsWhenQueueIsFull fills the only slot, leaves no
+// queue, and checks the next request is refused at once – with the
+// rejection counted – while the admitted one completes.
+func TestAdmissionShedsWhenQueueIsFull(t *testing.T) {
+	mux := http.NewServeMux()
+	Handler{}.Register(mux)
+	process, started, release := gatedProcessor()
+	h := Handler{Process: process, Admission: NewAdmission(1, 0, 3*time.Second)}
+
+	first := postAsync(h)
+	<-started
+	before := scrape(t, mux)
+	assertShed(t, <-postAsync(h), "3")
+	after := scrape(t, mux)
+	close(release)
+	if rec := <-first; rec.Code != http.StatusOK {
+		t.Errorf("admitted request: status = %d", rec.Code)
+	}
+
+	for series, want := range map[string]float64{
+		`mademanifest_admission_rejections_total{reason="queue_full"}`:                                     1,
+		`mademanifest_manifest_results_total{route="/manifest",code="503",error_type="execution_failure"}`: 1,
+	} {
+		if got := after[series] - before[series]; got != want {
+			t.Errorf("%s moved by %v, want %v", series, got, want)
+		}
+	}
+}
+
+// TestAdmissionQueuedRequestTakesFreedSlot: a waiter inside the
+// queue bound is admitted as soon as the running computation ends.
+func TestAdmissionQueuedRequestTakesFreedSlot(t *testing.T) {
+	process, started, release := gatedProcessor()
+	h := Handler{Process: process, Admission: NewAdmission(1, 1, 5*time.Second)}
+
+	first := postAsync(h)
+	<-started
+	second := postAsync(h)
+	for h.Admission.queued.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case <-started:
+		t.Fatal("queued request ran while the slot was held")
+	default:
+	}
+	close(release)
+	for _, done := range []<-chan *httptest.ResponseRecorder{first, second} {
+		if rec := <-done; rec.Code != http.StatusOK {
+			t.Errorf("status = %d, want 200", rec.Code)
+		}
+	}
+}
+
+// TestAdmissionShedsAfterQueueWait: a waiter whose slot does not
+// free up within the queue wait is refused, and the Retry-After
+// hint rounds the sub-second wait up to one second.
+func TestAdmissionShedsAfterQueueWait(t *testing.T) {
+	process, started, release := gatedProcessor()
+	defer close(release)
+	h := Handler{Process: process, Admission: NewAdmission(1, 4, 20*time.Millisecond)}
+
+	postAsync(h)
+	<-started
+	assertShed(t, <-postAsync(h), "1")
+	if n := h.Admission.queued.Load(); n != 0 {
+		t.Errorf("queue holds %d waiters after the timeout, want 0", n)
+	}
+}
+
+// TestAdmissionWaitHonoursContext: a request whose context ends
+// while queued gets the context error, not an overload.
+func TestAdmissionWaitHonoursContext(t *testing.T) {
+	a := NewAdmission(1, 1, time.Minute)
+	release, err := a.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = a.acquire(ctx)
+	var shed *OverloadError
+	if !errors.Is(err, context.Canceled) || errors.As(err, &shed) {
+		t.Errorf("acquire = %v, want context.Canceled", err)
+	}
+
+	if NewAdmission(0, 0, 0) != nil {
+		t.Error("NewAdmission(0, …) is not the unlimited nil limiter")
+	}
+	var unlimited *Admission
+	for i := 0; i < 3; i++ {
+		if _, err := unlimited.acquire(context.Background()); err != nil {
+			t.Fatalf("nil limiter refused: %v", err)
+		}
+	}
+}
+
+// TestManifestBatchReportsShedItem: a batch item refused for
+// capacity is reported in-band with http_status 503.
+func TestManifestBatchReportsShedItem(t *testing.T) {
+	process, started, release := gatedProcessor()
+	defer close(release)
+	h := Handler{Process: process, Admission: NewAdmission(1, 0, time.Second)}
+	postAsync(h)
+	<-started
+
+	payload := `{"birth_date":"1990-04-09","birth_time":"18:04","timezone":"Europe/Amsterdam",` +
+		`"latitude":51.9167,"longitude":4.4}`
+	rec := postBatch(t, h, NDJSONContentType, `{"id":"a","payload":`+payload+`}`+"\n")
+	results := decodeBatch(t, rec.Body.Bytes())
+	if len(results) != 1 || results[0].HTTPStatus != http.StatusServiceUnavailable ||
+		envelopeErrorType(t, results[0]) != output.ErrorExecutionFailure {
+		t.Fatalf("batch results = %+v, want one 503 execution_failure", results)
+	}
+}
+
+// stalledBody is a request body that blocks its first Read, after
+// reporting it on reading, until resume is closed, then yields
+// canonicalBaseline.
+type stalledBody struct {
+	reading chan struct{}
+	resume  chan struct{}
+	body    io.Reader
+}
+
+func (b *stalledBody) Read(p []byte) (int, error) {
+	if b.body == nil {
+		close(b.reading)
+		<-b.resume
+		b.body = strings.NewReader(canonicalBaseline)
+	}
+	return b.body.Read(p)
+}
+
+// TestAdmissionSkipsStalledUpload: a request whose body is still
+// uploading holds no slot, so with one slot and no queue the next
+// request is admitted rather than shed; the stalled one is admitted
+// once its body arrives.  A payload the validator rejects is
+// answered while the slot is held.
+func TestAdmissionSkipsStalledUpload(t *testing.T) {
+	process, started, release := gatedProcessor()
+	h := Handler{Process: process, Admission: NewAdmission(1, 0, time.Second)}
+
+	body := &stalledBody{reading: make(chan struct{}), resume: make(chan struct{})}
+	stalled := make(chan *httptest.ResponseRecorder, 1)
+	go func() {
+		req := httptest.NewRequest(http.MethodPost, "/manifest", body)
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		h.handleManifest(rec, req)
+		stalled <- rec
+	}()
+	select {
+	case <-body.reading:
+	case <-started:
+		t.Fatal("request admitted before its body was read")
+	}
+
+	admitted := postAsync(h)
+	select {
+	case <-started:
+	case rec := <-admitted:
+		t.Fatalf("request behind a stalled upload: status %d %s", rec.Code, rec.Body)
+	}
+
+	rejected := Handler{Ephemeris: goldenReplay(t), Admission: h.Admission}
+	req := httptest.NewRequest(http.MethodPost, "/manifest", strings.NewReader(`{"birth_date": "1990-04-09"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	rejected.handleManifest(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("rejected payload with the slot held: status %d %s", rec.Code, rec.Body)
+	}
+
+	close(release)
+	if rec := <-admitted; rec.Code != http.StatusOK {
+		t.Errorf("admitted request: status = %d, want 200; body %s", rec.Code, rec.Body)
+	}
+	close(body.resume)
+	if rec := <-stalled; rec.Code != http.StatusOK {
+		t.Errorf("stalled request: status = %d, want 200; body %s", rec.Code, rec.Body)
+	}
+}
+
+// TestAdmissionLimitsCustomProcessor: a custom Process cannot say
+// when its payload is valid, so it holds a slot for the whole call
+// whatever the body; a body the Trinity validator rejects is shed
+// like any other, without the processor running.
+func TestAdmissionLimitsCustomProcessor(t *testing.T) {
+	process, started, release := gatedProcessor()
+	h := Handler{Process: process, Admission: NewAdmission(1, 0, time.Second)}
+
+	first := postAsync(h)
+	<-started
+	assertShed(t, postManifestQuery(t, h, "", `{"birth_date": "1990-04-09"}`), "1")
+	select {
+	case <-started:
+		t.Error("shed request ran the processor")
+	default:
+	}
+	close(release)
+	if rec := <-first; rec.Code != http.StatusOK {
+		t.Errorf("admitted request: status = %d", rec.Code)
+	}
+}
+
+// TestAdmissionSkipsCacheHits: with every slot taken, a payload the
+// response cache holds is still answered – its 304 revalidation
+// included – while one it does not hold is shed.
+func TestAdmissionSkipsCacheHits(t *testing.T) {
+	h := Handler{
+		Ephemeris: goldenReplay(t),
+		Cache:     NewResponseCache(8),
+		Admission: NewAdmission(1, 0, time.Second),
+	}
+	warm := postManifestQuery(t, h, "", canonicalBaseline)
+	if warm.Code != http.StatusOK {
+		t.Fatalf("warm-up: status %d %s", warm.Code, warm.Body)
+	}
+	etag := warm.Header().Get("ETag")
+
+	hold, err := h.Admission.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hold()
+
+	if rec := postManifestQuery(t, h, "", canonicalBaseline); rec.Code != http.StatusOK ||
+		!bytes.Equal(rec.Body.Bytes(), warm.Body.Bytes()) {
+		t.Errorf("cached payload with the limiter full: status %d %s", rec.Code, rec.Body)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/manifest", strings.NewReader(canonicalBaseline))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-None-Match", etag)
+	rec := httptest.NewRecorder()
+	h.handleManifest(rec, req)
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("revalidation with the limiter full: status %d %s", rec.Code, rec.Body)
+	}
+	assertShed(t, postManifestQuery(t, h, "sections=astrology", canonicalBaseline), "1")
+}
@@ -0,0 +1,411 @@
+package httpservice
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"time"
+
+	"mademanifest-engine/pkg/trinity/output"
+)
+
+// Batch defaults.  DefaultBatchMaxItems bounds the number of
+// payloads one POST /manifest/batch request may carry;
+// DefaultBatchMaxBytes bounds its body the way MaxRequestBodyBytes
+// bounds /manifest.  Each item is additionally held to
+// MaxRequestBodyBytes, so an item accepted by the batch endpoint is
+// one /manifest would also accept.  Both batch caps are
+// configurable per Handler (cmd/httpserver reads
+// TRINITY_BATCH_MAX_ITEMS / TRINITY_BATCH_MAX_BYTES).
+const (
+	DefaultBatchMaxItems       = 1000
+	DefaultBatchMaxBytes int64 = 64 << 20
+)
+
+// batchDeadlineSlack is added to the request timeout when a batch
+// pushes its connection deadlines out before each item; it covers
+// reading the item and writing its result line.
+const batchDeadlineSlack = 10 * time.Second
+
+// NDJSONContentType is the media type of newline-delimited JSON:
+// accepted as a batch request body and always used for the batch
+// response.
+const NDJSONContentType = "application/x-ndjson"
+
+// BatchItem is one entry of a batch request: the caller's
+// correlation id and a canonical Trinity payload.  The payload is
+// kept raw so it goes through input.Validate exactly as a /manifest
+// body would; id is optional and echoed verbatim.
+type BatchItem struct {
+	ID      string          `json:"id,omitempty"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// BatchResult is one line of the NDJSON batch response.  Index is
+// the zero-based position of the item in the request, so callers
+// without ids can still correlate; HTTPStatus is the status
+// /manifest would have answered for the same payload; Envelope is
+// the unmodified Trinity success or error envelope.
+type BatchResult struct {
+	Index      int             `json:"index"`
+	ID         string          `json:"id,omitempty"`
+	HTTPStatus int             `json:"http_status"`
+	Envelope   json.RawMessage `json:"envelope"`
+}
+
+func (h Handler) batchMaxItems() int {
+	if h.BatchMaxItems > 0 {
+		return h.BatchMaxItems
+	}
+	return DefaultBatchMaxItems
+}
+
+func (h Handler) batchMaxBytes() int64 {
+	if h.BatchMaxBytes > 0 {
+		return h.BatchMaxBytes
+	}
+	return DefaultBatchMaxBytes
+}
+
+// handleManifestBatch serves POST /manifest/batch.  The body is
+// either a JSON array of BatchItem (Content-Type application/json)
+// or an NDJSON stream with one BatchItem per line (Content-Type
+// application/x-ndjson).  Items are processed in order through the
+// same Processor as /manifest and one BatchResult line is streamed
+// back per item as soon as it is computed.
+//
+// A ?sections= selector applies to every item, exactly as it would
+// to each payload posted to /manifest.
+//
+// Request-level problems detected before streaming starts (method,
+// Content-Type, the sections selector, an application/json body
+// that is not an array) are answered like /manifest, with a single
+// error envelope.  Once the first result line is written the HTTP
+// status is 200 and every later problem is reported in-band: a
+// malformed or invalid item yields an error envelope on its own line
+// and the batch continues; exceeding the item or byte cap, or an
+// array that stops parsing, yields a final unsupported_input /
+// invalid_input line and ends the batch.
+//
+// Each item runs under its own RequestTimeout deadline and competes
+// for an admission slot like a /manifest request; a shed item is
+// reported in-band with http_status 503.  The server's read and
+// write timeouts are sized for one /manifest exchange, so before
+// each item the connection deadlines are pushed out by one request
+// timeout plus batchDeadlineSlack: a long batch keeps streaming
+// while a stalled one still times out.  A client that disconnects
+// ends the batch before the next item.
+func (h Handler) handleManifestBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+	defer r.Body.Close()
+
+	mediaType, msg, ok := batchContentType(r)
+	if !ok {
+		env := output.NewError(output.ErrorInvalidInput, msg)
+		defaultMetrics.observeResult("/manifest/batch", http.StatusUnsupportedMediaType, env.Error.Type)
+		writeJSON(w, http.StatusUnsupportedMediaType, env)
+		return
+	}
+	sections, err := parseSections(r.URL.Query())
+	if err != nil {
+		env := output.NewError(output.ErrorInvalidInput, err.Error())
+		defaultMetrics.observeResult("/manifest/batch", http.StatusBadRequest, env.Error.Type)
+		writeJSON(w, http.StatusBadRequest, env)
+		return
+	}
+
+	body := http.MaxBytesReader(w, r.Body, h.batchMaxBytes())
+	var next func() (raw []byte, err error)
+	if mediaType == NDJSONContentType {
+		next = ndjsonItems(bufio.NewReader(body))
+	} else {
+		// The array form can be rejected as a whole before any
+		// result is streamed, so a non-array body gets a plain
+		// error envelope and a real HTTP status.
+		dec := json.NewDecoder(body)
+		if err := expectArrayStart(dec); err != nil {
+			res := streamFailure(0, err)
+			defaultMetrics.observeEnvelope("/manifest/batch", res.HTTPStatus, res.Envelope)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(res.HTTPStatus)
+			w.Write(append(res.Envelope, '\n'))
+			return
+		}
+		next = arrayItems(dec)
+	}
+
+	// Results are streamed while the body is still being read.
+	// Without full duplex the HTTP/1 server drains (and then
+	// closes) the unread request body as soon as the first result
+	// is written.  HTTP/2 is always full duplex and reports
+	// ErrNotSupported, which is harmless.
+	rc := http.NewResponseController(w)
+	_ = rc.EnableFullDuplex()
+	w.Header().Set("Content-Type", NDJSONContentType)
+	w.WriteHeader(http.StatusOK)
+	out := &batchWriter{w: w, enc: json.NewEncoder(w)}
+	out.flusher, _ = w.(http.Flusher)
+
+	ctx := withCache(withSections(r.Context(), sections), h.Cache)
+	maxItems := h.batchMaxItems()
+	for index := 0; ; index++ {
+		if err := ctx.Err(); err != nil {
+			log.Printf("manifest batch abandoned before item %d: %v", index, err)
+			return
+		}
+		// httptest recorders do not support deadlines; a real
+		// connection always does.
+		deadline := time.Now().Add(h.requestTimeout() + batchDeadlineSlack)
+		_ = rc.SetReadDeadline(deadline)
+		_ = rc.SetWriteDeadline(deadline)
+
+		raw, err := next()
+		if errors.Is(err, io.EOF) {
+			return
+		}
+		if index >= maxItems {
+			out.fail(index, "", output.ErrorUnsupportedInput, http.StatusRequestEntityTooLarge,
+				fmt.Sprintf("batch exceeds %d-item limit; remaining items were not processed", maxItems))
+			return
+		}
+		var itemErr *batchItemError
+		switch {
+		case errors.As(err, &itemErr):
+			// One bad NDJSON line: report it and keep going.
+			out.fail(index, "", itemErr.errType, itemErr.status, itemErr.msg)
+			continue
+		case err != nil:
+			// The stream itself is broken (body cap hit, array
+			// syntax error, read failure): nothing after this
+			// point can be attributed to an item.
+			out.write(streamFailure(index, err))
+			return
+		}
+		out.write(h.processBatchItem(ctx, index, raw))
+	}
+}
+
+// processBatchItem decodes one BatchItem and runs its payload
+// through the Processor under its own RequestTimeout deadline,
+// recovering from panics so one poisoned payload cannot abort the
+// rest of the batch.
+func (h Handler) processBatchItem(ctx context.Context, index int, raw []byte) (res BatchResult) {
+	var item BatchItem
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&item); err != nil {
+		// Echo the id when it is readable so the caller can still
+		// correlate the rejection.
+		var probe struct {
+			ID string `json:"id"`
+		}
+		_ = json.Unmarshal(raw, &probe)
+		return failedResult(index, probe.ID, output.ErrorInvalidInput, http.StatusBadRequest,
+			"batch item must be an object {\"id\": string, \"payload\": object}: "+err.Error())
+	}
+	if len(item.Payload) == 0 {
+		return failedResult(index, item.ID, output.ErrorIncompleteInput, http.StatusBadRequest,
+			"batch item is missing payload")
+	}
+	if len(item.Payload) > MaxRequestBodyBytes {
+		return failedResult(index, item.ID, output.ErrorUnsupportedInput, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("batch item payload exceeds %d-byte limit", MaxRequestBodyBytes))
+	}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			log.Printf("manifest batch item %d panic: %v", index, recovered)
+			res = failedResult(index, item.ID, output.ErrorExecutionFailure,
+				http.StatusInternalServerError, "internal processing error")
+		}
+	}()
+	ctx, cancel := context.WithTimeout(ctx, h.requestTimeout())
+	defer cancel()
+	body, status, err := h.compute(ctx, bytes.NewReader(item.Payload))
+	if err != nil {
+		env, failStatus := failureEnvelope("/manifest/batch", err)
+		return BatchResult{Index: index, ID: item.ID, HTTPStatus: failStatus, Envelope: mustMarshal(env)}
+	}
+	return BatchResult{Index: index, ID: item.ID, HTTPStatus: status, Envelope: body}
+}
+
+// streamFailure reports a problem with the batch body itself at
+// position index: the byte cap (413 + unsupported_input, as on
+// /manifest) or unparseable framing (400 + invalid_input).
+func streamFailure(index int, err error) BatchResult {
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		return failedResult(index, "", output.ErrorUnsupportedInput, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("batch body exceeds %d-byte limit; remaining items were not processed", maxErr.Limit))
+	}
+	return failedResult(index, "", output.ErrorInvalidInput, http.StatusBadRequest,
+		"batch body unreadable: "+err.Error())
+}
+
+// batchItemError is a per-item framing problem (an over-long NDJSON
+// line) that does not invalidate the rest of the stream.
+type batchItemError struct {
+	errType string
+	status  int
+	msg     string
+}
+
+func (e *batchItemError) Error() string { return e.msg }
+
+// ndjsonItems yields one raw item per non-blank line.  Lines longer
+// than MaxRequestBodyBytes are skipped and reported as a
+// batchItemError so the stream stays in sync.
+func ndjsonItems(br *bufio.Reader) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		for {
+			line, tooLong, err := readLine(br, MaxRequestBodyBytes)
+			if err != nil && !errors.Is(err, io.EOF) {
+				return nil, err
+			}
+			if tooLong {
+				return nil, &batchItemError{
+					errType: output.ErrorUnsupportedInput,
+					status:  http.StatusRequestEntityTooLarge,
+					msg:     fmt.Sprintf("batch line exceeds %d-byte limit", MaxRequestBodyBytes),
+				}
+			}
+			if len(bytes.TrimSpace(line)) > 0 {
+				return line, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// readLine reads up to the next '\n'.  When the line exceeds max
+// bytes the remainder is discarded and tooLong is set.  A final
+// line without a trailing newline is returned with a nil error; the
+// following call reports io.EOF.
+func readLine(br *bufio.Reader, max int) (line []byte, tooLong bool, err error) {
+	for {
+		chunk, err := br.ReadSlice('\n')
+		if !tooLong {
+			line = append(line, chunk...)
+			if len(line) > max+1 {
+				tooLong, line = true, nil
+			}
+		}
+		switch {
+		case errors.Is(err, bufio.ErrBufferFull):
+			continue
+		case errors.Is(err, io.EOF) && len(line) > 0:
+			return line, tooLong, nil
+		default:
+			return line, tooLong, err
+		}
+	}
+}
+
+// expectArrayStart consumes the opening '[' of the array form.
+func expectArrayStart(dec *json.Decoder) error {
+	tok, err := dec.Token()
+	if errors.Is(err, io.EOF) {
+		return errors.New("empty body; expected a JSON array of batch items")
+	}
+	if err != nil {
+		return err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return errors.New("application/json batch body must be a JSON array")
+	}
+	return nil
+}
+
+// arrayItems yields the elements of a top-level JSON array whose
+// opening '[' expectArrayStart has consumed.  Any syntax error ends
+// the batch: a JSON decoder cannot resynchronise after one.
+func arrayItems(dec *json.Decoder) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		if !dec.More() {
+			if _, err := dec.Token(); err != nil {
+				return nil, err
+			}
+			return nil, io.EOF
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		return raw, nil
+	}
+}
+
+// batchContentType accepts application/json (array form) and
+// application/x-ndjson (stream form), with optional parameters.
+func batchContentType(r *http.Request) (mediaType, msg string, ok bool) {
+	ct := r.Header.Get("Content-Type")
+	if ct == "" {
+		return "", "Content-Type header is required; expected application/json or " +
+			NDJSONContentType, false
+	}
+	mediaType, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return "", fmt.Sprintf("Content-Type %q is not parseable: %s", ct, err.Error()), false
+	}
+	if mediaType != "application/json" && mediaType != NDJSONContentType {
+		return "", fmt.Sprintf("Content-Type %q is not supported; expected application/json or %s",
+			mediaType, NDJSONContentType), false
+	}
+	return mediaType, "", true
+}
+
+// batchWriter streams BatchResult lines, flushing after each so
+// callers see results while the batch is still running.  Every line
+// is counted in mademanifest_manifest_results_total.
+type batchWriter struct {
+	w       io.Writer
+	enc     *json.Encoder
+	flusher http.Flusher
+}
+
+func (b *batchWriter) write(res BatchResult) {
+	defaultMetrics.observeEnvelope("/manifest/batch", res.HTTPStatus, res.Envelope)
+	if err := b.enc.Encode(res); err != nil {
+		log.Printf("write batch result %d: %v", res.Index, err)
+		return
+	}
+	if b.flusher != nil {
+		b.flusher.Flush()
+	}
+}
+
+func (b *batchWriter) fail(index int, id, errType string, status int, msg string) {
+	b.write(failedResult(index, id, errType, status, msg))
+}
+
+func failedResult(index int, id, errType string, status int, msg string) BatchResult {
+	return BatchResult{
+		Index:      index,
+		ID:         id,
+		HTTPStatus: status,
+		Envelope:   mustMarshal(output.NewError(errType, msg)),
+	}
+}
+
+// mustMarshal encodes an error envelope.  ErrorEnvelope holds only
+// strings, so Marshal cannot fail.
+func mustMarshal(env output.ErrorEnvelope) json.RawMessage {
+	raw, err := json.Marshal(env)
+	if err != nil {
+		panic(fmt.Sprintf("marshal error envelope: %v", err))
+	}
+	return raw
+}
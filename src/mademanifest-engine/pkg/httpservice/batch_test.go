@@ -0,0 +1,283 @@
+package httpservice
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"mademanifest-engine/pkg/trinity/output"
+)
+
+// postBatch drives handleManifestBatch and returns the recorder.
+func postBatch(t *testing.T, h Handler, contentType, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/manifest/batch", strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	rec := httptest.NewRecorder()
+	h.handleManifestBatch(rec, req)
+	return rec
+}
+
+// decodeBatch splits an NDJSON response into BatchResult lines.
+func decodeBatch(t *testing.T, raw []byte) []BatchResult {
+	t.Helper()
+	var out []BatchResult
+	sc := bufio.NewScanner(bytes.NewReader(raw))
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		var res BatchResult
+		if err := json.Unmarshal(sc.Bytes(), &res); err != nil {
+			t.Fatalf("decode line %q: %v", sc.Text(), err)
+		}
+		out = append(out, res)
+	}
+	return out
+}
+
+// envelopeErrorType extracts error.error_type from a result line
+// ("" for success envelopes).
+func envelopeErrorType(t *testing.T, res BatchResult) string {
+	t.Helper()
+	var env output.ErrorEnvelope
+	if err := json.Unmarshal(res.Envelope, &env); err != nil {
+		t.Fatalf("decode envelope %s: %v", res.Envelope, err)
+	}
+	return env.Error.Type
+}
+
+// TestManifestBatchNDJSONMixesSuccessAndErrors runs the real
+// pipeline over an NDJSON stream: every line yields one result in
+// request order, ids are echoed, a bad line does not stop the
+// batch, and each envelope is byte-identical to what /manifest
+// returns for the same payload.
+func TestManifestBatchNDJSONMixesSuccessAndErrors(t *testing.T) {
+	h := New()
+	h.Ephemeris = goldenReplay(t)
+	lines := []string{
+		`{"id":"baseline","payload":` + compactJSON(t, canonicalBaseline) + `}`,
+		`{"id":"missing-time","payload":{"birth_date":"1990-04-09"}}`,
+		``, // blank lines are skipped, not counted
+		`not json`,
+		`{"id":"dst-gap","payload":{"birth_date":"1990-03-25","birth_time":"02:30","timezone":"Europe/Amsterdam","latitude":51.9167,"longitude":4.4}}`,
+		`{"id":"extra","payload":{},"priority":1}`,
+		`{"id":"no-payload"}`,
+	}
+	rec := postBatch(t, h, NDJSONContentType, strings.Join(lines, "\n"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != NDJSONContentType {
+		t.Errorf("Content-Type = %q, want %q", ct, NDJSONContentType)
+	}
+
+	got := decodeBatch(t, rec.Body.Bytes())
+	want := []struct {
+		id      string
+		status  int
+		errType string
+	}{
+		{"baseline", http.StatusOK, ""},
+		{"missing-time", http.StatusBadRequest, output.ErrorIncompleteInput},
+		{"", http.StatusBadRequest, output.ErrorInvalidInput},
+		{"dst-gap", http.StatusBadRequest, output.ErrorInvalidInput},
+		{"extra", http.StatusBadRequest, output.ErrorInvalidInput},
+		{"no-payload", http.StatusBadRequest, output.ErrorIncompleteInput},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d result lines, want %d:\n%s", len(got), len(want), rec.Body.String())
+	}
+	for i, w := range want {
+		if got[i].Index != i || got[i].ID != w.id || got[i].HTTPStatus != w.status {
+			t.Errorf("line %d = {index %d id %q status %d}, want {index %d id %q status %d}",
+				i, got[i].Index, got[i].ID, got[i].HTTPStatus, i, w.id, w.status)
+		}
+		if et := envelopeErrorType(t, got[i]); et != w.errType {
+			t.Errorf("line %d error_type = %q, want %q", i, et, w.errType)
+		}
+	}
+
+	single, _, err := NewProcessor(h.Ephemeris)(context.Background(), strings.NewReader(canonicalBaseline))
+	if err != nil {
+		t.Fatalf("NewProcessor: %v", err)
+	}
+	if !bytes.Equal(got[0].Envelope, single) {
+		t.Errorf("batch envelope differs from /manifest envelope\nbatch:  %s\nsingle: %s",
+			got[0].Envelope, single)
+	}
+}
+
+// TestManifestBatchArrayForm accepts a JSON array body and answers a
+// non-array application/json body with a single 400 envelope before
+// any streaming starts.
+func TestManifestBatchArrayForm(t *testing.T) {
+	h := Handler{Process: func(_ context.Context, r io.Reader) ([]byte, int, error) {
+		raw, _ := io.ReadAll(r)
+		return []byte(fmt.Sprintf(`{"status":"success","echo":%s}`, raw)), http.StatusOK, nil
+	}}
+
+	rec := postBatch(t, h, "application/json",
+		`[{"id":"a","payload":{"n":1}}, {"id":"b","payload":{"n":2}}]`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d; body = %s", rec.Code, rec.Body.String())
+	}
+	got := decodeBatch(t, rec.Body.Bytes())
+	if len(got) != 2 || got[0].ID != "a" || got[1].ID != "b" {
+		t.Fatalf("results = %+v", got)
+	}
+	if !strings.Contains(string(got[1].Envelope), `"n":2`) {
+		t.Errorf("envelope %s does not carry item b's payload", got[1].Envelope)
+	}
+
+	rec = postBatch(t, h, "application/json", `{"id":"a","payload":{}}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("non-array status = %d, want 400; body = %s", rec.Code, rec.Body.String())
+	}
+	var env output.ErrorEnvelope
+	if err := json.Unmarshal(rec.Body.Bytes(), &env); err != nil || env.Error.Type != output.ErrorInvalidInput {
+		t.Errorf("non-array body = %s (err %v), want invalid_input envelope", rec.Body.String(), err)
+	}
+}
+
+// TestManifestBatchEnforcesItemCap processes exactly BatchMaxItems
+// items, then reports the cap on a final unsupported_input line.
+func TestManifestBatchEnforcesItemCap(t *testing.T) {
+	calls := 0
+	h := Handler{
+		BatchMaxItems: 2,
+		Process: func(context.Context, io.Reader) ([]byte, int, error) {
+			calls++
+			return []byte(`{"status":"success"}`), http.StatusOK, nil
+		},
+	}
+	body := strings.Repeat(`{"payload":{}}`+"\n", 5)
+	got := decodeBatch(t, postBatch(t, h, NDJSONContentType, body).Body.Bytes())
+	if calls != 2 {
+		t.Errorf("Process called %d times, want 2", calls)
+	}
+	if len(got) != 3 {
+		t.Fatalf("got %d lines, want 2 results + 1 cap line", len(got))
+	}
+	last := got[2]
+	if last.Index != 2 || last.HTTPStatus != http.StatusRequestEntityTooLarge ||
+		envelopeErrorType(t, last) != output.ErrorUnsupportedInput {
+		t.Errorf("cap line = %+v (%s)", last, last.Envelope)
+	}
+}
+
+// TestManifestBatchEnforcesByteCap ends the batch with a 413 line
+// once BatchMaxBytes is exceeded.
+func TestManifestBatchEnforcesByteCap(t *testing.T) {
+	h := Handler{
+		BatchMaxBytes: 64,
+		Process: func(context.Context, io.Reader) ([]byte, int, error) {
+			return []byte(`{"status":"success"}`), http.StatusOK, nil
+		},
+	}
+	body := strings.Repeat(`{"id":"x","payload":{}}`+"\n", 10)
+	got := decodeBatch(t, postBatch(t, h, NDJSONContentType, body).Body.Bytes())
+	if len(got) == 0 {
+		t.Fatal("no result lines")
+	}
+	last := got[len(got)-1]
+	if last.HTTPStatus != http.StatusRequestEntityTooLarge ||
+		envelopeErrorType(t, last) != output.ErrorUnsupportedInput {
+		t.Errorf("last line = %+v (%s), want 413 unsupported_input", last, last.Envelope)
+	}
+	if len(got) >= 10 {
+		t.Errorf("got %d lines; byte cap did not stop the batch", len(got))
+	}
+}
+
+// TestManifestBatchRecoversFromItemPanic proves one panicking item
+// yields an execution_failure line and the batch continues.
+func TestManifestBatchRecoversFromItemPanic(t *testing.T) {
+	h := Handler{Process: func(_ context.Context, r io.Reader) ([]byte, int, error) {
+		raw, _ := io.ReadAll(r)
+		if string(raw) == `{"boom":true}` {
+			panic("boom")
+		}
+		return []byte(`{"status":"success"}`), http.StatusOK, nil
+	}}
+	body := `{"id":"1","payload":{"boom":true}}` + "\n" + `{"id":"2","payload":{}}`
+	got := decodeBatch(t, postBatch(t, h, NDJSONContentType, body).Body.Bytes())
+	if len(got) != 2 {
+		t.Fatalf("got %d lines, want 2", len(got))
+	}
+	if got[0].HTTPStatus != http.StatusInternalServerError ||
+		envelopeErrorType(t, got[0]) != output.ErrorExecutionFailure {
+		t.Errorf("panic line = %+v (%s)", got[0], got[0].Envelope)
+	}
+	if got[1].ID != "2" || got[1].HTTPStatus != http.StatusOK {
+		t.Errorf("line after panic = %+v", got[1])
+	}
+}
+
+// TestManifestBatchAppliesPerItemDeadline gives every item its own
+// RequestTimeout: an item that overruns yields a 503 line and the
+// next item still gets a fresh deadline.
+func TestManifestBatchAppliesPerItemDeadline(t *testing.T) {
+	h := Handler{
+		RequestTimeout: 10 * time.Millisecond,
+		Process: func(ctx context.Context, r io.Reader) ([]byte, int, error) {
+			raw, _ := io.ReadAll(r)
+			if string(raw) == `{"slow":true}` {
+				<-ctx.Done()
+				return nil, 0, ctx.Err()
+			}
+			if err := ctx.Err(); err != nil {
+				return nil, 0, err
+			}
+			return []byte(`{"status":"success"}`), http.StatusOK, nil
+		},
+	}
+	body := `{"id":"slow","payload":{"slow":true}}` + "\n" + `{"id":"fast","payload":{}}`
+	got := decodeBatch(t, postBatch(t, h, NDJSONContentType, body).Body.Bytes())
+	if len(got) != 2 {
+		t.Fatalf("got %d lines, want 2", len(got))
+	}
+	if got[0].HTTPStatus != http.StatusServiceUnavailable ||
+		envelopeErrorType(t, got[0]) != output.ErrorExecutionFailure {
+		t.Errorf("slow line = %+v (%s), want 503 execution_failure", got[0], got[0].Envelope)
+	}
+	if got[1].HTTPStatus != http.StatusOK {
+		t.Errorf("fast line = %+v (%s), want 200", got[1], got[1].Envelope)
+	}
+}
+
+// TestManifestBatchRejectsRequestLevelErrors covers method and
+// Content-Type, answered like /manifest before any streaming.
+func TestManifestBatchRejectsRequestLevelErrors(t *testing.T) {
+	h := New()
+	req := httptest.NewRequest(http.MethodGet, "/manifest/batch", nil)
+	rec := httptest.NewRecorder()
+	h.handleManifestBatch(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET status = %d, want 405", rec.Code)
+	}
+
+	for _, ct := range []string{"", "text/plain"} {
+		rec := postBatch(t, h, ct, `[]`)
+		if rec.Code != http.StatusUnsupportedMediaType {
+			t.Errorf("Content-Type %q: status = %d, want 415", ct, rec.Code)
+		}
+	}
+}
+
+func compactJSON(t *testing.T, s string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, []byte(s)); err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+	return buf.String()
+}
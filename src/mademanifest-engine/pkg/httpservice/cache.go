@@ -0,0 +1,163 @@
+package httpservice
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	"mademanifest-engine/pkg/trinity/input"
+	"mademanifest-engine/pkg/trinity/output"
+)
+
+// DefaultCacheEntries is the capacity of the response cache New
+// wires.  A full success envelope is about 10 KiB, so the default
+// holds roughly 10 MiB of bodies.
+const DefaultCacheEntries = 1024
+
+// ResponseCache is an in-process LRU of success-envelope bodies.
+// The engine is deterministic – the metadata version pins and the
+// canonical payload fully determine the envelope – so a body
+// computed once can be replayed for every later request with the
+// same validated payload.
+//
+// Entries are keyed by cacheKey: a SHA-256 over
+// output.CurrentMetadata(), the validated input.Payload and the
+// section selection.  Keying on the validated payload rather than
+// the raw body means whitespace, key order and number spelling do
+// not defeat the cache, while any field that changes the envelope
+// (utc_offset included) does.  Rejections are never cached; they
+// cost no ephemeris call.
+//
+// A nil *ResponseCache is a valid, disabled cache.
+type ResponseCache struct {
+	mu      sync.Mutex
+	max     int
+	order   *list.List // front = most recently used
+	entries map[cacheKey]*list.Element
+}
+
+type cacheKey [sha256.Size]byte
+
+type cacheEntry struct {
+	key  cacheKey
+	body []byte
+}
+
+// NewResponseCache returns a cache holding at most maxEntries
+// bodies, or nil (caching disabled) when maxEntries <= 0.
+func NewResponseCache(maxEntries int) *ResponseCache {
+	if maxEntries <= 0 {
+		return nil
+	}
+	return &ResponseCache{
+		max:     maxEntries,
+		order:   list.New(),
+		entries: make(map[cacheKey]*list.Element),
+	}
+}
+
+// newCacheKey hashes everything that determines a success
+// envelope.  The three JSON documents are separated by a NUL byte,
+// which cannot occur in encoding/json output.
+func newCacheKey(payload input.Payload, sections []output.Section) cacheKey {
+	h := sha256.New()
+	enc := json.NewEncoder(h)
+	for _, v := range []any{output.CurrentMetadata(), payload, sections} {
+		// Marshalling plain strings and float64s cannot fail.
+		_ = enc.Encode(v)
+		h.Write([]byte{0})
+	}
+	var k cacheKey
+	h.Sum(k[:0])
+	return k
+}
+
+// get returns the cached body for k and marks it most recently
+// used.  The body is shared: callers must not modify it.
+func (c *ResponseCache) get(k cacheKey) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[k]
+	if !ok {
+		defaultMetrics.cacheLookups.Inc("miss")
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	defaultMetrics.cacheLookups.Inc("hit")
+	return el.Value.(*cacheEntry).body, true
+}
+
+// put stores body under k, evicting the least recently used entry
+// once the cache is full.
+func (c *ResponseCache) put(k cacheKey, body []byte) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[k]; ok {
+		// Two concurrent misses computed the same envelope.
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[k] = c.order.PushFront(&cacheEntry{key: k, body: body})
+	defaultMetrics.cacheEntries.Add(1)
+	if c.order.Len() > c.max {
+		oldest := c.order.Remove(c.order.Back()).(*cacheEntry)
+		delete(c.entries, oldest.key)
+		defaultMetrics.cacheEntries.Add(-1)
+		defaultMetrics.cacheEvictions.Inc()
+	}
+}
+
+// cacheCtxKey carries the Handler's cache to trinityProcess, like
+// sectionsKey.  Only /manifest and /manifest/batch attach it: the
+// /readyz canary must always recompute, or a cached verdict would
+// hide an ephemeris bundle that broke after the first probe.
+type cacheCtxKey struct{}
+
+func withCache(ctx context.Context, c *ResponseCache) context.Context {
+	if c == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, cacheCtxKey{}, c)
+}
+
+func cacheFrom(ctx context.Context) *ResponseCache {
+	c, _ := ctx.Value(cacheCtxKey{}).(*ResponseCache)
+	return c
+}
+
+// etagFor returns the strong entity tag of a success body: the
+// quoted hex SHA-256 of its bytes.  Identical bodies – cached or
+// recomputed, on any replica running the same version pins – carry
+// the same tag.
+func etagFor(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+// notModified reports whether the request's If-None-Match header
+// matches etag.  RFC 9110 §13.1.2 prescribes the weak comparison
+// for If-None-Match, so a W/ prefix on the client's tag is ignored;
+// "*" matches any current representation.
+func notModified(r *http.Request, etag string) bool {
+	header := r.Header.Values("If-None-Match")
+	for _, line := range header {
+		for _, tag := range strings.Split(line, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+				return true
+			}
+		}
+	}
+	return false
+}
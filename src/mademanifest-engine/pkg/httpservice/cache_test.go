@@ -0,0 +1,156 @@
+package httpservice
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mademanifest-engine/pkg/trinity/input"
+	"mademanifest-engine/pkg/trinity/output"
+)
+
+// TestResponseCacheEvictsLeastRecentlyUsed fills a two-entry cache,
+// touches the older entry, and checks the untouched one is evicted.
+func TestResponseCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewResponseCache(2)
+	key := func(date string) cacheKey {
+		return newCacheKey(input.Payload{BirthDate: date}, nil)
+	}
+	c.put(key("a"), []byte("A"))
+	c.put(key("b"), []byte("B"))
+	if _, ok := c.get(key("a")); !ok {
+		t.Fatal("a missing before eviction")
+	}
+	c.put(key("c"), []byte("C"))
+	if _, ok := c.get(key("b")); ok {
+		t.Error("b survived; want it evicted as least recently used")
+	}
+	for _, k := range []string{"a", "c"} {
+		if _, ok := c.get(key(k)); !ok {
+			t.Errorf("%s evicted; want it kept", k)
+		}
+	}
+
+	disabled := NewResponseCache(0)
+	disabled.put(key("a"), []byte("A"))
+	if _, ok := disabled.get(key("a")); ok {
+		t.Error("disabled cache returned a hit")
+	}
+}
+
+// TestCacheKeyCoversEnvelopeInputs proves every input that changes
+// the envelope changes the key.
+func TestCacheKeyCoversEnvelopeInputs(t *testing.T) {
+	base := input.Payload{BirthDate: "1990-04-09", BirthTime: "18:04",
+		Timezone: "Europe/Amsterdam", Latitude: 51.9167, Longitude: 4.4}
+	withOffset := base
+	withOffset.UTCOffset = "+02:00"
+	keys := map[cacheKey]string{}
+	for name, k := range map[string]cacheKey{
+		"base":       newCacheKey(base, nil),
+		"utc_offset": newCacheKey(withOffset, nil),
+		"sections":   newCacheKey(base, []output.Section{output.SectionGeneKeys}),
+	} {
+		if other, dup := keys[k]; dup {
+			t.Errorf("%s and %s share a cache key", name, other)
+		}
+		keys[k] = name
+	}
+	if newCacheKey(base, nil) != newCacheKey(base, nil) {
+		t.Error("cache key is not deterministic")
+	}
+}
+
+// TestManifestCachedBodyIsByteIdentical posts the same payload
+// twice – the second time spelled differently – and checks the
+// replayed body equals both the first answer and an uncached
+// computation, and that the second request hit the cache without
+// running a pipeline stage.
+func TestManifestCachedBodyIsByteIdentical(t *testing.T) {
+	mux := http.NewServeMux()
+	h := New()
+	h.Ephemeris = goldenReplay(t)
+	h.Register(mux)
+	post := func(body string) []byte {
+		req := httptest.NewRequest(http.MethodPost, "/manifest", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+		}
+		return rec.Body.Bytes()
+	}
+
+	first := post(canonicalBaseline)
+	before := scrape(t, mux)
+	second := post(compactJSON(t, canonicalBaseline))
+	after := scrape(t, mux)
+
+	if !bytes.Equal(first, second) {
+		t.Errorf("cached body differs from the computed one:\n%s\nvs\n%s", second, first)
+	}
+	uncached := postManifestQuery(t, Handler{Ephemeris: h.Ephemeris}, "", canonicalBaseline)
+	if !bytes.Equal(uncached.Body.Bytes(), second) {
+		t.Errorf("cached body differs from an uncached computation")
+	}
+	for series, want := range map[string]float64{
+		`mademanifest_response_cache_lookups_total{result="hit"}`:               1,
+		`mademanifest_response_cache_lookups_total{result="miss"}`:              0,
+		`mademanifest_pipeline_stage_duration_seconds_count{stage="astrology"}`: 0,
+	} {
+		if got := after[series] - before[series]; got != want {
+			t.Errorf("%s moved by %v, want %v", series, got, want)
+		}
+	}
+}
+
+// TestManifestETagAndNotModified checks the strong ETag on success
+// envelopes and the If-None-Match revalidation forms.
+func TestManifestETagAndNotModified(t *testing.T) {
+	h := New()
+	h.Ephemeris = goldenReplay(t)
+	rec := postManifestQuery(t, h, "", canonicalBaseline)
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || etag != etagFor(rec.Body.Bytes()) ||
+		strings.HasPrefix(etag, "W/") {
+		t.Fatalf("status %d ETag %q; want 200 and the strong body tag", rec.Code, etag)
+	}
+
+	for _, c := range []struct {
+		ifNoneMatch string
+		want        int
+	}{
+		{etag, http.StatusNotModified},
+		{"W/" + etag, http.StatusNotModified},
+		{`"stale", ` + etag, http.StatusNotModified},
+		{"*", http.StatusNotModified},
+		{`"stale"`, http.StatusOK},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/manifest", strings.NewReader(canonicalBaseline))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-None-Match", c.ifNoneMatch)
+		rec := httptest.NewRecorder()
+		h.handleManifest(rec, req)
+		if rec.Code != c.want {
+			t.Errorf("If-None-Match %s: status = %d, want %d", c.ifNoneMatch, rec.Code, c.want)
+		}
+		if rec.Header().Get("ETag") != etag {
+			t.Errorf("If-None-Match %s: ETag = %q, want %q", c.ifNoneMatch, rec.Header().Get("ETag"), etag)
+		}
+		if c.want == http.StatusNotModified && rec.Body.Len() != 0 {
+			t.Errorf("If-None-Match %s: 304 carried a body", c.ifNoneMatch)
+		}
+	}
+
+	partial := postManifestQuery(t, h, "sections=astrology", canonicalBaseline)
+	if partial.Header().Get("ETag") == etag {
+		t.Error("partial envelope shares the full envelope's ETag")
+	}
+	rejected := postManifestQuery(t, h, "", `{"birth_date":"1990-04-09"}`)
+	if rejected.Header().Get("ETag") != "" {
+		t.Error("rejection envelope carries an ETag")
+	}
+}
@@ -0,0 +1,74 @@
+//go:build cgo
+
+package httpservice
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// concurrencyPayloads are distinct valid inputs so that concurrent
+// requests interleave different Julian Days through the shared
+// Swiss Ephemeris state (a single repeated payload would hide
+// cache cross-talk inside libswe).
+var concurrencyPayloads = []string{
+	canonicalBaseline,
+	`{"birth_date":"1985-11-23","birth_time":"06:30","timezone":"America/New_York","latitude":40.7128,"longitude":-74.006}`,
+	`{"birth_date":"2001-02-14","birth_time":"23:59","timezone":"Asia/Tokyo","latitude":35.6762,"longitude":139.6503}`,
+	`{"birth_date":"1972-07-01","birth_time":"12:00","timezone":"Australia/Sydney","latitude":-33.8688,"longitude":151.2093}`,
+}
+
+// TestTrinityProcessConcurrentDeterminism hammers trinityProcess
+// from many goroutines at once and asserts every envelope is
+// byte-identical to the one produced serially for the same input.
+// It is a test of libswe itself, so it runs only where cgo is.
+// Run with -race to also prove the ephemeris access layer has no
+// data race on the Go side; the byte comparison catches races that
+// would occur inside the C library, where the race detector is
+// blind.
+func TestTrinityProcessConcurrentDeterminism(t *testing.T) {
+	want := make([][]byte, len(concurrencyPayloads))
+	for i, p := range concurrencyPayloads {
+		body, status, err := trinityProcess(context.Background(), strings.NewReader(p))
+		if err != nil || status != 200 {
+			t.Fatalf("serial reference %d: status=%d err=%v body=%s", i, status, err, body)
+		}
+		want[i] = body
+	}
+
+	const workers = 16
+	iterations := 8
+	if testing.Short() {
+		iterations = 2
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*iterations)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for it := 0; it < iterations; it++ {
+				i := (w + it) % len(concurrencyPayloads)
+				body, status, err := trinityProcess(context.Background(), strings.NewReader(concurrencyPayloads[i]))
+				if err != nil || status != 200 {
+					errs <- fmt.Errorf("worker %d iter %d payload %d: status=%d err=%v", w, it, i, status, err)
+					continue
+				}
+				if !bytes.Equal(body, want[i]) {
+					errs <- fmt.Errorf("worker %d iter %d payload %d: envelope drifted from serial reference\ngot:  %s\nwant: %s",
+						w, it, i, body, want[i])
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
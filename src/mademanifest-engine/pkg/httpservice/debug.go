@@ -0,0 +1,215 @@
+package httpservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+
+	"mademanifest-engine/pkg/ephemeris"
+	"mademanifest-engine/pkg/hd/calc"
+	"mademanifest-engine/pkg/hd/structure"
+	"mademanifest-engine/pkg/trinity/astro"
+	"mademanifest-engine/pkg/trinity/hd"
+	"mademanifest-engine/pkg/trinity/input"
+	"mademanifest-engine/pkg/trinity/output"
+	"mademanifest-engine/pkg/trinity/sensitivity"
+)
+
+// debugPrefix is the path prefix of the diagnostic routes.  They
+// answer derivation traces, never the canonical envelope, are
+// mounted only on request (Handler.Debug) and are deliberately left
+// out of the OpenAPI contract: their shape may change with any
+// engine version.
+const debugPrefix = "/debug/"
+
+// explainer computes the diagnostic body of one debug route for a
+// payload that passed validation and the gates.  It reads its
+// options from the request query (queryFrom); a bad option is
+// returned as a bare *input.Rejection, which answers with its error
+// envelope like a rejected payload.
+type explainer func(ctx context.Context, eph ephemeris.Provider, payload input.Payload) (any, error)
+
+// explainers are the debug routes by name: POST /debug/<name> on
+// the service, `manifest --explain <name>` offline.
+var explainers = map[string]explainer{
+	"structure":   explainStructure,
+	"design-time": explainDesignTime,
+	"boundaries":  explainBoundaries,
+}
+
+// DebugRoutes returns the names of the debug routes, sorted.
+func DebugRoutes() []string {
+	names := make([]string, 0, len(explainers))
+	for name := range explainers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewDebugProcessor returns the processor answering the debug route
+// name against eph, or false when there is no such route.  A payload
+// the validator or a gate rejects gets the error envelope /manifest
+// would answer with, so a trace only ever explains a chart the
+// engine would compute.
+func NewDebugProcessor(name string, eph ephemeris.Provider) (Processor, bool) {
+	explain, ok := explainers[name]
+	if !ok {
+		return nil, false
+	}
+	return func(ctx context.Context, bodyReader io.Reader) ([]byte, int, error) {
+		raw, err := io.ReadAll(bodyReader)
+		if err != nil {
+			return nil, 0, fmt.Errorf("read request body: %w", err)
+		}
+		payload, rej := input.Validate(raw)
+		if rej != nil {
+			return rejectionEnvelope(rej)
+		}
+		release, err := admit(ctx)
+		if err != nil {
+			return nil, 0, err
+		}
+		defer release()
+		rej, err = gatePayload(eph, payload)
+		if err != nil {
+			return nil, 0, err
+		}
+		if rej != nil {
+			return rejectionEnvelope(rej)
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, 0, err
+		}
+		trace, err := explain(ctx, eph, payload)
+		if rej, ok := err.(*input.Rejection); ok {
+			return rejectionEnvelope(rej)
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		body, err := json.Marshal(trace)
+		if err != nil {
+			return nil, 0, fmt.Errorf("marshal %s trace: %w", name, err)
+		}
+		return body, http.StatusOK, nil
+	}, true
+}
+
+// StructureExplanation is the answer of POST /debug/structure: how
+// the Human Design definition, type and authority of the chart
+// follow from its activations (structure.Trace), and the same trace
+// as a few lines of text.  The type and authority are always those
+// of the chart's /manifest envelope.
+type StructureExplanation struct {
+	Status    string           `json:"status"`
+	Metadata  output.Metadata  `json:"metadata"`
+	InputEcho output.InputEcho `json:"input_echo"`
+	Structure structure.Trace  `json:"structure"`
+	Summary   string           `json:"summary"`
+}
+
+func explainStructure(ctx context.Context, eph ephemeris.Provider, payload input.Payload) (any, error) {
+	snap, err := computeSnapshots(ctx, eph, payload)
+	if err != nil {
+		return nil, err
+	}
+	done := defaultMetrics.stage(stageStructure)
+	trace := structure.Explain(snap.personality, snap.design)
+	done()
+	return StructureExplanation{
+		Status:    output.StatusSuccess,
+		Metadata:  output.CurrentMetadata(),
+		InputEcho: output.EchoInput(payload),
+		Structure: trace,
+		Summary:   trace.Summary(),
+	}, nil
+}
+
+// DesignTimeExplanation is the answer of POST /debug/design-time:
+// the design-time solve of the chart with the solver's
+// calc.Diagnostics, for auditing the A3 / D22 lower-bound rule on a
+// given birth.  DesignTimeUTC is the value of the envelope's
+// human_design.system.design_time_utc; DesignJD is the Julian Day
+// (UT) the design activations are computed at.  On a
+// bracket_width_threshold exit DesignJD is Solver.FinalLowerJD, on
+// an abs_diff_threshold exit the midpoint of the final bracket, to
+// the nanosecond resolution of the design time.
+type DesignTimeExplanation struct {
+	Status        string            `json:"status"`
+	Metadata      output.Metadata   `json:"metadata"`
+	InputEcho     output.InputEcho  `json:"input_echo"`
+	BirthJD       float64           `json:"birth_jd"`
+	DesignJD      float64           `json:"design_jd"`
+	DesignTimeUTC output.DesignTime `json:"design_time_utc"`
+	Solver        calc.Diagnostics  `json:"solver"`
+}
+
+func explainDesignTime(ctx context.Context, eph ephemeris.Provider, payload input.Payload) (any, error) {
+	birthJD, err := hd.BirthJDFromPayload(payload)
+	if err != nil {
+		return nil, fmt.Errorf("compute birth JD: %w", err)
+	}
+	designTime, diag, err := solveDesignTime(ctx, eph, payload)
+	if err != nil {
+		return nil, err
+	}
+	return DesignTimeExplanation{
+		Status:        output.StatusSuccess,
+		Metadata:      output.CurrentMetadata(),
+		InputEcho:     output.EchoInput(payload),
+		BirthJD:       birthJD,
+		DesignJD:      hd.DesignJDFromTime(designTime),
+		DesignTimeUTC: output.DesignTime(designTime),
+		Solver:        diag,
+	}, nil
+}
+
+// BoundaryExplanation is the answer of POST /debug/boundaries: the
+// margin of every position of the chart to its nearest sign, house,
+// gate and line boundary (sensitivity.Report), and the same report
+// as a text table.  The ?thresholds= option overrides
+// sensitivity.DefaultThresholds, e.g. thresholds=gate:0.2,line:0.05.
+type BoundaryExplanation struct {
+	Status     string             `json:"status"`
+	Metadata   output.Metadata    `json:"metadata"`
+	InputEcho  output.InputEcho   `json:"input_echo"`
+	Boundaries sensitivity.Report `json:"boundaries"`
+	Summary    string             `json:"summary"`
+}
+
+func explainBoundaries(ctx context.Context, eph ephemeris.Provider, payload input.Payload) (any, error) {
+	thresholds, err := sensitivity.ParseThresholds(queryFrom(ctx).Get("thresholds"))
+	if err != nil {
+		return nil, &input.Rejection{Type: input.RejectInvalid, Field: "thresholds", Message: err.Error()}
+	}
+
+	done := defaultMetrics.stage(stageAstrology)
+	astroSection, err := astro.ComputeAstrology(eph, payload)
+	done()
+	if err != nil {
+		return nil, fmt.Errorf("compute astrology: %w", err)
+	}
+	designTime, _, err := solveDesignTime(ctx, eph, payload)
+	if err != nil {
+		return nil, err
+	}
+	done = defaultMetrics.stage(stageActivations)
+	personality, design, err := hd.ActivationLongitudes(ctx, eph, payload, hd.DesignJDFromTime(designTime))
+	done()
+	if err != nil {
+		return nil, fmt.Errorf("compute activations: %w", err)
+	}
+
+	report := sensitivity.Compute(astroSection, personality, design, thresholds)
+	return BoundaryExplanation{
+		Status:     output.StatusSuccess,
+		Metadata:   output.CurrentMetadata(),
+		InputEcho:  output.EchoInput(payload),
+		Boundaries: report,
+		Summary:    report.Text(),
+	}, nil
+}
@@ -0,0 +1,274 @@
+package httpservice
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"mademanifest-engine/pkg/golden"
+	"mademanifest-engine/pkg/trinity/output"
+	"mademanifest-engine/pkg/trinity/sensitivity"
+)
+
+func postJSON(t *testing.T, mux *http.ServeMux, route, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, route, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+// TestDebugRoutesOffByDefault: New mounts no /debug/ route, so the
+// traces are unreachable unless an operator asks for them.
+func TestDebugRoutesOffByDefault(t *testing.T) {
+	mux := http.NewServeMux()
+	New().Register(mux)
+	if rec := postJSON(t, mux, "/debug/structure", canonicalBaseline); rec.Code != http.StatusNotFound {
+		t.Errorf("POST /debug/structure without Debug: status %d, want 404", rec.Code)
+	}
+}
+
+// TestDebugStructureAgreesWithManifest explains the canonical
+// baseline: the trace must reach the type and authority, and list
+// the channels, of the /manifest envelope for the same payload.
+func TestDebugStructureAgreesWithManifest(t *testing.T) {
+	replay := goldenReplay(t)
+	h := New()
+	h.Process = NewProcessor(replay)
+	h.Debug = replay
+	mux := http.NewServeMux()
+	h.Register(mux)
+
+	rec := postJSON(t, mux, "/debug/structure", canonicalBaseline)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	var got StructureExplanation
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode trace: %v", err)
+	}
+
+	body, _, err := NewProcessor(replay)(context.Background(), strings.NewReader(canonicalBaseline))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var env output.SuccessEnvelope
+	if err := json.Unmarshal(body, &env); err != nil {
+		t.Fatal(err)
+	}
+	hd := env.HumanDesign
+	if got.Structure.Type != hd.Type || got.Structure.Authority != hd.Authority ||
+		got.Structure.Definition != hd.Definition || len(got.Structure.Channels) != len(hd.Channels) {
+		t.Errorf("trace %s/%s/%s with %d channels, envelope %s/%s/%s with %d", got.Structure.Type,
+			got.Structure.Authority, got.Structure.Definition, len(got.Structure.Channels),
+			hd.Type, hd.Authority, hd.Definition, len(hd.Channels))
+	}
+	if got.Status != "success" || got.Metadata != env.Metadata || got.InputEcho != env.InputEcho {
+		t.Errorf("header = %q %+v %+v", got.Status, got.Metadata, got.InputEcho)
+	}
+	if !strings.Contains(got.Summary, "Type: "+hd.Type+" (step ") {
+		t.Errorf("Summary does not state the type:\n%s", got.Summary)
+	}
+}
+
+// TestDebugStructureRejectsLikeManifest: a payload the validator or
+// a gate rejects gets the envelope and status /manifest answers
+// with, not a trace.
+func TestDebugStructureRejectsLikeManifest(t *testing.T) {
+	replay := goldenReplay(t)
+	h := New()
+	h.Debug = replay
+	mux := http.NewServeMux()
+	h.Register(mux)
+
+	polar, err := os.ReadFile(filepath.Join("..", "..", "..", "golden", "trinity",
+		"unsupported_input", "polar_latitude_norway_tromso", "input.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, payload := range []string{`{"birth_date": "1990-04-09"}`, string(polar)} {
+		want, wantStatus, err := NewProcessor(replay)(context.Background(), strings.NewReader(payload))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rec := postJSON(t, mux, "/debug/structure", payload)
+		if rec.Code != wantStatus || rec.Body.String() != string(want) {
+			t.Errorf("status %d %s\nwant %d %s", rec.Code, rec.Body, wantStatus, want)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/structure", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET /debug/structure: status %d, want 405", rec.Code)
+	}
+}
+
+// TestOfflineExplain: Offline.Explain answers with the debug route's
+// body, and refuses an unknown route, a section selector or a bad
+// route option.
+func TestOfflineExplain(t *testing.T) {
+	replay := goldenReplay(t)
+	process, ok := NewDebugProcessor("structure", replay)
+	if !ok {
+		t.Fatal("no structure debug route")
+	}
+	want, _, err := process(context.Background(), strings.NewReader(canonicalBaseline))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, status := Offline{Provider: replay, Explain: "structure"}.Envelope(context.Background(),
+		strings.NewReader(canonicalBaseline))
+	if status != http.StatusOK || string(body) != string(want) {
+		t.Errorf("explain=structure: status %d\n%s\nwant\n%s", status, body, want)
+	}
+
+	for _, o := range []Offline{
+		{Provider: replay, Explain: "tarot"},
+		{Provider: replay, Explain: "structure", Sections: "astrology"},
+		{Provider: replay, Explain: "boundaries", Query: url.Values{"thresholds": {"tarot:1"}}},
+	} {
+		body, status := o.Envelope(context.Background(), strings.NewReader(canonicalBaseline))
+		if status != http.StatusBadRequest || envelopeType(t, string(body)) != output.ErrorInvalidInput {
+			t.Errorf("%+v: status %d %s", o, status, body)
+		}
+	}
+}
+
+// TestDebugDesignTimeReportsSolve replays every golden success
+// payload through /debug/design-time: the design time must be the
+// envelope's, the reported JD must sit where the exit reason puts it
+// (A3 / D22: the lower bound on a width exit), and the call count
+// must add up to the solve the diagnostics describe.
+func TestDebugDesignTimeReportsSolve(t *testing.T) {
+	replay := goldenReplay(t)
+	h := New()
+	h.Debug = replay
+	mux := http.NewServeMux()
+	h.Register(mux)
+
+	fixtures, err := golden.LoadFixtures(filepath.Join("..", "..", "..", "golden", "trinity"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range fixtures {
+		if golden.IsErrorCategory(f.Category) {
+			continue
+		}
+		raw, err := f.LoadInput()
+		if err != nil {
+			t.Fatal(err)
+		}
+		rec := postJSON(t, mux, "/debug/design-time", string(raw))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status %d: %s", f.RelativePath, rec.Code, rec.Body)
+		}
+		var got DesignTimeExplanation
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Fatalf("%s: decode: %v", f.RelativePath, err)
+		}
+		body, _, err := NewProcessor(replay)(context.Background(), bytes.NewReader(raw))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var env output.SuccessEnvelope
+		if err := json.Unmarshal(body, &env); err != nil {
+			t.Fatal(err)
+		}
+		if !time.Time(got.DesignTimeUTC).Equal(time.Time(env.HumanDesign.System.DesignTimeUTC)) {
+			t.Errorf("%s: design_time_utc %v, envelope %v", f.RelativePath,
+				time.Time(got.DesignTimeUTC), time.Time(env.HumanDesign.System.DesignTimeUTC))
+		}
+
+		d := got.Solver
+		want, calls := 0.0, 3+d.BracketExpansions+d.BracketIterations
+		switch d.ExitReason {
+		case "abs_diff_threshold":
+			want = (d.FinalLowerJD + d.FinalUpperJD) / 2
+		case "bracket_width_threshold":
+			want = d.FinalLowerJD
+			calls++
+		default:
+			t.Errorf("%s: exit_reason %q", f.RelativePath, d.ExitReason)
+			continue
+		}
+		if math.Abs(got.DesignJD-want) > 1e-8 { // about a millisecond
+			t.Errorf("%s: design_jd %.10f, want %.10f on %s", f.RelativePath, got.DesignJD, want, d.ExitReason)
+		}
+		if d.SunFuncCalls != calls {
+			t.Errorf("%s: %d Sun evaluations, want %d from %+v", f.RelativePath, d.SunFuncCalls, calls, d)
+		}
+		if got.BirthJD-got.DesignJD < 80 || got.BirthJD-got.DesignJD > 100 {
+			t.Errorf("%s: design %.4f days before birth", f.RelativePath, got.BirthJD-got.DesignJD)
+		}
+	}
+}
+
+// TestDebugBoundariesFlagsSchiedam reproduces the Schiedam review's
+// boundary-distances.txt: personality jupiter is the one position
+// within the default 0.02° of a line cusp.  A tighter ?thresholds=
+// clears the flag; a malformed one is an invalid_input.
+func TestDebugBoundariesFlagsSchiedam(t *testing.T) {
+	replay := goldenReplay(t)
+	h := New()
+	h.Debug = replay
+	mux := http.NewServeMux()
+	h.Register(mux)
+
+	report := func(query string) BoundaryExplanation {
+		t.Helper()
+		rec := postJSON(t, mux, "/debug/boundaries"+query, canonicalBaseline)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status %d: %s", query, rec.Code, rec.Body)
+		}
+		var got BoundaryExplanation
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	got := report("")
+	var flagged []string
+	for _, m := range got.Boundaries.Astrology {
+		if len(m.Near) > 0 {
+			flagged = append(flagged, "astrology."+m.ObjectID)
+		}
+	}
+	for _, snap := range []struct {
+		name    string
+		margins []sensitivity.ActivationMargin
+	}{{"personality", got.Boundaries.Personality}, {"design", got.Boundaries.Design}} {
+		for _, m := range snap.margins {
+			if len(m.Near) > 0 {
+				flagged = append(flagged, snap.name+"."+m.ObjectID+" "+strings.Join(m.Near, ","))
+			}
+		}
+	}
+	if want := []string{"personality.jupiter line"}; !reflect.DeepEqual(flagged, want) || !got.Boundaries.Sensitive {
+		t.Errorf("flagged %v (sensitive %v), want %v", flagged, got.Boundaries.Sensitive, want)
+	}
+	if !strings.Contains(got.Summary, "near line cusp") {
+		t.Errorf("Summary:\n%s", got.Summary)
+	}
+
+	if got := report("?thresholds=line:0.01"); got.Boundaries.Sensitive || got.Boundaries.Thresholds.Line != 0.01 {
+		t.Errorf("thresholds=line:0.01: sensitive %v with %+v", got.Boundaries.Sensitive, got.Boundaries.Thresholds)
+	}
+	rec := postJSON(t, mux, "/debug/boundaries?thresholds=line", canonicalBaseline)
+	if rec.Code != http.StatusBadRequest || envelopeType(t, rec.Body.String()) != output.ErrorInvalidInput {
+		t.Errorf("thresholds=line: status %d %s", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,195 @@
+package httpservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"mademanifest-engine/pkg/astronomy"
+	"mademanifest-engine/pkg/ephemeris"
+	"mademanifest-engine/pkg/trinity/astro"
+	"mademanifest-engine/pkg/trinity/extended"
+	"mademanifest-engine/pkg/trinity/input"
+	"mademanifest-engine/pkg/trinity/output"
+)
+
+// ExtendedAstrologyRoute serves the non-canonical extended astrology
+// (pkg/trinity/extended).  The extension is versioned apart from the
+// canon: the major revision is in the path, the full one in the
+// envelope's extension_version, and /manifest never carries it.
+const ExtendedAstrologyRoute = "/extensions/v1/astrology"
+
+// ObjectsParam is the optional query parameter of POST
+// /extensions/v1/astrology opting in to the extended object set
+// (extended.ExtendedObjects).  Its one value is ObjectsExtended;
+// without it the response carries no extended_objects block.
+const (
+	ObjectsParam    = "objects"
+	ObjectsExtended = "extended"
+)
+
+// HouseSystemParam is the optional query parameter of POST
+// /extensions/v1/astrology selecting a system of the house-system
+// registry (astro.HouseSystems) by name; the response then carries
+// its house_table.  The astrology section stays Placidus.
+const HouseSystemParam = "house_system"
+
+// extensionOptions are the query options of an extension request.
+type extensionOptions struct {
+	objects     bool
+	houseSystem *astro.HouseSystem
+}
+
+// ExtendedAstrologyEnvelope is the success answer of POST
+// /extensions/v1/astrology: the astrology section of the /manifest
+// envelope for the same payload, the speed and direction of each of
+// its objects, and the aspects between its objects and angles found
+// with the orb table of OrbTableVersion.  ExtendedObjects is set
+// only on the ?objects=extended opt-in: the extended objects never
+// join the canonical thirteen of the astrology section, nor their
+// aspects.  HouseTable is set only on ?house_system=.
+// Rejections use the Trinity error envelope, exactly as /manifest
+// does for the same payload.
+type ExtendedAstrologyEnvelope struct {
+	Status           string                 `json:"status"` // always "success"
+	Metadata         output.Metadata        `json:"metadata"`
+	ExtensionVersion string                 `json:"extension_version"`
+	InputEcho        output.InputEcho       `json:"input_echo"`
+	Astrology        output.Astrology       `json:"astrology"`
+	Speeds           []extended.ObjectSpeed `json:"speeds"`
+	OrbTableVersion  string                 `json:"orb_table_version"`
+	Aspects          []extended.Aspect      `json:"aspects"`
+	ExtendedObjects  *extended.ObjectSet    `json:"extended_objects,omitempty"`
+	HouseTable       *extended.HouseTable   `json:"house_table,omitempty"`
+}
+
+// parseExtensionOptions reads the ?objects= and ?house_system=
+// options of an extension request.
+func parseExtensionOptions(query url.Values) (extensionOptions, error) {
+	var opts extensionOptions
+	if query.Has(ObjectsParam) {
+		if raw := query.Get(ObjectsParam); raw != ObjectsExtended {
+			return opts, fmt.Errorf("%s %q is not %q", ObjectsParam, raw, ObjectsExtended)
+		}
+		opts.objects = true
+	}
+	if query.Has(HouseSystemParam) {
+		raw := query.Get(HouseSystemParam)
+		sys, ok := astro.HouseSystemByName(raw)
+		if !ok {
+			return opts, fmt.Errorf("%s %q is not a registered house system", HouseSystemParam, raw)
+		}
+		opts.houseSystem = &sys
+	}
+	return opts, nil
+}
+
+// extendedProcess is the Processor of POST /extensions/v1/astrology:
+// like windowProcess it reads the query before the body, so a bad
+// option is the caller's error whatever the body says.
+func (h Handler) extendedProcess(ctx context.Context, bodyReader io.Reader) ([]byte, int, error) {
+	opts, err := parseExtensionOptions(queryFrom(ctx))
+	if err != nil {
+		return mustMarshal(output.NewError(output.ErrorInvalidInput, err.Error())), http.StatusBadRequest, nil
+	}
+	eph := h.Ephemeris
+	if eph == nil {
+		eph = ephemeris.Default()
+	}
+	return NewExtendedAstrologyProcessor(eph, opts.objects, opts.houseSystem)(ctx, bodyReader)
+}
+
+// NewExtendedAstrologyProcessor returns the processor answering a
+// payload with its extended astrology against eph.  The payload goes
+// through the validator and the gates of /manifest first.  The
+// speeds need an ephemeris.VectorProvider; any other eph fails the
+// accepted payloads with an execution failure.  withObjects adds the
+// extended object set to the answer, a non-nil houseSystem its house
+// table; a birth whose houses are undefined in houseSystem is
+// rejected like a polar birth.
+func NewExtendedAstrologyProcessor(eph ephemeris.Provider, withObjects bool, houseSystem *astro.HouseSystem) Processor {
+	return func(ctx context.Context, bodyReader io.Reader) ([]byte, int, error) {
+		raw, err := io.ReadAll(bodyReader)
+		if err != nil {
+			return nil, 0, fmt.Errorf("read request body: %w", err)
+		}
+		payload, rej := input.Validate(raw)
+		if rej != nil {
+			return rejectionEnvelope(rej)
+		}
+		release, err := admit(ctx)
+		if err != nil {
+			return nil, 0, err
+		}
+		defer release()
+		rej, err = gatePayload(eph, payload)
+		if err != nil {
+			return nil, 0, err
+		}
+		if rej != nil {
+			return rejectionEnvelope(rej)
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, 0, err
+		}
+
+		vectors, ok := eph.(ephemeris.VectorProvider)
+		if !ok {
+			return nil, 0, fmt.Errorf("ephemeris %T provides no body speeds", eph)
+		}
+		birth, rej := input.LocalToUTC(payload)
+		if rej != nil {
+			return nil, 0, fmt.Errorf("convert birth time: %w", rej)
+		}
+		jd := astronomy.ConvertUTCToJulianDay(birth)
+		if houseSystem != nil {
+			rej, err := astro.CheckHouseSystemFor(eph, jd, payload.Latitude, payload.Longitude, *houseSystem)
+			if err != nil {
+				return nil, 0, err
+			}
+			if rej != nil {
+				return rejectionEnvelope(rej)
+			}
+		}
+		done := defaultMetrics.stage(stageExtended)
+		chart, err := extended.ChartAt(vectors, jd, payload.Latitude, payload.Longitude)
+		var objects *extended.ObjectSet
+		if err == nil && withObjects {
+			var set extended.ObjectSet
+			set, err = extended.ObjectsAt(vectors, jd, chart.Astrology)
+			objects = &set
+		}
+		var houses *extended.HouseTable
+		if err == nil && houseSystem != nil {
+			var table extended.HouseTable
+			table, err = extended.HousesAt(eph, jd, payload.Latitude, payload.Longitude, *houseSystem,
+				chart.Astrology, objects)
+			houses = &table
+		}
+		aspects := extended.FindAspects(chart.Points)
+		done()
+		if err != nil {
+			return nil, 0, fmt.Errorf("compute extended astrology: %w", err)
+		}
+
+		body, err := json.Marshal(ExtendedAstrologyEnvelope{
+			Status:           output.StatusSuccess,
+			Metadata:         output.CurrentMetadata(),
+			ExtensionVersion: extended.Version,
+			InputEcho:        output.EchoInput(payload),
+			Astrology:        chart.Astrology,
+			Speeds:           chart.Speeds,
+			OrbTableVersion:  extended.OrbTableVersion,
+			Aspects:          aspects,
+			ExtendedObjects:  objects,
+			HouseTable:       houses,
+		})
+		if err != nil {
+			return nil, 0, fmt.Errorf("marshal extended astrology envelope: %w", err)
+		}
+		return body, http.StatusOK, nil
+	}
+}
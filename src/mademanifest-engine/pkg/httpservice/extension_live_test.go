@@ -0,0 +1,276 @@
+//go:build cgo
+
+package httpservice
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+
+	"mademanifest-engine/pkg/astronomy"
+	"mademanifest-engine/pkg/ephemeris"
+	"mademanifest-engine/pkg/openapi"
+	"mademanifest-engine/pkg/trinity/astro"
+	"mademanifest-engine/pkg/trinity/extended"
+	"mademanifest-engine/pkg/trinity/output"
+)
+
+// TestExtendedAstrologySchiedam computes the canonical baseline's
+// extended astrology against the live Swiss Ephemeris.  Its
+// astrology section must be byte-identical to the /manifest one, the
+// Sun and the Earth must stand in a fixed opposition, every
+// applying aspect must be tighter, every separating one wider, a
+// minute later, and Pluto and the mean node must be retrograde.
+// The response must match the published OpenAPI schema, and carry
+// the extended objects only on ?objects=extended.
+func TestExtendedAstrologySchiedam(t *testing.T) {
+	eph := ephemeris.Default()
+	if err := eph.Init(); err != nil {
+		t.Fatal(err)
+	}
+	h := New()
+	h.Ephemeris = eph
+	mux := http.NewServeMux()
+	h.Register(mux)
+
+	rec := postJSON(t, mux, ExtendedAstrologyRoute, canonicalBaseline)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	if err := openapi.Validate(readPublishedOpenAPI(t), "ExtendedAstrologyEnvelope", rec.Body.Bytes()); err != nil {
+		t.Errorf("response does not match the published schema: %v", err)
+	}
+	var got struct {
+		Astrology json.RawMessage        `json:"astrology"`
+		Speeds    []extended.ObjectSpeed `json:"speeds"`
+		Aspects   []extended.Aspect      `json:"aspects"`
+		Objects   *extended.ObjectSet    `json:"extended_objects"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	manifest := postJSON(t, mux, "/manifest", canonicalBaseline)
+	var env struct {
+		Astrology json.RawMessage `json:"astrology"`
+	}
+	if err := json.Unmarshal(manifest.Body.Bytes(), &env); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Astrology, env.Astrology) {
+		t.Errorf("astrology section differs from /manifest:\n%s\n%s", got.Astrology, env.Astrology)
+	}
+	if got.Objects != nil {
+		t.Errorf("extended objects without the opt-in: %+v", got.Objects)
+	}
+
+	later := astronomy.ConvertUTCToJulianDay(time.Date(1990, 4, 9, 16, 5, 0, 0, time.UTC))
+	chart, err := extended.ChartAt(eph, later, 51.9167, 4.4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	longitude := map[string]float64{}
+	for _, p := range chart.Points {
+		longitude[p.ID] = p.Longitude
+	}
+	fixed := false
+	for _, a := range got.Aspects {
+		if a.ObjectA == "sun" && a.ObjectB == "earth" {
+			fixed = a.Aspect == "opposition" && a.Motion == extended.MotionFixed && a.Orb < 1e-6
+			continue
+		}
+		separation := math.Abs(math.Remainder(longitude[a.ObjectB]-longitude[a.ObjectA], 360))
+		change := math.Abs(separation-a.Angle) - float64(a.Orb)
+		if math.Abs(change) < 1e-7 {
+			continue
+		}
+		if (a.Motion == extended.MotionApplying) != (change < 0) {
+			t.Errorf("%+v: orb %+.7f a minute later", a, change)
+		}
+	}
+	if !fixed {
+		t.Errorf("no fixed sun–earth opposition in %+v", got.Aspects)
+	}
+
+	for _, sp := range got.Speeds {
+		want := extended.DirectionDirect
+		if sp.ObjectID == "pluto" || sp.ObjectID == "north_node_mean" {
+			want = extended.DirectionRetrograde
+		}
+		if sp.Direction != want {
+			t.Errorf("%s at %.6f °/day is %s, want %s", sp.ObjectID, sp.Speed, sp.Direction, want)
+		}
+	}
+}
+
+// TestExtendedObjectsSchiedam opts the canonical baseline in to the
+// extended object set.  The canonical sections must not change, the
+// seven objects must come in ExtendedObjects order with the sign and
+// house of their longitude against the chart's cusps, and the mean
+// south node must stand opposite the canonical mean north node.
+func TestExtendedObjectsSchiedam(t *testing.T) {
+	eph := ephemeris.Default()
+	if err := eph.Init(); err != nil {
+		t.Fatal(err)
+	}
+	h := New()
+	h.Ephemeris = eph
+	mux := http.NewServeMux()
+	h.Register(mux)
+
+	rec := postJSON(t, mux, ExtendedAstrologyRoute+"?objects=extended", canonicalBaseline)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	if err := openapi.Validate(readPublishedOpenAPI(t), "ExtendedAstrologyEnvelope", rec.Body.Bytes()); err != nil {
+		t.Errorf("response does not match the published schema: %v", err)
+	}
+	var got struct {
+		Astrology output.Astrology    `json:"astrology"`
+		Objects   *extended.ObjectSet `json:"extended_objects"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	plain := postJSON(t, mux, ExtendedAstrologyRoute, canonicalBaseline)
+	if !bytes.Equal(withoutField(t, rec.Body.Bytes(), "extended_objects"),
+		withoutField(t, plain.Body.Bytes(), "extended_objects")) {
+		t.Errorf("the opt-in changed the canonical sections:\n%s\n%s", rec.Body, plain.Body)
+	}
+	if got.Objects == nil || got.Objects.Version != extended.ObjectSetVersion ||
+		len(got.Objects.Objects) != len(extended.ExtendedObjects) {
+		t.Fatalf("extended objects %+v", got.Objects)
+	}
+
+	var cusps [12]float64
+	for i, c := range got.Astrology.HouseCusps {
+		cusps[i] = float64(c.Longitude)
+	}
+	var north float64
+	for _, o := range got.Astrology.Objects {
+		if o.ObjectID == "north_node_mean" {
+			north = float64(o.Longitude)
+		}
+	}
+	for i, o := range got.Objects.Objects {
+		long := float64(o.Longitude)
+		if o.ObjectID != extended.ExtendedObjects[i].ID || o.Sign != astro.SignFor(long) ||
+			o.House != astro.HouseFor(long, cusps) {
+			t.Errorf("object %d = %+v", i, o)
+		}
+	}
+	south := got.Objects.Objects[len(got.Objects.Objects)-1]
+	if d := math.Abs(math.Remainder(float64(south.Longitude)-north-180, 360)); d > 1e-6 {
+		t.Errorf("south node %v is %v° off the north node %v + 180°", south.Longitude, d, north)
+	}
+	if south.Direction != extended.DirectionRetrograde {
+		t.Errorf("south node %+v is not retrograde", south)
+	}
+}
+
+// withoutField returns an extension response without its field
+// block, re-marshalled with sorted keys.
+func withoutField(t *testing.T, body []byte, field string) []byte {
+	t.Helper()
+	var env map[string]json.RawMessage
+	if err := json.Unmarshal(body, &env); err != nil {
+		t.Fatal(err)
+	}
+	delete(env, field)
+	out, err := json.Marshal(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// TestExtendedHouseSystemsSchiedam computes the canonical baseline's
+// house table in every system of the registry.  The astrology section
+// must stay the canonical Placidus one; a Placidus table must repeat
+// its cusps; the quadrant systems must put cusps 1 and 10 on the
+// ascendant and the midheaven, Equal cusp 1 on the ascendant and
+// Whole Sign every cusp on a sign boundary; and every object, the
+// extended ones included, must be placed with HouseFor.
+func TestExtendedHouseSystemsSchiedam(t *testing.T) {
+	eph := ephemeris.Default()
+	if err := eph.Init(); err != nil {
+		t.Fatal(err)
+	}
+	h := New()
+	h.Ephemeris = eph
+	mux := http.NewServeMux()
+	h.Register(mux)
+	plain := postJSON(t, mux, ExtendedAstrologyRoute+"?objects=extended", canonicalBaseline)
+
+	for _, sys := range astro.HouseSystems {
+		rec := postJSON(t, mux, ExtendedAstrologyRoute+"?objects=extended&house_system="+sys.Name, canonicalBaseline)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status %d: %s", sys.Name, rec.Code, rec.Body)
+		}
+		if err := openapi.Validate(readPublishedOpenAPI(t), "ExtendedAstrologyEnvelope", rec.Body.Bytes()); err != nil {
+			t.Errorf("%s: response does not match the published schema: %v", sys.Name, err)
+		}
+		var got struct {
+			Astrology output.Astrology     `json:"astrology"`
+			Objects   *extended.ObjectSet  `json:"extended_objects"`
+			Houses    *extended.HouseTable `json:"house_table"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(withoutField(t, rec.Body.Bytes(), "house_table"),
+			withoutField(t, plain.Body.Bytes(), "house_table")) {
+			t.Errorf("%s: the house table changed the rest of the response", sys.Name)
+		}
+		table := got.Houses
+		if table == nil || table.HouseSystem != sys.Name || len(table.HouseCusps) != 12 ||
+			len(table.Placements) != len(got.Astrology.Objects)+len(got.Objects.Objects) {
+			t.Fatalf("%s: house table %+v", sys.Name, table)
+		}
+
+		var cusps [12]float64
+		for i, c := range table.HouseCusps {
+			cusps[i] = float64(c.Longitude)
+		}
+		asc := float64(got.Astrology.Angles.Ascendant.Longitude)
+		mc := float64(got.Astrology.Angles.Midheaven.Longitude)
+		near := func(a, b float64) bool { return math.Abs(math.Remainder(a-b, 360)) < 1e-6 }
+		switch sys.Name {
+		case "placidus":
+			for i, c := range table.HouseCusps {
+				if c != got.Astrology.HouseCusps[i] {
+					t.Errorf("placidus cusp %d = %+v, canonical %+v", i+1, c, got.Astrology.HouseCusps[i])
+				}
+			}
+		case "koch", "porphyry", "regiomontanus", "campanus":
+			if !near(cusps[0], asc) || !near(cusps[9], mc) {
+				t.Errorf("%s: cusps 1 and 10 at %v and %v, angles %v and %v", sys.Name, cusps[0], cusps[9], asc, mc)
+			}
+		case "equal":
+			if !near(cusps[0], asc) {
+				t.Errorf("equal: cusp 1 at %v, ascendant %v", cusps[0], asc)
+			}
+		case "whole_sign":
+			for i, c := range cusps {
+				if !near(c, math.Floor(asc/30)*30+30*float64(i)) {
+					t.Errorf("whole_sign: cusp %d at %v, ascendant %v", i+1, c, asc)
+				}
+			}
+		}
+
+		longitudes := []float64{}
+		for _, o := range got.Astrology.Objects {
+			longitudes = append(longitudes, float64(o.Longitude))
+		}
+		for _, o := range got.Objects.Objects {
+			longitudes = append(longitudes, float64(o.Longitude))
+		}
+		for i, p := range table.Placements {
+			if p.House != astro.HouseFor(longitudes[i], cusps) {
+				t.Errorf("%s: placement %+v of longitude %v", sys.Name, p, longitudes[i])
+			}
+		}
+	}
+}
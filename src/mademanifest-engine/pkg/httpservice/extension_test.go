@@ -0,0 +1,50 @@
+package httpservice
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mademanifest-engine/pkg/trinity/output"
+)
+
+// TestExtendedAstrologyRejects: the extension route is mounted by
+// New, accepts only POST, and answers a payload the validator
+// rejects with the /manifest error envelope.  An objects value
+// other than "extended", or a house_system outside the registry, is
+// an invalid_input, before the body is read.
+func TestExtendedAstrologyRejects(t *testing.T) {
+	h := New()
+	h.Ephemeris = goldenReplay(t)
+	mux := http.NewServeMux()
+	h.Register(mux)
+
+	rec := postJSON(t, mux, ExtendedAstrologyRoute, `{"birth_date": "1990-04-09"}`)
+	if rec.Code != http.StatusBadRequest || envelopeType(t, rec.Body.String()) != output.ErrorIncompleteInput {
+		t.Errorf("incomplete payload: status %d %s", rec.Code, rec.Body)
+	}
+
+	for query, param := range map[string]string{
+		"?objects=":                              ObjectsParam,
+		"?objects=all":                           ObjectsParam,
+		"?objects=EXTENDED":                      ObjectsParam,
+		"?house_system=":                         HouseSystemParam,
+		"?house_system=topocentric":              HouseSystemParam,
+		"?house_system=Koch":                     HouseSystemParam,
+		"?objects=extended&house_system=equal_a": HouseSystemParam,
+	} {
+		rec := postJSON(t, mux, ExtendedAstrologyRoute+query, canonicalBaseline)
+		if rec.Code != http.StatusBadRequest || envelopeType(t, rec.Body.String()) != output.ErrorInvalidInput ||
+			!strings.Contains(rec.Body.String(), param) {
+			t.Errorf("%s: status %d %s", query, rec.Code, rec.Body)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, ExtendedAstrologyRoute, nil)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET %s: status %d, want 405", ExtendedAstrologyRoute, rec.Code)
+	}
+}
@@ -1,6 +1,8 @@
 package httpservice
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -8,9 +10,12 @@ import (
 	"log"
 	"mime"
 	"net/http"
+	"net/url"
+	"time"
 
 	"mademanifest-engine/pkg/canon"
 	"mademanifest-engine/pkg/ephemeris"
+	"mademanifest-engine/pkg/hd/calc"
 	"mademanifest-engine/pkg/hd/structure"
 	"mademanifest-engine/pkg/trinity/astro"
 	"mademanifest-engine/pkg/trinity/genekeys"
@@ -29,11 +34,22 @@ import (
 // unsupported_input envelope per the Phase 10 plan deliverable.
 const MaxRequestBodyBytes = 1 << 20
 
+// DefaultRequestTimeout bounds one manifest computation (one
+// /manifest request, or one /manifest/batch item) when
+// Handler.RequestTimeout is zero.  A canonical computation takes a
+// few milliseconds; the deadline only exists so a wedged request
+// cannot hold a worker forever.
+const DefaultRequestTimeout = 30 * time.Second
+
 // Processor consumes the request body and returns the bytes to send
 // back, the HTTP status code to attach, and an error.  A non-nil
 // error is treated as a server-side execution_failure: the handler
 // wraps it in a Trinity error envelope and returns HTTP 500.
 //
+// ctx carries the request's cancellation and deadline.  Processors
+// must stop work once it is done and return an error wrapping
+// ctx.Err(), which the handler answers with HTTP 503 instead of 500.
+//
 // When err is nil, body must be a complete JSON document (the Trinity
 // envelope) and status the canonical HTTP code chosen by
 // output.StatusCodeForErrorType for rejections, or 200 for the
@@ -42,7 +58,7 @@ const MaxRequestBodyBytes = 1 << 20
 // Phase 12 retired the canonPaths argument: the trinity processor
 // reads its canonical constants directly from pkg/canon, never
 // from a JSON file on disk.
-type Processor func(bodyReader io.Reader) (body []byte, status int, err error)
+type Processor func(ctx context.Context, bodyReader io.Reader) (body []byte, status int, err error)
 
 // Handler is the Trinity HTTP service.  Phase 12 retired the
 // CanonPaths field that originally piped legacy canon JSON paths
@@ -52,33 +68,113 @@ type Processor func(bodyReader io.Reader) (body []byte, status int, err error)
 // DevCORS is OFF by default and must remain so in production.
 // See the docstring on withCORS for the threat model and the dev
 // workflow that enables it.
+//
+// BatchMaxItems / BatchMaxBytes cap one POST /manifest/batch
+// request; zero selects DefaultBatchMaxItems / DefaultBatchMaxBytes.
+//
+// RequestTimeout is the deadline of one computation; zero selects
+// DefaultRequestTimeout.
+//
+// Process answers /manifest and /manifest/batch; nil selects the
+// Trinity pipeline against Ephemeris (NewProcessor).
+//
+// Cache replays success envelopes for repeated payloads on
+// /manifest and /manifest/batch; nil disables it.  It is consulted
+// by the Trinity processors (NewProcessor) only, so a custom
+// Process is never cached.
+//
+// Admission bounds the concurrent computations of /manifest,
+// /manifest/batch, /manifest/window and /extensions/v1/astrology and
+// sheds the excess with 503; nil admits everything.  A custom
+// Process holds its slot for the whole call (admitted).
+//
+// Ephemeris is the ephemeris of POST /manifest/window, POST
+// /extensions/v1/astrology, and of /manifest and /manifest/batch
+// when Process is nil; nil selects ephemeris.Default().
+//
+// Debug mounts the diagnostic POST /debug/<name> routes
+// (DebugRoutes), computing against this ephemeris; nil, the
+// default, leaves them unmounted.  They share RequestTimeout and
+// Admission with /manifest.
 type Handler struct {
-	Process Processor
-	DevCORS bool
+	Process        Processor
+	DevCORS        bool
+	BatchMaxItems  int
+	BatchMaxBytes  int64
+	RequestTimeout time.Duration
+	Cache          *ResponseCache
+	Admission      *Admission
+	Ephemeris      ephemeris.Provider
+	Debug          ephemeris.Provider
+
+	ready *readiness
 }
 
-// New wires the default Trinity processor.  CORS is OFF; flip
-// Handler.DevCORS = true (or pass --dev-cors / TRINITY_DEV_CORS=1
-// to cmd/httpserver) to enable it for the local browser test
-// client.
+func (h Handler) requestTimeout() time.Duration {
+	if h.RequestTimeout > 0 {
+		return h.RequestTimeout
+	}
+	return DefaultRequestTimeout
+}
+
+// New wires the default Trinity processor, a response cache of
+// DefaultCacheEntries and an admission limiter with the default
+// bounds.  CORS is OFF; flip Handler.DevCORS = true
+// (or pass --dev-cors / TRINITY_DEV_CORS=1 to cmd/httpserver) to
+// enable it for the local browser test client.
 func New() Handler {
 	return Handler{
-		Process: trinityProcess,
+		Cache:     NewResponseCache(DefaultCacheEntries),
+		Admission: NewAdmission(DefaultMaxInFlight, DefaultMaxQueued, DefaultQueueWait),
+		ready:     &readiness{},
 	}
 }
 
+// Register mounts every route on mux.  Each route is counted and
+// timed in the process-wide metrics served on GET /metrics, which
+// is never CORS-wrapped: it is for the cluster's scraper, not for
+// browsers.
 func (h Handler) Register(mux *http.ServeMux) {
 	healthz := http.HandlerFunc(h.handleHealth)
+	readyz := http.HandlerFunc(h.handleReady)
 	version := http.HandlerFunc(h.handleVersion)
 	manifest := http.HandlerFunc(h.handleManifest)
+	batch := http.HandlerFunc(h.handleManifestBatch)
+	window := h.handlePost("/manifest/window", h.windowProcess)
+	extension := h.handlePost(ExtendedAstrologyRoute, h.extendedProcess)
+	spec := http.HandlerFunc(h.handleOpenAPI)
 	if h.DevCORS {
 		healthz = withCORS(healthz)
+		readyz = withCORS(readyz)
 		version = withCORS(version)
 		manifest = withCORS(manifest)
+		batch = withCORS(batch)
+		window = withCORS(window)
+		extension = withCORS(extension)
+		spec = withCORS(spec)
+	}
+	m := defaultMetrics
+	mux.Handle("/healthz", m.instrument("/healthz", false, healthz))
+	mux.Handle("/readyz", m.instrument("/readyz", false, readyz))
+	mux.Handle("/version", m.instrument("/version", false, version))
+	mux.Handle("/manifest", m.instrument("/manifest", true, manifest))
+	mux.Handle("/manifest/batch", m.instrument("/manifest/batch", true, batch))
+	mux.Handle("/manifest/window", m.instrument("/manifest/window", true, window))
+	mux.Handle(ExtendedAstrologyRoute, m.instrument(ExtendedAstrologyRoute, true, extension))
+	mux.Handle("/metrics", m.instrument("/metrics", false, MetricsHandler()))
+	mux.Handle("/openapi.json", m.instrument("/openapi.json", false, spec))
+	if h.Debug == nil {
+		return
+	}
+	for _, name := range DebugRoutes() {
+		route := debugPrefix + name
+		process, _ := NewDebugProcessor(name, h.Debug)
+		debug := h.handlePost(route, process)
+		if h.DevCORS {
+			debug = withCORS(debug)
+		}
+		mux.Handle(route, m.instrument(route, true, debug))
 	}
-	mux.Handle("/healthz", healthz)
-	mux.Handle("/version", version)
-	mux.Handle("/manifest", manifest)
 }
 
 // withCORS wraps an http.HandlerFunc with permissive CORS headers
@@ -125,23 +221,34 @@ func (h Handler) handleHealth(w http.ResponseWriter, _ *http.Request) {
 // the engine has loaded.  The field never appears in the trinity
 // success/error response metadata block – that is reserved for
 // canon version pins (trinity.org §"Metadata" lines 451-462).
+//
+// ephemeris_coverage is the date window read from the loaded .se1
+// headers; births outside it are rejected as unsupported_input.  It
+// is omitted when the headers cannot be read (the boot gate would
+// normally have refused to start in that case).
 type VersionResponse struct {
 	canon.VersionInfo
-	EphePathResolved string `json:"ephe_path_resolved"`
+	EphePathResolved  string              `json:"ephe_path_resolved"`
+	EphemerisCoverage *ephemeris.Coverage `json:"ephemeris_coverage,omitempty"`
 }
 
 // handleVersion returns the compiled-in pinned versions as JSON,
-// plus the resolved ephemeris path (Phase 9 diagnostic).
+// plus the resolved ephemeris path (Phase 9 diagnostic) and the
+// ephemeris coverage window.
 func (h Handler) handleVersion(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		w.Header().Set("Allow", http.MethodGet)
 		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
 		return
 	}
-	writeJSON(w, http.StatusOK, VersionResponse{
+	resp := VersionResponse{
 		VersionInfo:      canon.Versions(),
 		EphePathResolved: ephemeris.ResolvedEphePath(),
-	})
+	}
+	if cov, err := ephemeris.EphemerisCoverage(); err == nil {
+		resp.EphemerisCoverage = &cov
+	}
+	writeJSON(w, http.StatusOK, resp)
 }
 
 func (h Handler) handleManifest(w http.ResponseWriter, r *http.Request) {
@@ -160,10 +267,22 @@ func (h Handler) handleManifest(w http.ResponseWriter, r *http.Request) {
 	// silently parsing them as JSON.
 	if msg, ok := requireJSONContentType(r); !ok {
 		env := output.NewError(output.ErrorInvalidInput, msg)
+		defaultMetrics.observeResult("/manifest", http.StatusUnsupportedMediaType, env.Error.Type)
 		writeJSON(w, http.StatusUnsupportedMediaType, env)
 		return
 	}
 
+	// The ?sections= selector is checked before the body is read:
+	// a malformed selector is the caller's error whatever the body
+	// says.
+	sections, err := parseSections(r.URL.Query())
+	if err != nil {
+		env := output.NewError(output.ErrorInvalidInput, err.Error())
+		defaultMetrics.observeResult("/manifest", http.StatusBadRequest, env.Error.Type)
+		writeJSON(w, http.StatusBadRequest, env)
+		return
+	}
+
 	r.Body = http.MaxBytesReader(w, r.Body, MaxRequestBodyBytes)
 
 	defer func() {
@@ -171,31 +290,43 @@ func (h Handler) handleManifest(w http.ResponseWriter, r *http.Request) {
 			log.Printf("manifest handler panic: %v", recovered)
 			env := output.NewError(output.ErrorExecutionFailure,
 				"internal processing error")
+			defaultMetrics.observeResult("/manifest", http.StatusInternalServerError, env.Error.Type)
 			writeJSON(w, http.StatusInternalServerError, env)
 		}
 	}()
 
-	body, status, err := h.Process(r.Body)
+	// The computation runs under the request context, so a client
+	// that disconnects stops it, bounded by the handler's deadline.
+	ctx := withCache(withSections(r.Context(), sections), h.Cache)
+	ctx, cancel := context.WithTimeout(ctx, h.requestTimeout())
+	defer cancel()
+	body, status, err := h.compute(ctx, r.Body)
 	if err != nil {
-		// Phase 10: distinguish oversize-body errors from generic
-		// execution failures.  http.MaxBytesReader returns
-		// *http.MaxBytesError once the cap is hit; we surface that
-		// as the canonical 413 + unsupported_input envelope before
-		// falling through to the generic 500 path.
-		var maxErr *http.MaxBytesError
-		if errors.As(err, &maxErr) {
-			env := output.NewError(output.ErrorUnsupportedInput,
-				fmt.Sprintf("request body exceeds %d-byte limit",
-					MaxRequestBodyBytes))
-			writeJSON(w, http.StatusRequestEntityTooLarge, env)
-			return
+		env, status := failureEnvelope("/manifest", err)
+		var shed *OverloadError
+		if errors.As(err, &shed) {
+			w.Header().Set("Retry-After", shed.retryAfterSeconds())
 		}
-		log.Printf("manifest processor error: %v", err)
-		env := output.NewError(output.ErrorExecutionFailure, err.Error())
-		writeJSON(w, http.StatusInternalServerError, env)
+		defaultMetrics.observeResult("/manifest", status, env.Error.Type)
+		writeJSON(w, status, env)
 		return
 	}
 
+	// Success envelopes carry a strong ETag derived from the body.
+	// A client revalidating with If-None-Match gets 304 and no body;
+	// the envelope was still produced (from the cache, for a repeat
+	// payload), so validation errors are never masked.
+	if status == http.StatusOK {
+		etag := etagFor(body)
+		w.Header().Set("ETag", etag)
+		if notModified(r, etag) {
+			defaultMetrics.observeResult("/manifest", http.StatusNotModified, "")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	defaultMetrics.observeEnvelope("/manifest", status, body)
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	if _, err := w.Write(body); err != nil {
@@ -203,6 +334,164 @@ func (h Handler) handleManifest(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// processor returns the Processor of /manifest: Process, or the
+// Trinity pipeline against Ephemeris.
+func (h Handler) processor() Processor {
+	switch {
+	case h.Process != nil:
+		return h.Process
+	case h.Ephemeris != nil:
+		return NewProcessor(h.Ephemeris)
+	}
+	return trinityProcess
+}
+
+// compute runs one /manifest payload under admission control: the
+// Trinity pipeline takes its slot itself, a custom Process is
+// admitted before it runs (admitted).
+func (h Handler) compute(ctx context.Context, bodyReader io.Reader) ([]byte, int, error) {
+	process := h.processor()
+	if h.Process != nil {
+		process = admitted(process)
+	}
+	return h.run(ctx, process, bodyReader)
+}
+
+// run reads the whole body, then runs process on it with the
+// handler's limiter attached to ctx (withAdmission).  The body is
+// read first so a slow upload never holds a slot: the limiter
+// bounds computations, not transfers.  The wait for a slot counts
+// against ctx's deadline.
+func (h Handler) run(ctx context.Context, process Processor, bodyReader io.Reader) ([]byte, int, error) {
+	raw, err := io.ReadAll(bodyReader)
+	if err != nil {
+		// I/O failures (truncated upload, MaxBytesReader trip)
+		// surface as in process: the validator never ran.
+		return nil, 0, fmt.Errorf("read request body: %w", err)
+	}
+	return process(withAdmission(ctx, h.Admission), bytes.NewReader(raw))
+}
+
+type queryKey struct{}
+
+// withQuery attaches the query string of a request to ctx, for the
+// Processors of the routes handlePost serves; cmd/manifest passes
+// its own (Offline.Query).
+func withQuery(ctx context.Context, query url.Values) context.Context {
+	return context.WithValue(ctx, queryKey{}, query)
+}
+
+// queryFrom returns the query attached to ctx, or nil.
+func queryFrom(ctx context.Context) url.Values {
+	q, _ := ctx.Value(queryKey{}).(url.Values)
+	return q
+}
+
+// handlePost serves a POST route answered by process (the debug
+// routes, /manifest/window, the /extensions/ routes) with the
+// request handling of /manifest: JSON Content-Type, body cap,
+// deadline, admission and the same failure envelopes.  The
+// request's query reaches process through its context (queryFrom).
+func (h Handler) handlePost(route string, process Processor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+		defer r.Body.Close()
+		if msg, ok := requireJSONContentType(r); !ok {
+			env := output.NewError(output.ErrorInvalidInput, msg)
+			defaultMetrics.observeResult(route, http.StatusUnsupportedMediaType, env.Error.Type)
+			writeJSON(w, http.StatusUnsupportedMediaType, env)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, MaxRequestBodyBytes)
+
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				log.Printf("%s handler panic: %v", route, recovered)
+				env := output.NewError(output.ErrorExecutionFailure, "internal processing error")
+				defaultMetrics.observeResult(route, http.StatusInternalServerError, env.Error.Type)
+				writeJSON(w, http.StatusInternalServerError, env)
+			}
+		}()
+
+		ctx, cancel := context.WithTimeout(withQuery(r.Context(), r.URL.Query()), h.requestTimeout())
+		defer cancel()
+		body, status, err := h.run(ctx, process, r.Body)
+		if err != nil {
+			env, status := failureEnvelope(route, err)
+			var shed *OverloadError
+			if errors.As(err, &shed) {
+				w.Header().Set("Retry-After", shed.retryAfterSeconds())
+			}
+			defaultMetrics.observeResult(route, status, env.Error.Type)
+			writeJSON(w, status, env)
+			return
+		}
+		defaultMetrics.observeEnvelope(route, status, body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if _, err := w.Write(body); err != nil {
+			log.Printf("write response: %v", err)
+		}
+	}
+}
+
+// failureEnvelope maps a non-nil Processor error to the Trinity
+// error envelope and HTTP status the service answers with.  Shared
+// by every route so a payload fails the same way on each surface;
+// route names the surface in the log lines.
+func failureEnvelope(route string, err error) (output.ErrorEnvelope, int) {
+	// Phase 10: distinguish oversize-body errors from generic
+	// execution failures.  http.MaxBytesReader returns
+	// *http.MaxBytesError once the cap is hit; we surface that as
+	// the canonical 413 + unsupported_input envelope before falling
+	// through to the generic 500 path.
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		return output.NewError(output.ErrorUnsupportedInput,
+				fmt.Sprintf("request body exceeds %d-byte limit", maxErr.Limit)),
+			http.StatusRequestEntityTooLarge
+	}
+	// Shed load is counted on /metrics rather than logged: under
+	// a burst a log line per refusal would add to the overload.
+	var shed *OverloadError
+	if errors.As(err, &shed) {
+		return output.NewError(output.ErrorExecutionFailure, shed.Error()+"; retry later"),
+			http.StatusServiceUnavailable
+	}
+	// A computation abandoned because its deadline expired or its
+	// client went away is not an engine defect: answer 503 so the
+	// caller (or the ingress) retries instead of reporting a 500.
+	if errors.Is(err, context.DeadlineExceeded) {
+		log.Printf("%s request abandoned: %v", route, err)
+		return output.NewError(output.ErrorExecutionFailure,
+			"computation exceeded the request deadline"), http.StatusServiceUnavailable
+	}
+	if errors.Is(err, context.Canceled) {
+		log.Printf("%s request abandoned: %v", route, err)
+		return output.NewError(output.ErrorExecutionFailure,
+			"request cancelled before the computation finished"), http.StatusServiceUnavailable
+	}
+	// Swiss Ephemeris failures (missing / corrupt .se1 data,
+	// out-of-range dates) get their own log prefix and a message
+	// naming the body and JD, so operators can tell a data-file
+	// problem from an engine bug at a glance.
+	var calcErr *ephemeris.CalcError
+	if errors.As(err, &calcErr) {
+		log.Printf("%s ephemeris error: body=%s id=%d jd=%.6f code=%d serr=%q: %v",
+			route, calcErr.Body, calcErr.BodyID, calcErr.JulianDay,
+			calcErr.Code, calcErr.Message, err)
+		return output.NewError(output.ErrorExecutionFailure,
+			"ephemeris failure: "+err.Error()), http.StatusInternalServerError
+	}
+	log.Printf("%s processor error: %v", route, err)
+	return output.NewError(output.ErrorExecutionFailure, err.Error()),
+		http.StatusInternalServerError
+}
+
 // requireJSONContentType inspects the request's Content-Type header
 // and returns ("", true) when it is application/json (with optional
 // parameters such as charset=utf-8), or (msg, false) when it is
@@ -227,7 +516,24 @@ func requireJSONContentType(r *http.Request) (string, bool) {
 	return "", true
 }
 
-// trinityProcess is the default manifest processor.  It reads the
+// NewProcessor returns the Trinity manifest processor computing
+// against eph.  New wires the process-wide Swiss Ephemeris
+// (trinityProcess); a test or an embedder passes another
+// ephemeris.Provider, such as an ephemeristest.Fake, to run the
+// whole pipeline without libswe.
+func NewProcessor(eph ephemeris.Provider) Processor {
+	return func(ctx context.Context, bodyReader io.Reader) ([]byte, int, error) {
+		return process(ctx, eph, bodyReader)
+	}
+}
+
+// trinityProcess is the default manifest processor: the Trinity
+// pipeline against ephemeris.Default().
+func trinityProcess(ctx context.Context, bodyReader io.Reader) ([]byte, int, error) {
+	return process(ctx, ephemeris.Default(), bodyReader)
+}
+
+// process is the Trinity manifest pipeline.  It reads the
 // request body, runs it through the Trinity input validator, and
 // returns either:
 //
@@ -242,7 +548,17 @@ func requireJSONContentType(r *http.Request) (string, bool) {
 // Phase 4-8 incrementally fill the placeholder calculation
 // sub-fields with real values; each fill is a strict superset of
 // the previous behaviour and does not change the wire shape.
-func trinityProcess(bodyReader io.Reader) ([]byte, int, error) {
+//
+// ctx is checked between pipeline stages and threaded into the
+// design-time solver and the activation snapshots, the only loops
+// that make more than a handful of ephemeris calls.  It may also
+// carry a section selection (withSections); the stages no selected
+// section depends on are then skipped and the response is an
+// output.PartialSuccessEnvelope.  A ResponseCache attached with
+// withCache short-circuits everything after validation for a
+// payload it has already answered; any other valid payload takes
+// its admission slot (admit) before the gates.
+func process(ctx context.Context, eph ephemeris.Provider, bodyReader io.Reader) ([]byte, int, error) {
 	raw, err := io.ReadAll(bodyReader)
 	if err != nil {
 		// I/O failures (truncated upload, MaxBytesReader trip)
@@ -250,14 +566,43 @@ func trinityProcess(bodyReader io.Reader) ([]byte, int, error) {
 		return nil, 0, fmt.Errorf("read request body: %w", err)
 	}
 
+	done := defaultMetrics.stage(stageValidation)
 	payload, rej := input.Validate(raw)
+	done()
 	if rej != nil {
-		env := output.NewError(string(rej.Type), rej.Message)
-		body, encErr := json.Marshal(env)
-		if encErr != nil {
-			return nil, 0, fmt.Errorf("marshal error envelope: %w", encErr)
+		return rejectionEnvelope(rej)
+	}
+
+	// A payload seen before replays its cached envelope.  Only
+	// payloads that passed the gates below are ever cached, and the
+	// loaded ephemeris cannot change under a running process, so
+	// the gates are skipped on a hit.
+	sections := sectionsFrom(ctx)
+	cache := cacheFrom(ctx)
+	var key cacheKey
+	if cache != nil {
+		key = newCacheKey(payload, sections)
+		if body, ok := cache.get(key); ok {
+			return body, http.StatusOK, nil
 		}
-		return body, output.StatusCodeForErrorType(string(rej.Type)), nil
+	}
+
+	// Only now, with a valid payload the cache cannot answer, does
+	// the request wait for a computation slot.
+	release, err := admit(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer release()
+
+	// The payload is well-formed; make sure it can be computed
+	// before any longitude is.
+	rej, err = gatePayload(eph, payload)
+	if err != nil {
+		return nil, 0, err
+	}
+	if rej != nil {
+		return rejectionEnvelope(rej)
 	}
 
 	// Validation succeeded.  Build the canonical success envelope,
@@ -269,60 +614,161 @@ func trinityProcess(bodyReader io.Reader) ([]byte, int, error) {
 	// centers, structural derivations, gene keys).
 	env := output.NewPlaceholderSuccess(payload)
 
-	astroSection, err := astro.ComputeAstrology(payload)
+	// A ?sections= selector (carried in ctx) skips the stages no
+	// selected section depends on; see planFor.  Validation, the
+	// coverage gate and the house-system gate above always run, so a
+	// payload is rejected the same way whatever it asks for.
+	plan := planFor(sections)
+
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+	if plan.astrology {
+		done = defaultMetrics.stage(stageAstrology)
+		astroSection, err := astro.ComputeAstrology(eph, payload)
+		done()
+		if err != nil {
+			return nil, 0, fmt.Errorf("compute astrology: %w", err)
+		}
+		env.Astrology = astroSection
+	}
+
+	var personality, design []output.HDActivation
+	if plan.designTime {
+		snap, err := computeSnapshots(ctx, eph, payload)
+		if err != nil {
+			return nil, 0, err
+		}
+		personality, design = snap.personality, snap.design
+		env.HumanDesign.System.DesignTimeUTC = output.DesignTime(snap.designTime)
+		env.HumanDesign.PersonalityActivations = personality
+		env.HumanDesign.DesignActivations = design
+	}
+
+	if plan.structure {
+		// Phase 7: structural derivations from the combined activation
+		// set – channels, centers, definition, type, authority, profile,
+		// incarnation_cross.  Replaces the placeholder reflector / lunar /
+		// "1/1" values seeded by NewPlaceholderSuccess.
+		done = defaultMetrics.stage(stageStructure)
+		struc, err := structure.Compute(personality, design)
+		done()
+		if err != nil {
+			return nil, 0, fmt.Errorf("compute structure: %w", err)
+		}
+		env.HumanDesign.Channels = struc.Channels
+		env.HumanDesign.Centers = struc.Centers
+		env.HumanDesign.Definition = struc.Definition
+		env.HumanDesign.Type = struc.Type
+		env.HumanDesign.Authority = struc.Authority
+		env.HumanDesign.Profile = struc.Profile
+		env.HumanDesign.IncarnationCross = struc.IncarnationCross
+	}
+
+	if plan.geneKeys {
+		// Phase 8: Gene Keys block.  Derived directly from the four
+		// HD pillar activations (personality sun + earth, design sun +
+		// earth) — no astronomical computation, no node policy.
+		done = defaultMetrics.stage(stageGeneKeys)
+		gk, err := genekeys.Compute(personality, design)
+		done()
+		if err != nil {
+			return nil, 0, fmt.Errorf("compute gene keys: %w", err)
+		}
+		env.GeneKeys = gk
+	}
+
+	var encoded any = env
+	if sections != nil {
+		encoded = env.Partial(sections)
+	}
+	body, encErr := json.Marshal(encoded)
+	if encErr != nil {
+		return nil, 0, fmt.Errorf("marshal success envelope: %w", encErr)
+	}
+	cache.put(key, body)
+	return body, http.StatusOK, nil
+}
+
+// gatePayload runs the gates between validation and computation.
+// The coverage gate makes sure the ephemeris covers both the birth
+// moment and the design-time search window before any longitude is
+// computed.  Inside the polar circles the canonical Placidus houses
+// do not exist; the house-system gate rejects such a birth whatever
+// sections it asks for, rather than ship substitute cusps.
+func gatePayload(eph ephemeris.Provider, payload input.Payload) (*input.Rejection, error) {
+	done := defaultMetrics.stage(stageCoverage)
+	rej, err := hd.CheckEphemerisCoverage(eph, payload)
+	done()
 	if err != nil {
-		return nil, 0, fmt.Errorf("compute astrology: %w", err)
+		return nil, fmt.Errorf("check ephemeris coverage: %w", err)
+	}
+	if rej != nil {
+		return rej, nil
 	}
-	env.Astrology = astroSection
 
-	designTime, err := hd.ComputeDesignTime(payload)
+	done = defaultMetrics.stage(stageHouseSystem)
+	rej, err = astro.CheckHouseSystem(eph, payload)
+	done()
 	if err != nil {
-		return nil, 0, fmt.Errorf("compute design time: %w", err)
+		return nil, fmt.Errorf("check house system: %w", err)
 	}
-	env.HumanDesign.System.DesignTimeUTC = output.DesignTime(designTime)
+	return rej, nil
+}
 
-	// Phase 6: personality + design activation arrays.  We re-use
-	// the already-computed design moment (designTime) to avoid
-	// running the bisection solver a second time.  Personality is
-	// the snapshot at birth.
-	designJD := hd.DesignJDFromTime(designTime)
-	personality, design, err := hd.ComputeActivations(payload, designJD)
+// snapshots are the design moment and the two activation arrays of
+// a payload.
+type snapshots struct {
+	designTime  time.Time
+	personality []output.HDActivation
+	design      []output.HDActivation
+}
+
+// computeSnapshots runs the design-time solve (Phase 5) and the
+// personality + design activation snapshots (Phase 6).  The design
+// moment is re-used for the design snapshot rather than running
+// the bisection solver a second time; personality is the snapshot
+// at birth.
+func computeSnapshots(ctx context.Context, eph ephemeris.Provider, payload input.Payload) (snapshots, error) {
+	var s snapshots
+	var err error
+	s.designTime, _, err = solveDesignTime(ctx, eph, payload)
 	if err != nil {
-		return nil, 0, fmt.Errorf("compute activations: %w", err)
+		return s, err
 	}
-	env.HumanDesign.PersonalityActivations = personality
-	env.HumanDesign.DesignActivations = design
 
-	// Phase 7: structural derivations from the combined activation
-	// set – channels, centers, definition, type, authority, profile,
-	// incarnation_cross.  Replaces the placeholder reflector / lunar /
-	// "1/1" values seeded by NewPlaceholderSuccess.
-	struc, err := structure.Compute(personality, design)
+	done := defaultMetrics.stage(stageActivations)
+	s.personality, s.design, err = hd.ComputeActivations(ctx, eph, payload, hd.DesignJDFromTime(s.designTime))
+	done()
 	if err != nil {
-		return nil, 0, fmt.Errorf("compute structure: %w", err)
-	}
-	env.HumanDesign.Channels = struc.Channels
-	env.HumanDesign.Centers = struc.Centers
-	env.HumanDesign.Definition = struc.Definition
-	env.HumanDesign.Type = struc.Type
-	env.HumanDesign.Authority = struc.Authority
-	env.HumanDesign.Profile = struc.Profile
-	env.HumanDesign.IncarnationCross = struc.IncarnationCross
-
-	// Phase 8: Gene Keys block.  Derived directly from the four
-	// HD pillar activations (personality sun + earth, design sun +
-	// earth) — no astronomical computation, no node policy.
-	gk, err := genekeys.Compute(personality, design)
+		return s, fmt.Errorf("compute activations: %w", err)
+	}
+	return s, nil
+}
+
+// solveDesignTime runs the design-time solver as a timed pipeline
+// stage and feeds its diagnostics to the solver metrics.
+func solveDesignTime(ctx context.Context, eph ephemeris.Provider, payload input.Payload) (time.Time, calc.Diagnostics, error) {
+	done := defaultMetrics.stage(stageDesignTime)
+	designTime, diag, err := hd.ComputeDesignTimeWithDiagnostics(ctx, eph, payload)
+	done()
 	if err != nil {
-		return nil, 0, fmt.Errorf("compute gene keys: %w", err)
+		return designTime, diag, fmt.Errorf("compute design time: %w", err)
 	}
-	env.GeneKeys = gk
+	defaultMetrics.observeSolve(diag)
+	return designTime, diag, nil
+}
 
-	body, encErr := json.Marshal(env)
-	if encErr != nil {
-		return nil, 0, fmt.Errorf("marshal success envelope: %w", encErr)
+// rejectionEnvelope renders a validator or coverage-gate rejection
+// as a Trinity error envelope with its canonical HTTP status.
+func rejectionEnvelope(rej *input.Rejection) ([]byte, int, error) {
+	env := output.NewError(string(rej.Type), rej.Message)
+	env.Error.Code = string(rej.Code)
+	body, err := json.Marshal(env)
+	if err != nil {
+		return nil, 0, fmt.Errorf("marshal error envelope: %w", err)
 	}
-	return body, http.StatusOK, nil
+	return body, output.StatusCodeForErrorType(string(rej.Type)), nil
 }
 
 func writeJSON(w http.ResponseWriter, status int, payload any) {
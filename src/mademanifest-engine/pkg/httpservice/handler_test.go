@@ -1,15 +1,20 @@
 package httpservice
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"mademanifest-engine/pkg/canon"
+	"mademanifest-engine/pkg/ephemeris"
+	"mademanifest-engine/pkg/ephemeris/ephemeristest"
 	"mademanifest-engine/pkg/trinity/output"
 )
 
@@ -76,6 +81,40 @@ func TestHandleManifestValidatesAndReturnsErrorEnvelope(t *testing.T) {
 	}
 }
 
+// TestHandleManifestRejectionCarriesErrorCode proves a validator
+// rejection with a RejectionCode surfaces it as error_code, and that
+// error_code stays absent from rejections without one.
+func TestHandleManifestRejectionCarriesErrorCode(t *testing.T) {
+	handler := New()
+	for _, tc := range []struct {
+		body, wantCode string
+	}{
+		{`{"birth_date":"1990-03-25","birth_time":"02:30","timezone":"Europe/Amsterdam","latitude":51.9167,"longitude":4.4}`,
+			"nonexistent_local_time"},
+		{`{"birth_date":"1990-04-09"}`, ""},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/manifest", strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+
+		handler.handleManifest(rec, req)
+
+		var raw struct {
+			Error map[string]any `json:"error"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &raw); err != nil {
+			t.Fatalf("decode envelope: %v\nbody: %s", err, rec.Body.String())
+		}
+		code, present := raw.Error["error_code"]
+		switch {
+		case tc.wantCode == "" && present:
+			t.Errorf("body %s: unexpected error_code %v", tc.body, code)
+		case tc.wantCode != "" && code != tc.wantCode:
+			t.Errorf("body %s: error_code = %v, want %q", tc.body, code, tc.wantCode)
+		}
+	}
+}
+
 // TestHandleManifestValidPayloadReturnsSuccessEnvelope drives the
 // Phase 3 success path: a fully-valid Trinity payload now returns
 // HTTP 200 with the canonical SuccessEnvelope (placeholder content
@@ -87,6 +126,7 @@ func TestHandleManifestValidatesAndReturnsErrorEnvelope(t *testing.T) {
 // (top-level keys, status, metadata, input_echo).
 func TestHandleManifestValidPayloadReturnsSuccessEnvelope(t *testing.T) {
 	handler := New()
+	handler.Ephemeris = goldenReplay(t)
 	req := httptest.NewRequest(http.MethodPost, "/manifest",
 		strings.NewReader(canonicalBaseline))
 	req.Header.Set("Content-Type", "application/json")
@@ -152,7 +192,7 @@ func TestHandleManifestValidPayloadReturnsSuccessEnvelope(t *testing.T) {
 // the error as a Trinity execution_failure envelope.
 func TestHandleManifestProcessorErrorWrapsExecutionFailure(t *testing.T) {
 	handler := Handler{
-		Process: func(_ io.Reader) ([]byte, int, error) {
+		Process: func(_ context.Context, _ io.Reader) ([]byte, int, error) {
 			return nil, 0, errors.New("synthetic processor failure")
 		},
 	}
@@ -181,12 +221,153 @@ func TestHandleManifestProcessorErrorWrapsExecutionFailure(t *testing.T) {
 	}
 }
 
+// TestHandleManifestEphemerisErrorIsPrecise proves a wrapped
+// *ephemeris.CalcError reaches the client as an execution_failure
+// whose message names the Swiss Ephemeris diagnostic, body and JD
+// rather than the opaque panic-recovery text.
+func TestHandleManifestEphemerisErrorIsPrecise(t *testing.T) {
+	handler := Handler{
+		Process: func(_ context.Context, _ io.Reader) ([]byte, int, error) {
+			return nil, 0, fmt.Errorf("compute astrology: %w", &ephemeris.CalcError{
+				Body:      "chiron",
+				BodyID:    15,
+				JulianDay: 2447991.2,
+				Code:      -1,
+				Message:   "SwissEph file 'seas_18.se1' not found in PATH",
+			})
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/manifest",
+		strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	handler.handleManifest(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", rec.Code)
+	}
+	var env output.ErrorEnvelope
+	if err := json.Unmarshal(rec.Body.Bytes(), &env); err != nil {
+		t.Fatalf("decode envelope: %v\nbody: %s", err, rec.Body.String())
+	}
+	if env.Error.Type != output.ErrorExecutionFailure {
+		t.Errorf("error_type = %q, want %q",
+			env.Error.Type, output.ErrorExecutionFailure)
+	}
+	for _, want := range []string{"ephemeris failure", "chiron", "seas_18.se1", "2447991.2"} {
+		if !strings.Contains(env.Error.Message, want) {
+			t.Errorf("envelope message %q missing %q", env.Error.Message, want)
+		}
+	}
+}
+
+// TestHandleManifestDeadlineAnswers503 proves the handler hands the
+// Processor a context bounded by RequestTimeout and renders an
+// abandoned computation as a 503 execution_failure, not a 500.
+func TestHandleManifestDeadlineAnswers503(t *testing.T) {
+	handler := Handler{
+		RequestTimeout: time.Millisecond,
+		Process: func(ctx context.Context, _ io.Reader) ([]byte, int, error) {
+			<-ctx.Done()
+			return nil, 0, fmt.Errorf("compute design time: %w", ctx.Err())
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/manifest",
+		strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	handler.handleManifest(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", rec.Code)
+	}
+	var env output.ErrorEnvelope
+	if err := json.Unmarshal(rec.Body.Bytes(), &env); err != nil {
+		t.Fatalf("decode envelope: %v\nbody: %s", err, rec.Body.String())
+	}
+	if env.Error.Type != output.ErrorExecutionFailure ||
+		!strings.Contains(env.Error.Message, "deadline") {
+		t.Errorf("error = %+v, want execution_failure naming the deadline", env.Error)
+	}
+}
+
+// TestTrinityProcessHonoursCancelledContext runs the pipeline with
+// an already-cancelled context: the computation must stop with
+// context.Canceled in the error chain rather than run to completion.
+func TestTrinityProcessHonoursCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	body, _, err := NewProcessor(goldenReplay(t))(ctx, strings.NewReader(canonicalBaseline))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v (body %s), want wrapped context.Canceled", err, body)
+	}
+}
+
+// TestNewProcessorComputesAgainstProvider runs the whole pipeline
+// on a table-backed ephemeris: every longitude in the envelope comes
+// from the Fake, and its coverage window gates the payload.
+func TestNewProcessorComputesAgainstProvider(t *testing.T) {
+	const birthJD = 2451545.0 // 2000-01-01 12:00 UT
+	const payload = `{"birth_date":"2000-01-01","birth_time":"12:00",` +
+		`"timezone":"Europe/London","latitude":52,"longitude":4}`
+	f := &ephemeristest.Fake{Window: ephemeris.Coverage{StartJD: birthJD - 1000, EndJD: birthJD + 1000}}
+	for i, body := range []string{"sun", "moon", "mercury", "venus", "mars", "jupiter",
+		"saturn", "uranus", "neptune", "pluto", "chiron", "earth",
+		"north_node", "north_node_mean", "north_node_true"} {
+		// One degree per day, so the Sun at birth is 280.5.
+		f.AddLongitude(body, birthJD-120, 160.5+float64(7*i))
+		f.AddLongitude(body, birthJD+1, 281.5+float64(7*i))
+	}
+	h := ephemeristest.HouseSample{JD: birthJD, Latitude: 52, Longitude: 4, HSys: 'P'}
+	for i := range h.Cusps {
+		h.Cusps[i] = float64(30 * i)
+	}
+	f.AddHouses(h)
+
+	body, status, err := NewProcessor(f)(context.Background(), strings.NewReader(payload))
+	if err != nil || status != http.StatusOK {
+		t.Fatalf("status %d, err %v: %s", status, err, body)
+	}
+	var env struct {
+		Astrology struct {
+			Objects []struct {
+				ObjectID  string  `json:"object_id"`
+				Longitude float64 `json:"longitude"`
+			} `json:"objects"`
+		} `json:"astrology"`
+		HumanDesign struct {
+			System struct {
+				DesignTimeUTC string `json:"design_time_utc"`
+			} `json:"system"`
+		} `json:"human_design"`
+	}
+	if err := json.Unmarshal(body, &env); err != nil {
+		t.Fatal(err)
+	}
+	if sun := env.Astrology.Objects[0]; sun.ObjectID != "sun" || sun.Longitude != 280.5 {
+		t.Errorf("astrology sun = %+v, want the Fake's 280.5", sun)
+	}
+	if got := env.HumanDesign.System.DesignTimeUTC; !strings.HasPrefix(got, "1999-10-05T12:00") {
+		t.Errorf("design_time_utc = %q, want 88 days before birth", got)
+	}
+
+	f.Window.EndJD = birthJD
+	body, status, err = NewProcessor(f)(context.Background(), strings.NewReader(payload))
+	if err != nil || status != http.StatusUnprocessableEntity {
+		t.Errorf("outside the Fake's window: status %d, err %v: %s", status, err, body)
+	}
+}
+
 // TestHandleManifestRecoversFromPanic guarantees that a panic in the
 // processor is caught and rendered as a Trinity execution_failure
 // envelope, not as a partial response or an HTTP 200.
 func TestHandleManifestRecoversFromPanic(t *testing.T) {
 	handler := Handler{
-		Process: func(_ io.Reader) ([]byte, int, error) {
+		Process: func(_ context.Context, _ io.Reader) ([]byte, int, error) {
 			panic("boom")
 		},
 	}
@@ -263,6 +444,29 @@ func TestHandleVersionSurfacesEphePathResolved(t *testing.T) {
 	}
 }
 
+// TestHandleVersionSurfacesEphemerisCoverage proves /version reports
+// the date window read from the loaded .se1 headers.
+func TestHandleVersionSurfacesEphemerisCoverage(t *testing.T) {
+	handler := New()
+	req := httptest.NewRequest(http.MethodGet, "/version", nil)
+	rec := httptest.NewRecorder()
+
+	handler.handleVersion(rec, req)
+
+	var got VersionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode /version: %v", err)
+	}
+	if got.EphemerisCoverage == nil {
+		t.Fatalf("/version missing ephemeris_coverage; body = %s", rec.Body.String())
+	}
+	if got.EphemerisCoverage.StartDate != "1800-01-01" ||
+		got.EphemerisCoverage.EndDate != "2400-01-10" {
+		t.Errorf("ephemeris_coverage = %+v, want 1800-01-01 .. 2400-01-10",
+			*got.EphemerisCoverage)
+	}
+}
+
 // TestHandleVersionRejectsWrongMethod – Phase 1 invariant.
 func TestHandleVersionRejectsWrongMethod(t *testing.T) {
 	handler := New()
@@ -380,6 +584,7 @@ func TestHandleManifestRejectsWrongContentType(t *testing.T) {
 // JSON requests sent by many clients; accept it.
 func TestHandleManifestAcceptsContentTypeWithCharset(t *testing.T) {
 	handler := New()
+	handler.Ephemeris = goldenReplay(t)
 	req := httptest.NewRequest(http.MethodPost, "/manifest",
 		strings.NewReader(canonicalBaseline))
 	req.Header.Set("Content-Type", "application/json; charset=utf-8")
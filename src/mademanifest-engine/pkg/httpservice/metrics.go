@@ -0,0 +1,205 @@
+package httpservice
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"mademanifest-engine/pkg/hd/calc"
+	"mademanifest-engine/pkg/metrics"
+)
+
+// Pipeline stage labels of mademanifest_pipeline_stage_duration_seconds,
+// in trinityProcess order.  stageWindow times the whole chart scan
+// of a /manifest/window request, whose per-instant stages are not
+// observed one by one; stageExtended the positions, speeds and
+// aspects of an /extensions/v1/astrology request.
+const (
+	stageValidation  = "validation"
+	stageCoverage    = "ephemeris_coverage"
+	stageHouseSystem = "house_system"
+	stageAstrology   = "astrology"
+	stageDesignTime  = "design_time"
+	stageActivations = "activations"
+	stageStructure   = "structure"
+	stageGeneKeys    = "gene_keys"
+	stageWindow      = "window_scan"
+	stageExtended    = "extended_astrology"
+)
+
+// errorTypeNone is the error_type label of a success envelope.
+const errorTypeNone = "none"
+
+// serviceMetrics is the engine's Prometheus instrumentation, served
+// on GET /metrics.  It is process-wide like the ephemeris worker:
+// trinityProcess is a plain Processor function and every Handler in
+// the process shares the one pipeline it instruments.
+type serviceMetrics struct {
+	registry *metrics.Registry
+
+	httpRequests *metrics.CounterVec
+	httpDuration *metrics.HistogramVec
+	inFlight     *metrics.GaugeVec
+
+	results       *metrics.CounterVec
+	stageDuration *metrics.HistogramVec
+
+	bisectionIterations *metrics.HistogramVec
+	bracketExpansions   *metrics.HistogramVec
+
+	cacheLookups   *metrics.CounterVec
+	cacheEntries   *metrics.GaugeVec
+	cacheEvictions *metrics.CounterVec
+
+	admissionInFlight   *metrics.GaugeVec
+	admissionQueued     *metrics.GaugeVec
+	admissionRejections *metrics.CounterVec
+}
+
+// Latency buckets in seconds.  A canonical /manifest computation
+// takes a few milliseconds; the upper buckets exist to catch
+// ephemeris stalls and batches.
+var (
+	stageBuckets   = []float64{0.0001, 0.00025, 0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 1}
+	requestBuckets = []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 10, 30, 60}
+)
+
+func newServiceMetrics() *serviceMetrics {
+	r := metrics.NewRegistry()
+	return &serviceMetrics{
+		registry: r,
+		httpRequests: r.NewCounterVec("mademanifest_http_requests_total",
+			"HTTP requests served, by route and HTTP status.",
+			"route", "code"),
+		httpDuration: r.NewHistogramVec("mademanifest_http_request_duration_seconds",
+			"Wall time from request receipt to handler return, by route.",
+			requestBuckets, "route"),
+		inFlight: r.NewGaugeVec("mademanifest_http_requests_in_flight",
+			"Manifest requests (/manifest and /manifest/batch) currently being served."),
+		results: r.NewCounterVec("mademanifest_manifest_results_total",
+			"Manifest envelopes produced, one per /manifest request or batch item, "+
+				"by route, HTTP status and Trinity error_type (\"none\" on success).",
+			"route", "code", "error_type"),
+		stageDuration: r.NewHistogramVec("mademanifest_pipeline_stage_duration_seconds",
+			"Time spent in each trinityProcess pipeline stage.",
+			stageBuckets, "stage"),
+		bisectionIterations: r.NewHistogramVec("mademanifest_design_time_bisection_iterations",
+			"Bisection passes per design-time solve (calc.Diagnostics.BracketIterations).",
+			[]float64{10, 15, 20, 25, 30, 40, 60}),
+		bracketExpansions: r.NewHistogramVec("mademanifest_design_time_bracket_expansions",
+			"Bracket widenings per design-time solve (calc.Diagnostics.BracketExpansions); "+
+				"non-zero only for pathological Sun rates.",
+			[]float64{0, 1, 2, 4, 8, 16}),
+		cacheLookups: r.NewCounterVec("mademanifest_response_cache_lookups_total",
+			"Response cache lookups for validated payloads, by result (hit or miss).",
+			"result"),
+		cacheEntries: r.NewGaugeVec("mademanifest_response_cache_entries",
+			"Success envelopes currently held in the response cache."),
+		cacheEvictions: r.NewCounterVec("mademanifest_response_cache_evictions_total",
+			"Least-recently-used entries evicted from a full response cache."),
+		admissionInFlight: r.NewGaugeVec("mademanifest_admission_in_flight",
+			"Manifest computations holding an admission slot."),
+		admissionQueued: r.NewGaugeVec("mademanifest_admission_queued",
+			"Manifest computations waiting for an admission slot."),
+		admissionRejections: r.NewCounterVec("mademanifest_admission_rejections_total",
+			"Manifest computations shed with 503, by reason (queue_full or queue_timeout).",
+			"reason"),
+	}
+}
+
+var defaultMetrics = newServiceMetrics()
+
+// MetricsHandler serves the process-wide registry in the Prometheus
+// text exposition format.  Handler.Register mounts it on /metrics.
+func MetricsHandler() http.Handler {
+	return defaultMetrics.registry.Handler()
+}
+
+// stage starts timing one pipeline stage; call the returned func
+// when the stage returns, whatever its outcome.
+func (m *serviceMetrics) stage(name string) func() {
+	start := time.Now()
+	return func() {
+		m.stageDuration.Observe(time.Since(start).Seconds(), name)
+	}
+}
+
+func (m *serviceMetrics) observeSolve(diag calc.Diagnostics) {
+	m.bisectionIterations.Observe(float64(diag.BracketIterations))
+	m.bracketExpansions.Observe(float64(diag.BracketExpansions))
+}
+
+// observeResult counts one manifest envelope.  errType is "" for a
+// success envelope.
+func (m *serviceMetrics) observeResult(route string, status int, errType string) {
+	if errType == "" {
+		errType = errorTypeNone
+	}
+	m.results.Inc(route, strconv.Itoa(status), errType)
+}
+
+// observeEnvelope counts a Processor envelope, reading error_type
+// back from the body on non-200 answers.
+func (m *serviceMetrics) observeEnvelope(route string, status int, body []byte) {
+	errType := ""
+	if status != http.StatusOK {
+		var env struct {
+			Error struct {
+				Type string `json:"error_type"`
+			} `json:"error"`
+		}
+		_ = json.Unmarshal(body, &env)
+		errType = env.Error.Type
+	}
+	m.observeResult(route, status, errType)
+}
+
+// instrument wraps a route with the request counter and latency
+// histogram.  Manifest routes also move the in-flight gauge, the
+// signal the HPA can scale on.
+func (m *serviceMetrics) instrument(route string, manifest bool, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		if manifest {
+			m.inFlight.Add(1)
+			defer m.inFlight.Add(-1)
+		}
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		defer func() {
+			m.httpRequests.Inc(route, strconv.Itoa(sw.status))
+			m.httpDuration.Observe(time.Since(start).Seconds(), route)
+		}()
+		next.ServeHTTP(sw, r)
+	})
+}
+
+// statusWriter records the status code written through it.  Unwrap
+// keeps http.ResponseController (full duplex, per-item deadlines on
+// /manifest/batch) working through the wrapper, and Flush keeps
+// batch lines streaming.
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.status, w.wroteHeader = code, true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *statusWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
@@ -0,0 +1,91 @@
+package httpservice
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"mademanifest-engine/pkg/metrics"
+)
+
+// scrape GETs /metrics through mux and returns every sample line
+// keyed by series (name plus labels).
+func scrape(t *testing.T, mux *http.ServeMux) map[string]float64 {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /metrics status = %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != metrics.ContentType {
+		t.Errorf("Content-Type = %q, want %q", ct, metrics.ContentType)
+	}
+	out := map[string]float64{}
+	sc := bufio.NewScanner(rec.Body)
+	for sc.Scan() {
+		line := sc.Text()
+		if strings.HasPrefix(line, "#") || line == "" {
+			continue
+		}
+		i := strings.LastIndexByte(line, ' ')
+		v, err := strconv.ParseFloat(line[i+1:], 64)
+		if err != nil {
+			t.Fatalf("unparseable sample %q: %v", line, err)
+		}
+		out[line[:i]] = v
+	}
+	return out
+}
+
+// TestMetricsCountManifestStagesAndResults drives one success and
+// one rejection through the registered mux and checks that the
+// request, result, per-stage latency and solver series moved.  The
+// registry is process-wide, so the test compares deltas.
+func TestMetricsCountManifestStagesAndResults(t *testing.T) {
+	mux := http.NewServeMux()
+	h := New()
+	h.Ephemeris = goldenReplay(t)
+	h.Register(mux)
+	before := scrape(t, mux)
+
+	post := func(body string) int {
+		req := httptest.NewRequest(http.MethodPost, "/manifest", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	if code := post(canonicalBaseline); code != http.StatusOK {
+		t.Fatalf("baseline status = %d", code)
+	}
+	if code := post(`{"birth_date":"1990-04-09"}`); code != http.StatusBadRequest {
+		t.Fatalf("incomplete status = %d", code)
+	}
+	after := scrape(t, mux)
+
+	deltas := map[string]float64{
+		`mademanifest_http_requests_total{route="/manifest",code="200"}`:                                  1,
+		`mademanifest_http_requests_total{route="/manifest",code="400"}`:                                  1,
+		`mademanifest_http_request_duration_seconds_count{route="/manifest"}`:                             2,
+		`mademanifest_manifest_results_total{route="/manifest",code="200",error_type="none"}`:             1,
+		`mademanifest_manifest_results_total{route="/manifest",code="400",error_type="incomplete_input"}`: 1,
+		`mademanifest_pipeline_stage_duration_seconds_count{stage="validation"}`:                          2,
+		`mademanifest_design_time_bisection_iterations_count`:                                             1,
+		`mademanifest_design_time_bracket_expansions_count`:                                               1,
+	}
+	for _, stage := range []string{stageCoverage, stageHouseSystem, stageAstrology, stageDesignTime,
+		stageActivations, stageStructure, stageGeneKeys} {
+		deltas[`mademanifest_pipeline_stage_duration_seconds_count{stage="`+stage+`"}`] = 1
+	}
+	for series, want := range deltas {
+		if got := after[series] - before[series]; got != want {
+			t.Errorf("%s moved by %v, want %v", series, got, want)
+		}
+	}
+	if got := after["mademanifest_http_requests_in_flight"]; got != 0 {
+		t.Errorf("in-flight gauge = %v after requests completed, want 0", got)
+	}
+}
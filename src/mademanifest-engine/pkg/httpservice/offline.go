@@ -0,0 +1,180 @@
+package httpservice
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"mademanifest-engine/pkg/canon"
+	"mademanifest-engine/pkg/ephemeris"
+	"mademanifest-engine/pkg/trinity/output"
+)
+
+// SelfCheck runs the boot-time gates every engine binary must pass
+// before computing anything: the compiled-in canon, the runtime
+// tzdata release, the Swiss Ephemeris data directory and the linked
+// libswe version.  cmd/httpserver and cmd/manifest both refuse to
+// run when it fails; serving non-canonical results instead would
+// violate the canon determinism rules (trinity.org §"Determinism
+// And Versioning").
+func SelfCheck() error {
+	if err := canon.SelfCheck(); err != nil {
+		return fmt.Errorf("canon self-check failed: %w", err)
+	}
+	if err := canon.AssertTZDBVersion(); err != nil {
+		return fmt.Errorf("tzdb version assertion failed: %w", err)
+	}
+	if err := ephemeris.ValidateEphePath(); err != nil {
+		return fmt.Errorf("ephemeris path validation failed: %w", err)
+	}
+	if err := ephemeris.Default().Init(); err != nil {
+		return fmt.Errorf("ephemeris initialisation failed: %w", err)
+	}
+	return nil
+}
+
+// Offline computes Trinity envelopes without an HTTP server, for
+// cmd/manifest.  Each payload goes through the very pipeline
+// /manifest runs (process, failureEnvelope), so a payload gets the
+// same envelope and the same status on both surfaces; only the
+// response cache and the admission limiter, which exist for a
+// shared server, are left out.
+//
+// Provider is the ephemeris to compute against; nil selects
+// ephemeris.Default().  Sections is a ?sections= selector value
+// ("" for the full envelope).  Timeout is the deadline of one
+// computation; zero selects DefaultRequestTimeout.  Explain names a
+// debug route (DebugRoutes); when set, each payload is answered
+// with that route's trace instead of its envelope, and Sections
+// must be empty; Query holds the route's options, as its query
+// string would on the service.  Window, when non-zero, answers each
+// payload with its POST /manifest/window report for ±Window minutes
+// instead; it excludes Sections and Explain.
+type Offline struct {
+	Provider ephemeris.Provider
+	Sections string
+	Timeout  time.Duration
+	Explain  string
+	Query    url.Values
+	Window   int
+}
+
+func (o Offline) provider() ephemeris.Provider {
+	if o.Provider != nil {
+		return o.Provider
+	}
+	return ephemeris.Default()
+}
+
+func (o Offline) timeout() time.Duration {
+	if o.Timeout > 0 {
+		return o.Timeout
+	}
+	return DefaultRequestTimeout
+}
+
+// sections parses the selector as /manifest parses its query,
+// after checking Explain names a debug route and Window is in range.
+func (o Offline) sections() ([]output.Section, error) {
+	if o.Window != 0 {
+		if o.Sections != "" || o.Explain != "" {
+			return nil, errors.New("a birth-time window cannot be combined with a section selector or a debug route")
+		}
+		return nil, checkWindow(o.Window)
+	}
+	if o.Explain != "" {
+		if _, ok := explainers[o.Explain]; !ok {
+			return nil, fmt.Errorf("unknown debug route %q (want one of %s)",
+				o.Explain, strings.Join(DebugRoutes(), ", "))
+		}
+		if o.Sections != "" {
+			return nil, errors.New("a section selector cannot be combined with a debug route")
+		}
+	}
+	if o.Sections == "" {
+		return nil, nil
+	}
+	return parseSections(url.Values{SectionsParam: {o.Sections}})
+}
+
+// Envelope computes the envelope of the single payload read from
+// body and the HTTP status /manifest would answer with.  Like
+// /manifest it holds the payload to MaxRequestBodyBytes and turns
+// every failure, panics included, into an error envelope.
+func (o Offline) Envelope(ctx context.Context, body io.Reader) ([]byte, int) {
+	sections, err := o.sections()
+	if err != nil {
+		return mustMarshal(output.NewError(output.ErrorInvalidInput, err.Error())), http.StatusBadRequest
+	}
+	return o.compute(ctx, sections, http.MaxBytesReader(nil, io.NopCloser(body), MaxRequestBodyBytes))
+}
+
+// NDJSON computes one envelope per non-blank line of in, in order,
+// handing each to emit with its HTTP status.  A line longer than
+// MaxRequestBodyBytes gets the 413 unsupported_input envelope and
+// the stream continues, as on /manifest/batch; a bad Sections
+// selector, Explain route or Window yields a single invalid_input
+// envelope.  NDJSON stops at the first error of in, of emit or of
+// ctx.
+func (o Offline) NDJSON(ctx context.Context, in io.Reader, emit func(body []byte, status int) error) error {
+	sections, err := o.sections()
+	if err != nil {
+		return emit(mustMarshal(output.NewError(output.ErrorInvalidInput, err.Error())), http.StatusBadRequest)
+	}
+	next := ndjsonItems(bufio.NewReader(in))
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		raw, err := next()
+		var itemErr *batchItemError
+		switch {
+		case errors.Is(err, io.EOF):
+			return nil
+		case errors.As(err, &itemErr):
+			if err := emit(mustMarshal(output.NewError(itemErr.errType, itemErr.msg)), itemErr.status); err != nil {
+				return err
+			}
+			continue
+		case err != nil:
+			return err
+		}
+		if err := emit(o.compute(ctx, sections, bytes.NewReader(raw))); err != nil {
+			return err
+		}
+	}
+}
+
+// compute runs one payload under its own deadline.
+func (o Offline) compute(ctx context.Context, sections []output.Section, body io.Reader) (out []byte, status int) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			log.Printf("manifest panic: %v", recovered)
+			out, status = mustMarshal(output.NewError(output.ErrorExecutionFailure,
+				"internal processing error")), http.StatusInternalServerError
+		}
+	}()
+	ctx, cancel := context.WithTimeout(withQuery(withSections(ctx, sections), o.Query), o.timeout())
+	defer cancel()
+	process := NewProcessor(o.provider())
+	switch {
+	case o.Window != 0:
+		process = NewWindowProcessor(o.provider(), o.Window)
+	case o.Explain != "":
+		process, _ = NewDebugProcessor(o.Explain, o.provider())
+	}
+	out, status, err := process(ctx, body)
+	if err != nil {
+		env, failStatus := failureEnvelope("offline", err)
+		return mustMarshal(env), failStatus
+	}
+	return out, status
+}
@@ -0,0 +1,124 @@
+package httpservice
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mademanifest-engine/pkg/golden"
+	"mademanifest-engine/pkg/trinity/output"
+)
+
+// TestOfflineNDJSONMatchesProcessor streams every golden input as
+// one NDJSON line, with a blank line and an over-long line mixed
+// in: each payload must get the processor's exact envelope and
+// status, the blank line nothing, the long line a 413.
+func TestOfflineNDJSONMatchesProcessor(t *testing.T) {
+	replay := goldenReplay(t)
+	fixtures, err := golden.LoadFixtures(filepath.Join("..", "..", "..", "golden", "trinity"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	type answer struct {
+		body   string
+		status int
+	}
+	var in bytes.Buffer
+	var want []answer
+	for i, f := range fixtures {
+		raw, err := f.LoadInput()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var line bytes.Buffer
+		if err := json.Compact(&line, raw); err != nil {
+			t.Fatalf("%s: %v", f.RelativePath, err)
+		}
+		body, status, err := NewProcessor(replay)(context.Background(), bytes.NewReader(line.Bytes()))
+		if err != nil {
+			t.Fatalf("%s: %v", f.RelativePath, err)
+		}
+		in.Write(line.Bytes())
+		in.WriteString("\n")
+		want = append(want, answer{string(body), status})
+		if i == 0 {
+			in.WriteString("\n" + strings.Repeat(" ", MaxRequestBodyBytes+1) + "\n")
+			want = append(want, answer{"", http.StatusRequestEntityTooLarge})
+		}
+	}
+
+	var got []answer
+	err = Offline{Provider: replay}.NDJSON(context.Background(), &in, func(body []byte, status int) error {
+		got = append(got, answer{string(body), status})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("%d envelopes, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if want[i].body == "" {
+			if got[i].status != want[i].status || envelopeType(t, got[i].body) != output.ErrorUnsupportedInput {
+				t.Errorf("line %d: status %d %s, want 413 unsupported_input", i, got[i].status, got[i].body)
+			}
+			continue
+		}
+		if got[i] != want[i] {
+			t.Errorf("line %d: status %d %s\nwant status %d %s", i, got[i].status, got[i].body,
+				want[i].status, want[i].body)
+		}
+	}
+}
+
+// TestOfflineEnvelopeHonoursSectionsAndLimits: a selector gives the
+// partial envelope /manifest?sections= gives, an unknown section is
+// an invalid_input, and an oversized payload the /manifest 413.
+func TestOfflineEnvelopeHonoursSectionsAndLimits(t *testing.T) {
+	replay := goldenReplay(t)
+	ctx := withSections(context.Background(), []output.Section{output.SectionAstrology})
+	want, _, err := NewProcessor(replay)(ctx, strings.NewReader(canonicalBaseline))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, status := Offline{Provider: replay, Sections: "astrology"}.Envelope(context.Background(),
+		strings.NewReader(canonicalBaseline))
+	if status != http.StatusOK || !bytes.Equal(body, want) {
+		t.Errorf("sections=astrology: status %d\n%s\nwant\n%s", status, body, want)
+	}
+
+	body, status = Offline{Provider: replay, Sections: "tarot"}.Envelope(context.Background(),
+		strings.NewReader(canonicalBaseline))
+	if status != http.StatusBadRequest || envelopeType(t, string(body)) != output.ErrorInvalidInput {
+		t.Errorf("sections=tarot: status %d %s", status, body)
+	}
+	var calls int
+	err = Offline{Provider: replay, Sections: "tarot"}.NDJSON(context.Background(),
+		strings.NewReader(canonicalBaseline+"\n"+canonicalBaseline+"\n"), func([]byte, int) error {
+			calls++
+			return nil
+		})
+	if err != nil || calls != 1 {
+		t.Errorf("NDJSON with sections=tarot: %d envelopes, err %v; want one", calls, err)
+	}
+
+	body, status = Offline{Provider: replay}.Envelope(context.Background(),
+		strings.NewReader(strings.Repeat(" ", MaxRequestBodyBytes+1)))
+	if status != http.StatusRequestEntityTooLarge || envelopeType(t, string(body)) != output.ErrorUnsupportedInput {
+		t.Errorf("oversized payload: status %d %s", status, body)
+	}
+}
+
+func envelopeType(t *testing.T, body string) string {
+	t.Helper()
+	var env output.ErrorEnvelope
+	if err := json.Unmarshal([]byte(body), &env); err != nil {
+		t.Fatalf("decode envelope: %v: %s", err, body)
+	}
+	return env.Error.Type
+}
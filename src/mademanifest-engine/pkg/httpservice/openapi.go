@@ -0,0 +1,437 @@
+package httpservice
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+
+	"mademanifest-engine/pkg/canon"
+	"mademanifest-engine/pkg/hd/structure"
+	"mademanifest-engine/pkg/openapi"
+	"mademanifest-engine/pkg/trinity/astro"
+	"mademanifest-engine/pkg/trinity/extended"
+	"mademanifest-engine/pkg/trinity/input"
+	"mademanifest-engine/pkg/trinity/output"
+	"mademanifest-engine/pkg/trinity/window"
+)
+
+// OpenAPIDocument returns the OpenAPI 3.1 description of the HTTP
+// surface, indented and newline-terminated.  The request and
+// response schemas are reflected from input.Payload, the
+// pkg/trinity/output envelopes and the httpservice wire types;
+// formats, ranges and enumerations come from the validator's
+// exported rules and the canon tables, so the document cannot
+// describe a contract the engine does not implement.
+//
+// The published copy is src/doc/openapi.json; a unit test fails when
+// it drifts from this function's output.  Regenerate it with
+// `mademanifest-engine --openapi > src/doc/openapi.json`.
+func OpenAPIDocument() ([]byte, error) {
+	c := openapi.NewComponents()
+	annotateContract(c)
+
+	errorEnvelope := c.Ref(output.ErrorEnvelope{})
+	success := c.Ref(output.SuccessEnvelope{})
+	partial := c.Ref(output.PartialSuccessEnvelope{})
+	payload := c.Ref(input.Payload{})
+	batchItem := c.Ref(BatchItem{})
+	// NDJSON lines cannot be described per item in OpenAPI 3.1; the
+	// line schemas are registered as components and named in the
+	// batch descriptions.
+	c.Ref(BatchResult{})
+	windowEnvelope := c.Ref(WindowEnvelope{})
+	extendedEnvelope := c.Ref(ExtendedAstrologyEnvelope{})
+	version := c.Ref(VersionResponse{})
+	ready := c.Ref(ReadyResponse{})
+
+	schemas, err := c.Schemas()
+	if err != nil {
+		return nil, err
+	}
+
+	jsonBody := func(s openapi.Schema) openapi.Schema {
+		return openapi.Schema{"application/json": openapi.Schema{"schema": s}}
+	}
+	errorResponse := func(description string) openapi.Schema {
+		return openapi.Schema{"description": description, "content": jsonBody(errorEnvelope)}
+	}
+	methodNotAllowed := openapi.Schema{
+		"description": "Wrong HTTP method; the Allow header names the accepted one.",
+		"content": jsonBody(openapi.Schema{
+			"type":                 "object",
+			"properties":           openapi.Schema{"error": openapi.Schema{"type": "string"}},
+			"required":             []string{"error"},
+			"additionalProperties": false,
+		}),
+	}
+	sectionsParam := openapi.Schema{
+		"name":        SectionsParam,
+		"in":          "query",
+		"required":    false,
+		"style":       "form",
+		"explode":     false,
+		"description": "Calculation sections to compute; omitted or all three yields the full envelope.",
+		"schema": openapi.Schema{
+			"type":     "array",
+			"items":    openapi.Schema{"$ref": openapi.RefPrefix + "Section"},
+			"minItems": 1,
+		},
+	}
+	schemas["Section"] = openapi.Schema{"type": "string", "enum": openapi.Enum(output.SectionOrder...)}
+
+	paths := openapi.Schema{
+		"/healthz": openapi.Schema{"get": openapi.Schema{
+			"summary":     "Liveness probe",
+			"operationId": "getHealthz",
+			"responses": openapi.Schema{"200": openapi.Schema{
+				"description": "The process answers HTTP.",
+				"content": jsonBody(openapi.Schema{
+					"type":                 "object",
+					"properties":           openapi.Schema{"status": openapi.Schema{"const": "ok"}},
+					"required":             []string{"status"},
+					"additionalProperties": false,
+				}),
+			}},
+		}},
+		"/readyz": openapi.Schema{"get": openapi.Schema{
+			"summary":     "Readiness probe: the canary chart matches the compiled-in oracle",
+			"operationId": "getReadyz",
+			"responses": openapi.Schema{
+				"200": openapi.Schema{"description": "Ready.", "content": jsonBody(ready)},
+				"503": openapi.Schema{"description": "Not ready; reason names the failure.", "content": jsonBody(ready)},
+				"405": methodNotAllowed,
+			},
+		}},
+		"/version": openapi.Schema{"get": openapi.Schema{
+			"summary":     "Pinned versions and ephemeris diagnostics",
+			"operationId": "getVersion",
+			"responses": openapi.Schema{
+				"200": openapi.Schema{"description": "Version block.", "content": jsonBody(version)},
+				"405": methodNotAllowed,
+			},
+		}},
+		"/manifest": openapi.Schema{"post": openapi.Schema{
+			"summary":     "Compute the Trinity envelope for one canonical payload",
+			"operationId": "postManifest",
+			"parameters": []any{sectionsParam, openapi.Schema{
+				"name":        "If-None-Match",
+				"in":          "header",
+				"required":    false,
+				"description": "Entity tags of envelopes the client already holds.",
+				"schema":      openapi.Schema{"type": "string"},
+			}},
+			"requestBody": openapi.Schema{"required": true, "content": jsonBody(payload)},
+			"responses": openapi.Schema{
+				"200": openapi.Schema{
+					"description": "Success envelope; partial when a strict subset of sections was selected.",
+					"headers": openapi.Schema{"ETag": openapi.Schema{
+						"description": "Strong entity tag: the quoted SHA-256 of the body.",
+						"schema":      openapi.Schema{"type": "string"},
+					}},
+					"content": jsonBody(openapi.Schema{"oneOf": []any{success, partial}}),
+				},
+				"304": openapi.Schema{"description": "If-None-Match matched the envelope's ETag; no body."},
+				"400": errorResponse("invalid_input or incomplete_input rejection."),
+				"405": methodNotAllowed,
+				"413": errorResponse("Body over MaxRequestBodyBytes (unsupported_input)."),
+				"415": errorResponse("Content-Type is not application/json (invalid_input)."),
+				"422": errorResponse("Valid input outside the v1 scope (unsupported_input)."),
+				"500": errorResponse("execution_failure."),
+				"503": openapi.Schema{
+					"description": "Load shed by the admission limiter, or computation abandoned: " +
+						"deadline exceeded or client gone (execution_failure).",
+					"headers": openapi.Schema{"Retry-After": openapi.Schema{
+						"description": "Seconds to wait before retrying; sent when the request was shed.",
+						"schema":      openapi.Schema{"type": "integer", "minimum": 1},
+					}},
+					"content": jsonBody(errorEnvelope),
+				},
+			},
+		}},
+		"/manifest/batch": openapi.Schema{"post": openapi.Schema{
+			"summary":     "Compute many payloads; results stream back as NDJSON",
+			"operationId": "postManifestBatch",
+			"parameters":  []any{sectionsParam},
+			"requestBody": openapi.Schema{"required": true, "content": openapi.Schema{
+				"application/json": openapi.Schema{"schema": openapi.Schema{"type": "array", "items": batchItem}},
+				NDJSONContentType: openapi.Schema{
+					"schema": openapi.Schema{
+						"type":        "string",
+						"description": "One BatchItem (#/components/schemas/BatchItem) JSON object per line.",
+					},
+				},
+			}},
+			"responses": openapi.Schema{
+				"200": openapi.Schema{
+					"description": "One BatchResult JSON object per line, in request order.",
+					"content": openapi.Schema{NDJSONContentType: openapi.Schema{"schema": openapi.Schema{
+						"type":        "string",
+						"description": "One BatchResult (#/components/schemas/BatchResult) JSON object per line.",
+					}}},
+				},
+				"400": errorResponse("Invalid sections selector or non-array application/json body."),
+				"405": methodNotAllowed,
+				"415": errorResponse("Content-Type is neither application/json nor " + NDJSONContentType + "."),
+			},
+		}},
+		"/manifest/window": openapi.Schema{"post": openapi.Schema{
+			"summary":     "Enumerate the distinct charts over a birth-time window",
+			"operationId": "postManifestWindow",
+			"parameters": []any{openapi.Schema{
+				"name":        WindowParam,
+				"in":          "query",
+				"required":    true,
+				"description": "Half-width of the window around the stated birth time, in minutes.",
+				"schema":      openapi.Schema{"type": "integer", "minimum": 1, "maximum": MaxWindowMinutes},
+			}},
+			"requestBody": openapi.Schema{"required": true, "content": jsonBody(payload)},
+			"responses": openapi.Schema{
+				"200": openapi.Schema{
+					"description": "The sub-windows of constant chart, with the fields each changes.",
+					"content":     jsonBody(windowEnvelope),
+				},
+				"400": errorResponse("Missing or invalid window_minutes, or an invalid_input / incomplete_input payload."),
+				"405": methodNotAllowed,
+				"413": errorResponse("Body over MaxRequestBodyBytes (unsupported_input)."),
+				"415": errorResponse("Content-Type is not application/json (invalid_input)."),
+				"422": errorResponse("Payload or window outside the v1 scope (unsupported_input)."),
+				"500": errorResponse("execution_failure."),
+				"503": errorResponse("Load shed, deadline exceeded or client gone (execution_failure)."),
+			},
+		}},
+		ExtendedAstrologyRoute: openapi.Schema{"post": openapi.Schema{
+			"summary":     "Compute the non-canonical extended astrology (speeds, aspects)",
+			"operationId": "postExtendedAstrology",
+			"parameters": []any{openapi.Schema{
+				"name":        ObjectsParam,
+				"in":          "query",
+				"description": "Opt in to the extended object set (extended_objects).",
+				"schema":      openapi.Schema{"type": "string", "enum": []any{ObjectsExtended}},
+			}, openapi.Schema{
+				"name":        HouseSystemParam,
+				"in":          "query",
+				"description": "Add the cusp table and object houses of a registered house system (house_table).",
+				"schema":      openapi.Schema{"type": "string", "enum": openapi.Enum(astro.HouseSystemNames()...)},
+			}},
+			"requestBody": openapi.Schema{"required": true, "content": jsonBody(payload)},
+			"responses": openapi.Schema{
+				"200": openapi.Schema{
+					"description": "The canonical astrology section, the objects' speeds and directions, the aspects between its points and, on opt-in, the extended objects and an alternative house table.",
+					"content":     jsonBody(extendedEnvelope),
+				},
+				"400": errorResponse("Invalid objects or house_system, or an invalid_input / incomplete_input payload."),
+				"405": methodNotAllowed,
+				"413": errorResponse("Body over MaxRequestBodyBytes (unsupported_input)."),
+				"415": errorResponse("Content-Type is not application/json (invalid_input)."),
+				"422": errorResponse("Payload outside the v1 scope (unsupported_input)."),
+				"500": errorResponse("execution_failure."),
+				"503": errorResponse("Load shed, deadline exceeded or client gone (execution_failure)."),
+			},
+		}},
+		"/metrics": openapi.Schema{"get": openapi.Schema{
+			"summary":     "Prometheus text exposition",
+			"operationId": "getMetrics",
+			"responses": openapi.Schema{"200": openapi.Schema{
+				"description": "Metrics in the Prometheus text format 0.0.4.",
+				"content":     openapi.Schema{"text/plain": openapi.Schema{"schema": openapi.Schema{"type": "string"}}},
+			}},
+		}},
+		"/openapi.json": openapi.Schema{"get": openapi.Schema{
+			"summary":     "This document",
+			"operationId": "getOpenAPI",
+			"responses": openapi.Schema{
+				"200": openapi.Schema{"description": "The OpenAPI 3.1 document.",
+					"content": openapi.Schema{"application/json": openapi.Schema{}}},
+				"405": methodNotAllowed,
+			},
+		}},
+	}
+
+	doc := openapi.Schema{
+		"openapi": "3.1.0",
+		"info": openapi.Schema{
+			"title":   "MadeManifest Trinity engine",
+			"version": canon.EngineVersion,
+			"description": "Deterministic astrology, Human Design and Gene Keys calculations for one " +
+				"canonical birth payload (canon " + canon.CanonVersion + ", input schema " +
+				canon.InputSchemaVersion + ").",
+		},
+		"paths":      paths,
+		"components": openapi.Schema{"schemas": schemas},
+	}
+	raw, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal OpenAPI document: %w", err)
+	}
+	return append(raw, '\n'), nil
+}
+
+// annotateContract supplies what reflection cannot see: the wire
+// form of the custom-marshalled types and the value rules of each
+// field, taken from the validator and the canon tables.
+func annotateContract(c *openapi.Components) {
+	sign := openapi.Schema{"enum": openapi.Enum(canon.SignOrder[:]...)}
+	longitude := openapi.Schema{"minimum": 0.0, "exclusiveMaximum": 360.0}
+	gate := openapi.Schema{"minimum": 1.0, "maximum": 64.0}
+	line := openapi.Schema{"minimum": 1.0, "maximum": 6.0}
+	centers := openapi.Schema{"enum": openapi.Enum(canon.CenterOrder[:]...)}
+	channelIDs := make([]string, len(canon.ChannelTable))
+	for i, ch := range canon.ChannelTable {
+		channelIDs[i] = ch.ID
+	}
+	// The system blocks are fixed by the canon; read them from the
+	// placeholder envelope rather than repeating the literals.
+	system := output.NewPlaceholderSuccess(input.Payload{})
+
+	c.Override(output.Longitude(0), openapi.Schema{"type": "number",
+		"description": "Decimal degrees written with exactly six decimal places."})
+	c.Override(output.DesignTime{}, openapi.Schema{"type": "string", "format": "date-time",
+		"pattern": `^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}Z$`})
+	c.Override(output.Section(""), openapi.Schema{"$ref": openapi.RefPrefix + "Section"})
+
+	// Request.
+	c.Annotate(input.Payload{}, "birth_date", openapi.Schema{"format": "date", "pattern": input.BirthDatePattern})
+	c.Annotate(input.Payload{}, "birth_time", openapi.Schema{"pattern": input.BirthTimePattern,
+		"description": "Local wall-clock time, minute precision; seconds are unsupported_input."})
+	c.Annotate(input.Payload{}, "timezone", openapi.Schema{"pattern": input.TimezonePattern,
+		"description": "Canonical IANA Area/Location identifier; links and abbreviations are invalid_input."})
+	c.Annotate(input.Payload{}, "latitude", openapi.Schema{"minimum": input.LatitudeMin, "maximum": input.LatitudeMax})
+	c.Annotate(input.Payload{}, "longitude", openapi.Schema{"minimum": input.LongitudeMin, "maximum": input.LongitudeMax})
+	c.Annotate(input.Payload{}, "utc_offset", openapi.Schema{"pattern": input.UTCOffsetPattern,
+		"description": "Selects one occurrence of a local time repeated by a DST fall-back."})
+	c.Annotate(output.InputEcho{}, "utc_offset", openapi.Schema{"pattern": input.UTCOffsetPattern})
+
+	// Envelopes.
+	c.Annotate(output.SuccessEnvelope{}, "status", openapi.Schema{"const": output.StatusSuccess})
+	c.Annotate(output.PartialSuccessEnvelope{}, "status", openapi.Schema{"const": output.StatusSuccess})
+	c.Annotate(output.PartialSuccessEnvelope{}, "sections", openapi.Schema{"minItems": 1,
+		"maxItems": len(output.SectionOrder) - 1})
+	c.Annotate(output.ErrorEnvelope{}, "status", openapi.Schema{"const": output.StatusError})
+	c.Annotate(output.Error{}, "error_type", openapi.Schema{"enum": openapi.Enum(output.ErrorTypes...)})
+	c.Annotate(output.Error{}, "error_code", openapi.Schema{"enum": openapi.Enum(input.RejectionCodes...)})
+
+	// Astrology.
+	c.Annotate(output.AstroSystem{}, "zodiac", openapi.Schema{"const": system.Astrology.System.Zodiac})
+	c.Annotate(output.AstroSystem{}, "house_system", openapi.Schema{"const": system.Astrology.System.HouseSystem})
+	c.Annotate(output.AstroSystem{}, "node_type", openapi.Schema{"const": system.Astrology.System.NodeType})
+	c.Annotate(output.Astrology{}, "house_cusps", openapi.Schema{"minItems": 12, "maxItems": 12})
+	c.Annotate(output.Astrology{}, "objects", openapi.Schema{
+		"minItems": len(canon.AstrologyObjectOrder), "maxItems": len(canon.AstrologyObjectOrder)})
+	c.Annotate(output.SignedLongitude{}, "longitude", longitude)
+	c.Annotate(output.SignedLongitude{}, "sign", sign)
+	c.Annotate(output.HouseCusp{}, "house", openapi.Schema{"minimum": 1.0, "maximum": 12.0})
+	c.Annotate(output.HouseCusp{}, "longitude", longitude)
+	c.Annotate(output.HouseCusp{}, "sign", sign)
+	c.Annotate(output.AstroObject{}, "object_id", openapi.Schema{"enum": openapi.Enum(canon.AstrologyObjectOrder[:]...)})
+	c.Annotate(output.AstroObject{}, "longitude", longitude)
+	c.Annotate(output.AstroObject{}, "sign", sign)
+	c.Annotate(output.AstroObject{}, "house", openapi.Schema{"minimum": 1.0, "maximum": 12.0})
+
+	// Human Design.
+	snapshot := openapi.Schema{"minItems": len(canon.HDSnapshotOrder), "maxItems": len(canon.HDSnapshotOrder)}
+	c.Annotate(output.HDSystem{}, "node_type", openapi.Schema{"const": system.HumanDesign.System.NodeType})
+	c.Annotate(output.HumanDesignOut{}, "personality_activations", snapshot)
+	c.Annotate(output.HumanDesignOut{}, "design_activations", snapshot)
+	c.Annotate(output.HumanDesignOut{}, "centers", openapi.Schema{
+		"minItems": len(canon.CenterOrder), "maxItems": len(canon.CenterOrder)})
+	c.Annotate(output.HumanDesignOut{}, "definition", openapi.Schema{"enum": openapi.Enum(structure.Definitions...)})
+	c.Annotate(output.HumanDesignOut{}, "type", openapi.Schema{"enum": openapi.Enum(structure.Types...)})
+	c.Annotate(output.HumanDesignOut{}, "authority", openapi.Schema{"enum": openapi.Enum(structure.Authorities...)})
+	c.Annotate(output.HumanDesignOut{}, "profile", openapi.Schema{"pattern": `^[1-6]/[1-6]$`})
+	c.Annotate(output.HDActivation{}, "object_id", openapi.Schema{"enum": openapi.Enum(canon.HDSnapshotOrder[:]...)})
+	c.Annotate(output.HDActivation{}, "gate", gate)
+	c.Annotate(output.HDActivation{}, "line", line)
+	c.Annotate(output.HDChannel{}, "channel_id", openapi.Schema{"enum": openapi.Enum(channelIDs...)})
+	c.Annotate(output.HDChannel{}, "gate_a", gate)
+	c.Annotate(output.HDChannel{}, "gate_b", gate)
+	c.Annotate(output.HDChannel{}, "center_a", centers)
+	c.Annotate(output.HDChannel{}, "center_b", centers)
+	c.Annotate(output.HDCenter{}, "center_id", centers)
+	c.Annotate(output.HDCenter{}, "state", openapi.Schema{"enum": openapi.Enum(structure.CenterStates...)})
+	c.Annotate(output.HDGateLine{}, "gate", gate)
+	c.Annotate(output.HDGateLine{}, "line", line)
+
+	// Gene Keys.
+	c.Annotate(output.GKSystem{}, "derivation_basis", openapi.Schema{"const": system.GeneKeys.System.DerivationBasis})
+	c.Annotate(output.GKActivation{}, "key", gate)
+	c.Annotate(output.GKActivation{}, "line", line)
+
+	// Service types.
+	c.Replace(BatchItem{}, "payload", openapi.Schema{"$ref": openapi.RefPrefix + "Payload"})
+	c.Replace(BatchResult{}, "envelope", openapi.Schema{"oneOf": []any{
+		openapi.Schema{"$ref": openapi.RefPrefix + "SuccessEnvelope"},
+		openapi.Schema{"$ref": openapi.RefPrefix + "PartialSuccessEnvelope"},
+		openapi.Schema{"$ref": openapi.RefPrefix + "ErrorEnvelope"},
+	}})
+	dateTime := openapi.Schema{"format": "date-time"}
+	c.Annotate(WindowEnvelope{}, "status", openapi.Schema{"const": output.StatusSuccess})
+	c.Annotate(window.Report{}, "window_minutes", openapi.Schema{"minimum": 1, "maximum": MaxWindowMinutes})
+	c.Annotate(window.Report{}, "birth_utc", dateTime)
+	c.Annotate(window.Report{}, "start_utc", dateTime)
+	c.Annotate(window.Report{}, "end_utc", dateTime)
+	c.Annotate(window.Report{}, "sub_windows", openapi.Schema{"minItems": 1})
+	c.Annotate(window.SubWindow{}, "start_utc", dateTime)
+	c.Annotate(window.SubWindow{}, "end_utc", dateTime)
+	c.Annotate(window.SubWindow{}, "start_local", dateTime)
+	c.Annotate(window.SubWindow{}, "end_local", dateTime)
+	points := openapi.Enum(append(canon.AstrologyObjectOrder[:], extended.PointAscendant, extended.PointMidheaven)...)
+	c.Annotate(ExtendedAstrologyEnvelope{}, "status", openapi.Schema{"const": output.StatusSuccess})
+	c.Annotate(ExtendedAstrologyEnvelope{}, "extension_version", openapi.Schema{"const": extended.Version})
+	c.Annotate(ExtendedAstrologyEnvelope{}, "orb_table_version", openapi.Schema{"const": extended.OrbTableVersion})
+	c.Annotate(ExtendedAstrologyEnvelope{}, "speeds", openapi.Schema{
+		"minItems": len(canon.AstrologyObjectOrder), "maxItems": len(canon.AstrologyObjectOrder)})
+	c.Annotate(extended.ObjectSpeed{}, "object_id", openapi.Schema{"enum": openapi.Enum(canon.AstrologyObjectOrder[:]...)})
+	c.Annotate(extended.ObjectSpeed{}, "direction", openapi.Schema{"enum": openapi.Enum(extended.Directions...)})
+	c.Annotate(extended.Aspect{}, "object_a", openapi.Schema{"enum": points})
+	c.Annotate(extended.Aspect{}, "object_b", openapi.Schema{"enum": points})
+	c.Annotate(extended.Aspect{}, "aspect", openapi.Schema{"enum": openapi.Enum(extended.AspectNames()...)})
+	c.Annotate(extended.Aspect{}, "class", openapi.Schema{"enum": []any{extended.ClassMajor, extended.ClassMinor}})
+	c.Annotate(extended.Aspect{}, "angle", openapi.Schema{"minimum": 0, "maximum": 180})
+	c.Annotate(extended.Aspect{}, "separation", openapi.Schema{"minimum": 0, "maximum": 180})
+	c.Annotate(extended.Aspect{}, "orb", openapi.Schema{"minimum": 0})
+	c.Annotate(extended.Aspect{}, "motion", openapi.Schema{"enum": []any{
+		extended.MotionApplying, extended.MotionSeparating, extended.MotionFixed}})
+	extendedObjects := make([]string, 0, len(extended.ExtendedObjects))
+	for _, o := range extended.ExtendedObjects {
+		extendedObjects = append(extendedObjects, o.ID)
+	}
+	c.Annotate(extended.ObjectSet{}, "object_set_version", openapi.Schema{"const": extended.ObjectSetVersion})
+	c.Annotate(extended.ObjectSet{}, "objects", openapi.Schema{
+		"minItems": len(extended.ExtendedObjects), "maxItems": len(extended.ExtendedObjects)})
+	c.Annotate(extended.ExtendedObject{}, "object_id", openapi.Schema{"enum": openapi.Enum(extendedObjects...)})
+	c.Annotate(extended.ExtendedObject{}, "longitude", longitude)
+	c.Annotate(extended.ExtendedObject{}, "sign", sign)
+	c.Annotate(extended.ExtendedObject{}, "house", openapi.Schema{"minimum": 1.0, "maximum": 12.0})
+	c.Annotate(extended.ExtendedObject{}, "direction", openapi.Schema{"enum": openapi.Enum(extended.Directions...)})
+	houseSystem := openapi.Schema{"enum": openapi.Enum(astro.HouseSystemNames()...)}
+	c.Annotate(extended.HouseTable{}, "house_system", houseSystem)
+	c.Annotate(extended.HouseTable{}, "house_cusps", openapi.Schema{"minItems": 12, "maxItems": 12})
+	c.Annotate(extended.Placement{}, "object_id", openapi.Schema{
+		"enum": openapi.Enum(append(canon.AstrologyObjectOrder[:], extendedObjects...)...)})
+	c.Annotate(extended.Placement{}, "house", openapi.Schema{"minimum": 1.0, "maximum": 12.0})
+	c.Annotate(ReadyResponse{}, "status", openapi.Schema{"enum": []any{"ready", "not_ready"}})
+}
+
+// openAPIDocument is built once per process: it depends only on
+// compiled-in types and constants.
+var openAPIDocument = sync.OnceValues(OpenAPIDocument)
+
+// handleOpenAPI serves GET /openapi.json.
+func (h Handler) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+	doc, err := openAPIDocument()
+	if err != nil {
+		log.Printf("openapi document: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "OpenAPI document unavailable"})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(doc)
+}
@@ -0,0 +1,148 @@
+package httpservice
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"mademanifest-engine/pkg/golden"
+	"mademanifest-engine/pkg/openapi"
+)
+
+// publishedOpenAPI is the checked-in contract, relative to this
+// package directory.
+var publishedOpenAPI = filepath.Join("..", "..", "..", "doc", "openapi.json")
+
+func readPublishedOpenAPI(t *testing.T) []byte {
+	t.Helper()
+	doc, err := os.ReadFile(publishedOpenAPI)
+	if err != nil {
+		t.Fatalf("read published contract: %v", err)
+	}
+	return doc
+}
+
+// TestOpenAPIMatchesPublishedDocument fails when a struct tag, an
+// enumeration or a validator rule changes without the published
+// contract being regenerated.
+func TestOpenAPIMatchesPublishedDocument(t *testing.T) {
+	generated, err := OpenAPIDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(generated, readPublishedOpenAPI(t)) {
+		t.Errorf("%s is stale; regenerate it with "+
+			"`mademanifest-engine --openapi > src/doc/openapi.json` and review the diff",
+			publishedOpenAPI)
+	}
+}
+
+// TestOpenAPIDescribesGoldenPack holds every golden fixture's input
+// and the engine's actual answer to it against the published
+// schemas.  The enumerations are annotated by hand from canon
+// tables, so this is what catches an engine value the contract does
+// not admit.
+func TestOpenAPIDescribesGoldenPack(t *testing.T) {
+	doc := readPublishedOpenAPI(t)
+	fixtures, err := golden.LoadFixtures(filepath.Join("..", "..", "..", "golden", "trinity"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	process := NewProcessor(goldenReplay(t))
+	for _, f := range fixtures {
+		in, err := f.LoadInput()
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, status, err := process(context.Background(), bytes.NewReader(in))
+		if err != nil {
+			t.Fatalf("%s: %v", f.RelativePath, err)
+		}
+		schema := "SuccessEnvelope"
+		if golden.IsErrorCategory(f.Category) {
+			schema = "ErrorEnvelope"
+		} else if err := openapi.Validate(doc, "Payload", in); err != nil {
+			t.Errorf("%s: input.json: %v", f.RelativePath, err)
+		}
+		if status != http.StatusOK && schema == "SuccessEnvelope" {
+			t.Errorf("%s: status %d: %s", f.RelativePath, status, body)
+			continue
+		}
+		if err := openapi.Validate(doc, schema, body); err != nil {
+			t.Errorf("%s: %s: %v", f.RelativePath, schema, err)
+		}
+	}
+}
+
+// TestOpenAPIDescribesServiceResponses covers the bodies the golden
+// pack does not produce: partial envelopes, batch lines, /version
+// and /readyz.
+func TestOpenAPIDescribesServiceResponses(t *testing.T) {
+	doc := readPublishedOpenAPI(t)
+	h := New()
+	h.Ephemeris = goldenReplay(t)
+	check := func(what, schema string, body []byte) {
+		t.Helper()
+		if err := openapi.Validate(doc, schema, body); err != nil {
+			t.Errorf("%s: %v\n%s", what, err, body)
+		}
+	}
+
+	for _, q := range []string{"sections=astrology", "sections=human_design,gene_keys"} {
+		check(q, "PartialSuccessEnvelope", postManifestQuery(t, h, q, canonicalBaseline).Body.Bytes())
+	}
+	if err := openapi.Validate(doc, "PartialSuccessEnvelope",
+		postManifestQuery(t, h, "", canonicalBaseline).Body.Bytes()); err == nil {
+		t.Error("full envelope validates as PartialSuccessEnvelope; the 200 oneOf is ambiguous")
+	}
+
+	batch := postBatch(t, h, NDJSONContentType,
+		compactJSON(t, `{"id":"ok","payload":`+canonicalBaseline+`}`)+"\n"+`{"id":"bad","payload":{}}`+"\n")
+	for _, line := range bytes.Split(bytes.TrimSpace(batch.Body.Bytes()), []byte("\n")) {
+		check("batch line", "BatchResult", line)
+	}
+
+	rec := httptest.NewRecorder()
+	h.handleVersion(rec, httptest.NewRequest(http.MethodGet, "/version", nil))
+	check("/version", "VersionResponse", rec.Body.Bytes())
+
+	rec = httptest.NewRecorder()
+	h.handleReady(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	check("/readyz", "ReadyResponse", rec.Body.Bytes())
+}
+
+// TestHandleOpenAPIServesDocument checks the route serves the
+// generated bytes and is GET-only.
+func TestHandleOpenAPIServesDocument(t *testing.T) {
+	mux := http.NewServeMux()
+	New().Register(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	want, err := OpenAPIDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" ||
+		!bytes.Equal(rec.Body.Bytes(), want) {
+		t.Fatalf("GET /openapi.json = %d %q, want 200 application/json and the generated document",
+			rec.Code, rec.Header().Get("Content-Type"))
+	}
+	var head struct {
+		OpenAPI string `json:"openapi"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &head); err != nil || head.OpenAPI != "3.1.0" {
+		t.Errorf("openapi = %q (%v), want 3.1.0", head.OpenAPI, err)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/openapi.json", nil))
+	if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != http.MethodGet {
+		t.Errorf("POST /openapi.json = %d Allow %q, want 405 GET", rec.Code, rec.Header().Get("Allow"))
+	}
+}
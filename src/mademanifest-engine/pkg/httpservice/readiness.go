@@ -0,0 +1,140 @@
+package httpservice
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// The readiness canary is the Schiedam 1990-04-09 baseline: its
+// input and the success envelope the golden pack expects for it
+// (metadata excluded, like every golden expected.json).  Both are
+// byte-for-byte copies of
+// src/golden/trinity/valid_baseline/schiedam_1990_04_09/, compiled
+// into the binary so a pod cannot report ready against an oracle it
+// read from the same (possibly broken) disk.  A unit test keeps the
+// copies in sync with the golden pack.
+var (
+	//go:embed canary/input.json
+	canaryInput []byte
+	//go:embed canary/expected.json
+	canaryExpected []byte
+)
+
+// readinessCacheTTL is how long a canary verdict is reused.  The
+// kubelet probes every few seconds per pod; one full computation
+// per TTL keeps the probe cheap while still noticing an ephemeris
+// bundle that goes bad after boot.
+const readinessCacheTTL = 30 * time.Second
+
+// ReadyResponse is the wire shape of GET /readyz.  Reason is set
+// only when Status is "not_ready".
+type ReadyResponse struct {
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// readiness caches the last canary verdict.  Handler holds it by
+// pointer so every copy of the Handler value shares one cache; a
+// Handler built without New (tests) has none and runs the canary on
+// every probe.
+type readiness struct {
+	mu      sync.Mutex
+	checked time.Time
+	err     error
+}
+
+// handleReady serves GET /readyz.  Unlike /healthz (liveness: the
+// process answers HTTP), readiness runs the canary payload through
+// the full Processor pipeline and reports ready only when the
+// envelope matches the compiled-in oracle exactly – every float
+// bit-for-bit.  A pod whose .se1 files are missing, unreadable or
+// of the wrong release therefore never receives traffic:
+// ValidateEphePath at boot only proves the directory and file
+// headers exist.
+func (h Handler) handleReady(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	if err := h.readinessVerdict(r.Context()); err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, ReadyResponse{Status: "not_ready", Reason: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, ReadyResponse{Status: "ready"})
+}
+
+// readinessVerdict returns the cached canary verdict, re-running
+// the canary once the cache has expired.  Concurrent probes wait
+// for the one in flight instead of stacking computations.
+func (h Handler) readinessVerdict(ctx context.Context) error {
+	if h.ready == nil {
+		return h.runCanary(ctx)
+	}
+	h.ready.mu.Lock()
+	defer h.ready.mu.Unlock()
+	if !h.ready.checked.IsZero() && time.Since(h.ready.checked) < readinessCacheTTL {
+		return h.ready.err
+	}
+	err := h.runCanary(ctx)
+	if errors.Is(err, context.Canceled) {
+		// The prober went away; that says nothing about the pod.
+		return err
+	}
+	if err != nil {
+		log.Printf("readiness canary failed: %v", err)
+	}
+	h.ready.checked, h.ready.err = time.Now(), err
+	return err
+}
+
+// runCanary computes the canary envelope and compares it with the
+// oracle.
+func (h Handler) runCanary(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, h.requestTimeout())
+	defer cancel()
+	body, status, err := h.processor()(ctx, bytes.NewReader(canaryInput))
+	if err != nil {
+		env, _ := failureEnvelope("/readyz", err)
+		return fmt.Errorf("canary computation failed: %s", env.Error.Message)
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("canary computation answered HTTP %d: %s", status, body)
+	}
+	return matchOracle(body, canaryExpected)
+}
+
+// matchOracle compares a success envelope with an oracle envelope,
+// ignoring the metadata block (it carries EngineVersion, which moves
+// on every release without changing a single computed value).
+// Numbers decode to float64, so equality is bit-exact.
+func matchOracle(got, want []byte) error {
+	var g, w map[string]any
+	if err := json.Unmarshal(got, &g); err != nil {
+		return fmt.Errorf("canary envelope is not JSON: %w", err)
+	}
+	if err := json.Unmarshal(want, &w); err != nil {
+		return fmt.Errorf("canary oracle is not JSON: %w", err)
+	}
+	delete(g, "metadata")
+	delete(w, "metadata")
+	for _, section := range []string{"status", "input_echo", "astrology", "human_design", "gene_keys"} {
+		if !reflect.DeepEqual(g[section], w[section]) {
+			return fmt.Errorf("canary %s differs from the compiled-in oracle", section)
+		}
+	}
+	if !reflect.DeepEqual(g, w) {
+		return errors.New("canary envelope differs from the compiled-in oracle")
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package httpservice
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mademanifest-engine/pkg/ephemeris"
+)
+
+// getReady drives handleReady and decodes the response.
+func getReady(t *testing.T, h Handler) (int, ReadyResponse) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.handleReady(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	var resp ReadyResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode /readyz body %q: %v", rec.Body.String(), err)
+	}
+	return rec.Code, resp
+}
+
+// TestReadyzPassesOnRealPipeline runs the canary through the real
+// pipeline against the golden recording: the compiled-in oracle must
+// match bit-for-bit.
+func TestReadyzPassesOnRealPipeline(t *testing.T) {
+	h := New()
+	h.Ephemeris = goldenReplay(t)
+	status, resp := getReady(t, h)
+	if status != http.StatusOK || resp.Status != "ready" {
+		t.Fatalf("/readyz = %d %+v, want 200 ready", status, resp)
+	}
+}
+
+// TestReadyzRejectsDriftedCanary perturbs one longitude in the last
+// printed digit; readiness must fail and name the section.
+func TestReadyzRejectsDriftedCanary(t *testing.T) {
+	process := NewProcessor(goldenReplay(t))
+	h := Handler{Process: func(ctx context.Context, r io.Reader) ([]byte, int, error) {
+		body, status, err := process(ctx, r)
+		return bytes.Replace(body, []byte("175.114625"), []byte("175.114626"), 1), status, err
+	}}
+	status, resp := getReady(t, h)
+	if status != http.StatusServiceUnavailable || resp.Status != "not_ready" ||
+		!strings.Contains(resp.Reason, "astrology") {
+		t.Fatalf("/readyz = %d %+v, want 503 not_ready naming astrology", status, resp)
+	}
+}
+
+// TestReadyzReportsEphemerisFailure covers the motivating case: the
+// .se1 files exist but cannot be read.
+func TestReadyzReportsEphemerisFailure(t *testing.T) {
+	h := Handler{Process: func(context.Context, io.Reader) ([]byte, int, error) {
+		return nil, 0, fmt.Errorf("compute astrology: %w", &ephemeris.CalcError{
+			Body: "sun", JulianDay: 2447991.2, Code: -1,
+			Message: "SwissEph file 'sepl_18.se1' not found in PATH",
+		})
+	}}
+	status, resp := getReady(t, h)
+	if status != http.StatusServiceUnavailable || !strings.Contains(resp.Reason, "sepl_18.se1") {
+		t.Fatalf("/readyz = %d %+v, want 503 naming the ephemeris file", status, resp)
+	}
+}
+
+// TestReadyzCachesVerdict proves repeated probes within the cache
+// TTL reuse one canary computation, failures included.
+func TestReadyzCachesVerdict(t *testing.T) {
+	calls := 0
+	h := Handler{
+		ready: &readiness{},
+		Process: func(context.Context, io.Reader) ([]byte, int, error) {
+			calls++
+			return []byte(`{"status":"success"}`), http.StatusOK, nil
+		},
+	}
+	for i := 0; i < 3; i++ {
+		if status, _ := getReady(t, h); status != http.StatusServiceUnavailable {
+			t.Fatalf("probe %d status = %d, want 503", i, status)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("canary ran %d times, want 1", calls)
+	}
+}
+
+// TestCanaryMatchesGoldenPack keeps the compiled-in canary in sync
+// with the golden fixture it was copied from: regenerating the pack
+// without updating canary/ fails here.
+func TestCanaryMatchesGoldenPack(t *testing.T) {
+	dir := filepath.Join("..", "..", "..", "golden", "trinity", "valid_baseline", "schiedam_1990_04_09")
+	for name, embedded := range map[string][]byte{
+		"input.json":    canaryInput,
+		"expected.json": canaryExpected,
+	} {
+		onDisk, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("read golden %s: %v", name, err)
+		}
+		if !bytes.Equal(onDisk, embedded) {
+			t.Errorf("canary/%s differs from %s; copy the fixture again", name, filepath.Join(dir, name))
+		}
+	}
+}
@@ -0,0 +1,55 @@
+//go:build cgo
+
+package httpservice
+
+import (
+	"flag"
+	"testing"
+
+	"mademanifest-engine/pkg/ephemeris"
+	"mademanifest-engine/pkg/ephemeris/ephemeristest"
+)
+
+var updateRecording = flag.Bool("update-ephemeris-recording", false,
+	"rewrite the golden-pack ephemeris recording from the live Swiss Ephemeris")
+
+// TestGoldenPackRecordingMatchesLiveEphemeris records every Swiss
+// Ephemeris answer the golden pack needs and requires the recording
+// to match the checked-in one bit for bit.  This pins the numeric
+// inputs of MapToGateLine, SignFor and HouseFor independently of the
+// envelopes: a libswe, data-file or flag change that moves a
+// longitude fails here even when no gate, sign or house changes.
+func TestGoldenPackRecordingMatchesLiveEphemeris(t *testing.T) {
+	recorder := ephemeristest.NewRecorder(ephemeris.Default())
+	runGoldenPack(t, recorder)
+	live, err := recorder.Recording()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *updateRecording {
+		if err := live.WriteFile(goldenRecording); err != nil {
+			t.Fatal(err)
+		}
+		t.Logf("wrote %s: %d longitudes, %d house computations",
+			goldenRecording, len(live.Longitudes), len(live.Houses))
+		return
+	}
+
+	want, err := ephemeristest.LoadRecording(goldenRecording)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diffs := want.Diff(live)
+	for i, d := range diffs {
+		if i == 10 {
+			t.Errorf("… and %d more", len(diffs)-i)
+			break
+		}
+		t.Errorf("live Swiss Ephemeris departs from %s: %s", goldenRecording, d)
+	}
+	if len(diffs) > 0 {
+		t.Log("if the change is intended, re-record with " +
+			"`go test ./pkg/httpservice -run TestGoldenPackRecordingMatchesLiveEphemeris " +
+			"-args -update-ephemeris-recording` and review the diff")
+	}
+}
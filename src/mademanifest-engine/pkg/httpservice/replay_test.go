@@ -0,0 +1,94 @@
+package httpservice
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"mademanifest-engine/pkg/ephemeris"
+	"mademanifest-engine/pkg/ephemeris/ephemeristest"
+	"mademanifest-engine/pkg/golden"
+	"mademanifest-engine/pkg/trinity/output"
+)
+
+// goldenRecording is the Swiss Ephemeris recording of the golden
+// pack, relative to this package directory.  It is checked against
+// libswe, and regenerated under -update-ephemeris-recording, by
+// TestGoldenPackRecordingMatchesLiveEphemeris.
+var goldenRecording = filepath.Join("..", "..", "..", "golden", "ephemeris", "trinity-pack.json")
+
+// runGoldenPack runs every golden fixture through the pipeline on
+// eph and holds each answer against its expected.json, as the
+// integration harness does over HTTP.
+func runGoldenPack(t *testing.T, eph ephemeris.Provider) {
+	t.Helper()
+	fixtures, err := golden.LoadFixtures(filepath.Join("..", "..", "..", "golden", "trinity"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	process := NewProcessor(eph)
+	for _, f := range fixtures {
+		in, err := f.LoadInput()
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, status, err := process(context.Background(), bytes.NewReader(in))
+		if err != nil {
+			t.Errorf("%s: %v", f.RelativePath, err)
+			continue
+		}
+		if golden.IsErrorCategory(f.Category) {
+			want, err := f.LoadExpectedError()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got output.ErrorEnvelope
+			if err := json.Unmarshal(body, &got); err != nil {
+				t.Fatalf("%s: %v", f.RelativePath, err)
+			}
+			if err := golden.CompareError(got, want, output.CurrentMetadata()); err != nil ||
+				status != output.StatusCodeForErrorType(want.Error.ErrorType) {
+				t.Errorf("%s: status %d: %v", f.RelativePath, status, err)
+			}
+			continue
+		}
+		want, err := f.LoadExpectedSuccess()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got output.SuccessEnvelope
+		if status != http.StatusOK {
+			t.Errorf("%s: status %d: %s", f.RelativePath, status, body)
+		} else if err := json.Unmarshal(body, &got); err != nil {
+			t.Fatalf("%s: %v", f.RelativePath, err)
+		} else if err := golden.CompareSuccess(got, want); err != nil {
+			t.Errorf("%s: %v", f.RelativePath, err)
+		}
+	}
+}
+
+// goldenReplay is the golden pack's recorded ephemeris.
+func goldenReplay(t *testing.T) *ephemeristest.Replay {
+	t.Helper()
+	rec, err := ephemeristest.LoadRecording(goldenRecording)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay, err := ephemeristest.NewReplay(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return replay
+}
+
+// TestGoldenPackReplaysFromRecording reproduces the whole golden
+// pack from the recorded ephemeris answers.  It needs neither cgo,
+// libswe nor the .se1 files, so CI runs it where the Swiss Ephemeris
+// is unavailable (make test-replay); a computation asking for
+// anything the recording lacks fails naming the call.
+func TestGoldenPackReplaysFromRecording(t *testing.T) {
+	runGoldenPack(t, goldenReplay(t))
+}
@@ -0,0 +1,125 @@
+package httpservice
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"mademanifest-engine/pkg/trinity/output"
+)
+
+// SectionsParam is the query parameter that selects the calculation
+// sections of a /manifest (or /manifest/batch) response, e.g.
+// ?sections=astrology or ?sections=human_design,gene_keys.  The
+// selector lives outside the body so the five-field canonical
+// payload – and input_schema_version – stay untouched.
+const SectionsParam = "sections"
+
+// parseSections reads the sections selector from a request query.
+// Values may be comma-separated, repeated, or both; order and
+// duplicates do not matter.  The result is in output.SectionOrder.
+// A missing parameter, or one that names every section, yields nil:
+// the full envelope.  An empty or unknown section name is an error
+// whose message is suitable for an invalid_input envelope.
+func parseSections(q url.Values) ([]output.Section, error) {
+	raw, present := q[SectionsParam]
+	if !present {
+		return nil, nil
+	}
+	selected := map[output.Section]bool{}
+	for _, v := range raw {
+		for _, name := range strings.Split(v, ",") {
+			s := output.Section(strings.TrimSpace(name))
+			if !knownSection(s) {
+				return nil, fmt.Errorf("query parameter %s: unknown section %q; expected a comma-separated "+
+					"subset of %s", SectionsParam, string(s), sectionList())
+			}
+			selected[s] = true
+		}
+	}
+	if len(selected) == len(output.SectionOrder) {
+		return nil, nil
+	}
+	out := make([]output.Section, 0, len(selected))
+	for _, s := range output.SectionOrder {
+		if selected[s] {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+func knownSection(s output.Section) bool {
+	for _, k := range output.SectionOrder {
+		if s == k {
+			return true
+		}
+	}
+	return false
+}
+
+func sectionList() string {
+	names := make([]string, len(output.SectionOrder))
+	for i, s := range output.SectionOrder {
+		names[i] = string(s)
+	}
+	return strings.Join(names, ", ")
+}
+
+// sectionsKey carries a section selection from the handler to the
+// Processor.  The selection is request metadata, like the deadline,
+// so it travels in the context rather than widening Processor.
+type sectionsKey struct{}
+
+// withSections attaches a selection to ctx; nil means the full
+// envelope and leaves ctx unchanged.
+func withSections(ctx context.Context, sections []output.Section) context.Context {
+	if sections == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, sectionsKey{}, sections)
+}
+
+// sectionsFrom returns the selection attached to ctx, or nil for
+// the full envelope.
+func sectionsFrom(ctx context.Context) []output.Section {
+	s, _ := ctx.Value(sectionsKey{}).([]output.Section)
+	return s
+}
+
+// sectionPlan is the dependency closure of a selection: the
+// pipeline stages trinityProcess must run to produce it.
+//
+//	astrology     -> astrology
+//	human_design  -> design time, activations, structure
+//	gene_keys     -> design time, activations (the four pillar
+//	                 activations; no structure)
+//
+// Both HD-derived sections need the activations, so designTime
+// covers the design-time solve and the activations together
+// (computeSnapshots); they are never run apart.
+type sectionPlan struct {
+	astrology  bool
+	designTime bool
+	structure  bool
+	geneKeys   bool
+}
+
+func planFor(sections []output.Section) sectionPlan {
+	if sections == nil {
+		return sectionPlan{true, true, true, true}
+	}
+	var p sectionPlan
+	for _, s := range sections {
+		switch s {
+		case output.SectionAstrology:
+			p.astrology = true
+		case output.SectionHumanDesign:
+			p.designTime, p.structure = true, true
+		case output.SectionGeneKeys:
+			p.designTime, p.geneKeys = true, true
+		}
+	}
+	return p
+}
@@ -0,0 +1,228 @@
+package httpservice
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"mademanifest-engine/pkg/trinity/output"
+)
+
+// postManifestQuery drives handleManifest with the given query
+// string and returns the recorder.
+func postManifestQuery(t *testing.T, h Handler, query, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/manifest?"+query, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	h.handleManifest(rec, req)
+	return rec
+}
+
+// TestParseSections covers the selector grammar: comma lists,
+// repeats, whitespace and duplicates normalise to SectionOrder; the
+// full set collapses to nil; empty and unknown names are errors.
+func TestParseSections(t *testing.T) {
+	cases := []struct {
+		query string
+		want  []output.Section
+		bad   bool
+	}{
+		{query: "", want: nil},
+		{query: "sections=gene_keys", want: []output.Section{output.SectionGeneKeys}},
+		{query: "sections=gene_keys,%20astrology,gene_keys",
+			want: []output.Section{output.SectionAstrology, output.SectionGeneKeys}},
+		{query: "sections=human_design&sections=astrology",
+			want: []output.Section{output.SectionAstrology, output.SectionHumanDesign}},
+		{query: "sections=gene_keys,human_design,astrology", want: nil},
+		{query: "sections=", bad: true},
+		{query: "sections=astrology,", bad: true},
+		{query: "sections=metadata", bad: true},
+		{query: "sections=Astrology", bad: true},
+	}
+	for _, c := range cases {
+		q, err := url.ParseQuery(c.query)
+		if err != nil {
+			t.Fatalf("%q: %v", c.query, err)
+		}
+		got, err := parseSections(q)
+		if c.bad {
+			if err == nil {
+				t.Errorf("%q: got %v, want an error", c.query, got)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%q = %v, %v; want %v", c.query, got, err, c.want)
+		}
+	}
+}
+
+// TestManifestSectionsMatchFullEnvelope proves every partial
+// response carries exactly the selected sections, each identical to
+// the same section of the full envelope, plus the sections marker;
+// selecting everything returns the full envelope byte-for-byte.
+func TestManifestSectionsMatchFullEnvelope(t *testing.T) {
+	h := New()
+	h.Ephemeris = goldenReplay(t)
+	fullRec := postManifestQuery(t, h, "", canonicalBaseline)
+	if fullRec.Code != http.StatusOK {
+		t.Fatalf("full status = %d: %s", fullRec.Code, fullRec.Body)
+	}
+	var full map[string]json.RawMessage
+	if err := json.Unmarshal(fullRec.Body.Bytes(), &full); err != nil {
+		t.Fatalf("decode full envelope: %v", err)
+	}
+
+	for _, sel := range []string{"astrology", "human_design", "gene_keys",
+		"astrology,human_design", "astrology,gene_keys", "human_design,gene_keys"} {
+		rec := postManifestQuery(t, h, "sections="+sel, canonicalBaseline)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d: %s", sel, rec.Code, rec.Body)
+		}
+		var got map[string]json.RawMessage
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Fatalf("%s: decode: %v", sel, err)
+		}
+		var marker []string
+		if err := json.Unmarshal(got["sections"], &marker); err != nil ||
+			strings.Join(marker, ",") != sel {
+			t.Errorf("%s: sections marker = %s", sel, got["sections"])
+		}
+		for _, key := range []string{"status", "metadata", "input_echo"} {
+			if !bytes.Equal(got[key], full[key]) {
+				t.Errorf("%s: %s differs from the full envelope", sel, key)
+			}
+		}
+		for _, s := range output.SectionOrder {
+			selected := strings.Contains(","+sel+",", ","+string(s)+",")
+			raw, present := got[string(s)]
+			switch {
+			case selected && !bytes.Equal(raw, full[string(s)]):
+				t.Errorf("%s: %s differs from the full envelope", sel, s)
+			case !selected && present:
+				t.Errorf("%s: unselected %s is present", sel, s)
+			}
+		}
+	}
+
+	rec := postManifestQuery(t, h, "sections=gene_keys,human_design,astrology", canonicalBaseline)
+	if !bytes.Equal(rec.Body.Bytes(), fullRec.Body.Bytes()) {
+		t.Errorf("selecting every section differs from the full envelope:\n%s\nvs\n%s",
+			rec.Body, fullRec.Body)
+	}
+}
+
+// TestManifestSectionsSkipUnneededStages checks the dependency
+// graph through the per-stage metrics: gene_keys alone runs the
+// design-time solver and the activations but neither astrology nor
+// the structural derivations; astrology alone touches no HD stage.
+func TestManifestSectionsSkipUnneededStages(t *testing.T) {
+	mux := http.NewServeMux()
+	h := New()
+	h.Ephemeris = goldenReplay(t)
+	h.Register(mux)
+	post := func(query string) {
+		req := httptest.NewRequest(http.MethodPost, "/manifest?"+query, strings.NewReader(canonicalBaseline))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d: %s", query, rec.Code, rec.Body)
+		}
+	}
+	series := func(stage string) string {
+		return `mademanifest_pipeline_stage_duration_seconds_count{stage="` + stage + `"}`
+	}
+
+	for _, c := range []struct {
+		query string
+		ran   map[string]float64
+	}{
+		{"sections=gene_keys", map[string]float64{
+			stageValidation: 1, stageCoverage: 1, stageHouseSystem: 1, stageAstrology: 0,
+			stageDesignTime: 1, stageActivations: 1, stageStructure: 0, stageGeneKeys: 1,
+		}},
+		{"sections=astrology", map[string]float64{
+			stageValidation: 1, stageCoverage: 1, stageHouseSystem: 1, stageAstrology: 1,
+			stageDesignTime: 0, stageActivations: 0, stageStructure: 0, stageGeneKeys: 0,
+		}},
+	} {
+		before := scrape(t, mux)
+		post(c.query)
+		after := scrape(t, mux)
+		for stage, want := range c.ran {
+			if got := after[series(stage)] - before[series(stage)]; got != want {
+				t.Errorf("%s: stage %s ran %v times, want %v", c.query, stage, got, want)
+			}
+		}
+	}
+}
+
+// TestManifestRejectsUnknownSection proves a bad selector is a 400
+// invalid_input envelope answered before the body is processed.
+func TestManifestRejectsUnknownSection(t *testing.T) {
+	called := false
+	h := Handler{Process: func(context.Context, io.Reader) ([]byte, int, error) {
+		called = true
+		return nil, 0, nil
+	}}
+	rec := postManifestQuery(t, h, "sections=astrology,tarot", canonicalBaseline)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", rec.Code)
+	}
+	var env output.ErrorEnvelope
+	if err := json.Unmarshal(rec.Body.Bytes(), &env); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if env.Error.Type != output.ErrorInvalidInput || !strings.Contains(env.Error.Message, `"tarot"`) {
+		t.Errorf("error = %+v, want invalid_input naming tarot", env.Error)
+	}
+	if called {
+		t.Error("processor ran despite the invalid selector")
+	}
+
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/manifest/batch?sections=", strings.NewReader(`[]`))
+	req.Header.Set("Content-Type", "application/json")
+	h.handleManifestBatch(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("batch status = %d, want 400", rec.Code)
+	}
+}
+
+// TestManifestBatchAppliesSections proves the batch selector reaches
+// every item while rejections keep their usual envelope.
+func TestManifestBatchAppliesSections(t *testing.T) {
+	body := `{"id":"a","payload":` + compactJSON(t, canonicalBaseline) + "}\n" +
+		`{"id":"b","payload":{"birth_date":"1990-04-09"}}` + "\n"
+	req := httptest.NewRequest(http.MethodPost, "/manifest/batch?sections=gene_keys", strings.NewReader(body))
+	req.Header.Set("Content-Type", NDJSONContentType)
+	rec := httptest.NewRecorder()
+	h := New()
+	h.Ephemeris = goldenReplay(t)
+	h.handleManifestBatch(rec, req)
+
+	results := decodeBatch(t, rec.Body.Bytes())
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2: %s", len(results), rec.Body)
+	}
+	var env output.PartialSuccessEnvelope
+	if err := json.Unmarshal(results[0].Envelope, &env); err != nil {
+		t.Fatalf("decode item 0: %v", err)
+	}
+	if !reflect.DeepEqual(env.Sections, []output.Section{output.SectionGeneKeys}) ||
+		env.GeneKeys == nil || env.Astrology != nil || env.HumanDesign != nil {
+		t.Errorf("item 0 = %s, want a gene_keys-only envelope", results[0].Envelope)
+	}
+	if got := envelopeErrorType(t, results[1]); got != output.ErrorIncompleteInput {
+		t.Errorf("item 1 error_type = %q, want incomplete_input", got)
+	}
+}
@@ -0,0 +1,157 @@
+package httpservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"mademanifest-engine/pkg/astronomy"
+	"mademanifest-engine/pkg/ephemeris"
+	"mademanifest-engine/pkg/trinity/astro"
+	"mademanifest-engine/pkg/trinity/hd"
+	"mademanifest-engine/pkg/trinity/input"
+	"mademanifest-engine/pkg/trinity/output"
+	"mademanifest-engine/pkg/trinity/window"
+)
+
+// WindowParam is the query parameter of POST /manifest/window: the
+// half-width of the birth-time window in whole minutes, from 1 to
+// MaxWindowMinutes.  It is required.
+const (
+	WindowParam      = "window_minutes"
+	MaxWindowMinutes = 720
+)
+
+// WindowEnvelope is the success answer of POST /manifest/window:
+// the distinct charts a birth produces over ±window_minutes around
+// its stated time (window.Report).  Rejections use the Trinity
+// error envelope, exactly as /manifest does for the same payload.
+type WindowEnvelope struct {
+	Status    string           `json:"status"` // always "success"
+	Metadata  output.Metadata  `json:"metadata"`
+	InputEcho output.InputEcho `json:"input_echo"`
+	Window    window.Report    `json:"window"`
+}
+
+// parseWindow reads the ?window_minutes= value of a /manifest/window
+// request.
+func parseWindow(query url.Values) (int, error) {
+	if !query.Has(WindowParam) {
+		return 0, fmt.Errorf("%s is required", WindowParam)
+	}
+	raw := query.Get(WindowParam)
+	minutes, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("%s %q is not an integer", WindowParam, raw)
+	}
+	return minutes, checkWindow(minutes)
+}
+
+func checkWindow(minutes int) error {
+	if minutes < 1 || minutes > MaxWindowMinutes {
+		return fmt.Errorf("%s %d is outside [1, %d]", WindowParam, minutes, MaxWindowMinutes)
+	}
+	return nil
+}
+
+// windowProcess is the Processor of POST /manifest/window: it reads
+// the window from the request query before the body, so a bad
+// window_minutes is the caller's error whatever the body says.
+func (h Handler) windowProcess(ctx context.Context, bodyReader io.Reader) ([]byte, int, error) {
+	minutes, err := parseWindow(queryFrom(ctx))
+	if err != nil {
+		return mustMarshal(output.NewError(output.ErrorInvalidInput, err.Error())), http.StatusBadRequest, nil
+	}
+	eph := h.Ephemeris
+	if eph == nil {
+		eph = ephemeris.Default()
+	}
+	return NewWindowProcessor(eph, minutes)(ctx, bodyReader)
+}
+
+// NewWindowProcessor returns the processor answering a payload with
+// its birth-time window of ±minutes against eph.  The payload goes
+// through the validator and the gates of /manifest, and both ends
+// of the window through the ephemeris coverage gate; a window that
+// reaches a moment without Placidus houses is rejected like a polar
+// birth.
+func NewWindowProcessor(eph ephemeris.Provider, minutes int) Processor {
+	return func(ctx context.Context, bodyReader io.Reader) ([]byte, int, error) {
+		if err := checkWindow(minutes); err != nil {
+			return mustMarshal(output.NewError(output.ErrorInvalidInput, err.Error())), http.StatusBadRequest, nil
+		}
+		raw, err := io.ReadAll(bodyReader)
+		if err != nil {
+			return nil, 0, fmt.Errorf("read request body: %w", err)
+		}
+		payload, rej := input.Validate(raw)
+		if rej != nil {
+			return rejectionEnvelope(rej)
+		}
+		release, err := admit(ctx)
+		if err != nil {
+			return nil, 0, err
+		}
+		defer release()
+		rej, err = gatePayload(eph, payload)
+		if err != nil {
+			return nil, 0, err
+		}
+		if rej != nil {
+			return rejectionEnvelope(rej)
+		}
+
+		birth, rej := input.LocalToUTC(payload)
+		if rej != nil {
+			return nil, 0, fmt.Errorf("convert birth time: %w", rej)
+		}
+		half := time.Duration(minutes) * time.Minute
+		for _, edge := range []time.Time{birth.Add(-half), birth.Add(half)} {
+			rej, err := hd.CheckEphemerisCoverageAt(eph, astronomy.ConvertUTCToJulianDay(edge))
+			if err != nil {
+				return nil, 0, fmt.Errorf("check ephemeris coverage: %w", err)
+			}
+			if rej != nil {
+				return rejectionEnvelope(rej)
+			}
+		}
+		loc, err := time.LoadLocation(payload.Timezone)
+		if err != nil {
+			return nil, 0, fmt.Errorf("load timezone: %w", err)
+		}
+
+		chart := func(ctx context.Context, t time.Time) (window.Chart, error) {
+			return window.ChartAt(ctx, eph, t, payload.Latitude, payload.Longitude)
+		}
+		done := defaultMetrics.stage(stageWindow)
+		report, err := window.Compute(ctx, chart, birth, minutes, loc)
+		done()
+		var undefined *ephemeris.HousesError
+		if errors.As(err, &undefined) {
+			rej, gateErr := astro.CheckHouseSystemAt(eph, undefined.JulianDay, undefined.Latitude, undefined.Longitude)
+			if gateErr == nil && rej != nil {
+				return rejectionEnvelope(rej)
+			}
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("scan birth-time window: %w", err)
+		}
+
+		body, err := json.Marshal(WindowEnvelope{
+			Status:    output.StatusSuccess,
+			Metadata:  output.CurrentMetadata(),
+			InputEcho: output.EchoInput(payload),
+			Window:    report,
+		})
+		if err != nil {
+			return nil, 0, fmt.Errorf("marshal window envelope: %w", err)
+		}
+		return body, http.StatusOK, nil
+	}
+}
@@ -0,0 +1,127 @@
+//go:build cgo
+
+package httpservice
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"mademanifest-engine/pkg/ephemeris"
+	"mademanifest-engine/pkg/openapi"
+	"mademanifest-engine/pkg/trinity/output"
+	"mademanifest-engine/pkg/trinity/window"
+)
+
+// TestManifestWindowSchiedam scans the canonical baseline ±30
+// minutes against the live Swiss Ephemeris.  The reference chart
+// must hold the /manifest envelope's values, and a chart computed
+// at every whole minute of the window, and on both sides of every
+// change, must agree with the sub-window it falls in: the bisection
+// missed nothing a minute-by-minute scan would have seen.  The
+// response must match the published OpenAPI schema.
+func TestManifestWindowSchiedam(t *testing.T) {
+	eph := ephemeris.Default()
+	if err := eph.Init(); err != nil {
+		t.Fatal(err)
+	}
+	h := New()
+	h.Ephemeris = eph
+	mux := http.NewServeMux()
+	h.Register(mux)
+
+	rec := postJSON(t, mux, "/manifest/window?window_minutes=30", canonicalBaseline)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	if err := openapi.Validate(readPublishedOpenAPI(t), "WindowEnvelope", rec.Body.Bytes()); err != nil {
+		t.Errorf("response does not match the published schema: %v", err)
+	}
+	var got WindowEnvelope
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	r := got.Window
+
+	body, _, err := NewProcessor(eph)(context.Background(), strings.NewReader(canonicalBaseline))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var env output.SuccessEnvelope
+	if err := json.Unmarshal(body, &env); err != nil {
+		t.Fatal(err)
+	}
+	if got.InputEcho != env.InputEcho || r.Reference["human_design.type"] != env.HumanDesign.Type ||
+		r.Reference["human_design.profile"] != env.HumanDesign.Profile {
+		t.Errorf("reference %v, envelope %+v", r.Reference, env.HumanDesign)
+	}
+	for _, o := range env.Astrology.Objects {
+		if house := r.Reference["astrology."+o.ObjectID+".house"]; house != float64(o.House) {
+			t.Errorf("reference %s house %v, envelope %d", o.ObjectID, house, o.House)
+		}
+	}
+	for _, a := range env.HumanDesign.DesignActivations {
+		if line := r.Reference["human_design.design."+a.ObjectID+".line"]; line != float64(a.Line) {
+			t.Errorf("reference design %s line %v, envelope %d", a.ObjectID, line, a.Line)
+		}
+	}
+	if len(r.SubWindows) < 2 || r.ChartsComputed > 200 {
+		t.Fatalf("%d sub-windows from %d charts", len(r.SubWindows), r.ChartsComputed)
+	}
+
+	chartAt := func(t *testing.T, at time.Time) window.Chart {
+		t.Helper()
+		c, err := window.ChartAt(context.Background(), eph, at, 51.9167, 4.4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Through JSON, as the sub-windows were.
+		raw, _ := json.Marshal(c)
+		var decoded window.Chart
+		json.Unmarshal(raw, &decoded)
+		return decoded
+	}
+	parse := func(s string) time.Time {
+		v, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	spanOf := func(at time.Time) int {
+		for i, w := range r.SubWindows {
+			if !at.Before(parse(w.StartUTC)) && !at.After(parse(w.EndUTC)) {
+				return i
+			}
+		}
+		t.Fatalf("%v outside every sub-window", at)
+		return -1
+	}
+	expect := func(i int) window.Chart {
+		c := window.Chart{}
+		for k, v := range r.Reference {
+			c[k] = v
+		}
+		for k, v := range r.SubWindows[i].Differs {
+			c[k] = v
+		}
+		return c
+	}
+	for at := parse(r.StartUTC); !at.After(parse(r.EndUTC)); at = at.Add(time.Minute) {
+		i := spanOf(at)
+		if c := chartAt(t, at); !reflect.DeepEqual(c, expect(i)) {
+			t.Errorf("%v: chart differs from sub-window %d in %v", at, i, c.Diff(expect(i)))
+		}
+	}
+	for i, w := range r.SubWindows[1:] {
+		start := parse(w.StartUTC)
+		before, after := chartAt(t, start.Add(-time.Second)), chartAt(t, start)
+		if changed := before.Diff(after); !reflect.DeepEqual(changed, w.Changed) {
+			t.Errorf("sub-window %d at %v: changed %v, reported %v", i+1, start, changed, w.Changed)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package httpservice
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mademanifest-engine/pkg/trinity/output"
+)
+
+// TestManifestWindowRejectsBadWindow: a missing, malformed or
+// out-of-range window_minutes is an invalid_input answered before
+// the body is read, and only POST is accepted.
+func TestManifestWindowRejectsBadWindow(t *testing.T) {
+	h := New()
+	h.Ephemeris = goldenReplay(t)
+	mux := http.NewServeMux()
+	h.Register(mux)
+
+	for _, query := range []string{"", "?window_minutes=", "?window_minutes=half", "?window_minutes=0",
+		"?window_minutes=721", "?window_minutes=-5"} {
+		rec := postJSON(t, mux, "/manifest/window"+query, `{"birth_date": "1990-04-09"}`)
+		if rec.Code != http.StatusBadRequest || envelopeType(t, rec.Body.String()) != output.ErrorInvalidInput ||
+			!strings.Contains(rec.Body.String(), WindowParam) {
+			t.Errorf("%q: status %d %s", query, rec.Code, rec.Body)
+		}
+	}
+
+	// A good window hands the payload to the validator.
+	rec := postJSON(t, mux, "/manifest/window?window_minutes=30", `{"birth_date": "1990-04-09"}`)
+	if rec.Code != http.StatusBadRequest || envelopeType(t, rec.Body.String()) != output.ErrorIncompleteInput {
+		t.Errorf("incomplete payload: status %d %s", rec.Code, rec.Body)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/manifest/window?window_minutes=30", nil)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET /manifest/window: status %d, want 405", rec.Code)
+	}
+}
+
+// TestOfflineWindowOptions: Offline.Window is range-checked and
+// excludes a section selector and a debug route.
+func TestOfflineWindowOptions(t *testing.T) {
+	replay := goldenReplay(t)
+	for _, o := range []Offline{
+		{Provider: replay, Window: MaxWindowMinutes + 1},
+		{Provider: replay, Window: -1},
+		{Provider: replay, Window: 30, Sections: "astrology"},
+		{Provider: replay, Window: 30, Explain: "structure"},
+	} {
+		body, status := o.Envelope(context.Background(), strings.NewReader(canonicalBaseline))
+		if status != http.StatusBadRequest || envelopeType(t, string(body)) != output.ErrorInvalidInput {
+			t.Errorf("%+v: status %d %s", o, status, body)
+		}
+	}
+}
@@ -0,0 +1,329 @@
+// Package metrics is a minimal Prometheus instrumentation library:
+// labelled counters, gauges and histograms collected in a Registry
+// and rendered in the Prometheus text exposition format (version
+// 0.0.4).
+//
+// The engine depends on nothing beyond the Swiss Ephemeris bindings,
+// and the handful of series GET /metrics exposes does not justify
+// pulling in the upstream client library and its transitive
+// dependencies.  The output is what any Prometheus server (or the
+// prometheus-adapter feeding the HPA) scrapes; series are emitted in
+// a deterministic order so the exposition is diffable in tests.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the media type of the text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// collector is one metric family: a name, its help text, and a
+// renderer for its samples.
+type collector interface {
+	name() string
+	write(w *bufio.Writer)
+}
+
+// Registry holds metric families in registration order.  The zero
+// value is not usable; call NewRegistry.
+type Registry struct {
+	mu         sync.Mutex
+	collectors []collector
+	names      map[string]bool
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{names: map[string]bool{}}
+}
+
+func (r *Registry) register(c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.names[c.name()] {
+		panic("metrics: duplicate metric name " + c.name())
+	}
+	r.names[c.name()] = true
+	r.collectors = append(r.collectors, c)
+}
+
+// WriteText renders every registered family in the text exposition
+// format.
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	cs := append([]collector(nil), r.collectors...)
+	r.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	for _, c := range cs {
+		c.write(bw)
+	}
+	return bw.Flush()
+}
+
+// Handler serves the registry as a GET-only text exposition.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", ContentType)
+		_ = r.WriteText(w)
+	})
+}
+
+// family is the label bookkeeping shared by every vector type.
+type family struct {
+	fqName     string
+	help       string
+	kind       string
+	labelNames []string
+}
+
+func (f family) name() string { return f.fqName }
+
+func (f family) header(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", f.fqName, escapeHelp(f.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", f.fqName, f.kind)
+}
+
+// key joins label values into a map key.  \xff cannot occur in a
+// valid UTF-8 label value, so distinct tuples never collide.
+func (f family) key(values []string) string {
+	if len(values) != len(f.labelNames) {
+		panic(fmt.Sprintf("metrics: %s wants %d label values, got %d",
+			f.fqName, len(f.labelNames), len(values)))
+	}
+	return strings.Join(values, "\xff")
+}
+
+// labels renders {a="x",b="y"} for the given values plus any extra
+// trailing pair (used for the histogram "le" label).
+func (f family) labels(values []string, extra ...string) string {
+	if len(values) == 0 && len(extra) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, v := range values {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%s=\"%s\"", f.labelNames[i], escapeLabel(v))
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		if b.Len() > 1 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%s=\"%s\"", extra[i], escapeLabel(extra[i+1]))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// CounterVec is a family of monotonically increasing counters.
+type CounterVec struct {
+	family
+	mu     sync.Mutex
+	values map[string]*sample
+}
+
+type sample struct {
+	labels []string
+	value  float64
+}
+
+// NewCounterVec registers a counter family on r.
+func (r *Registry) NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	c := &CounterVec{
+		family: family{fqName: name, help: help, kind: "counter", labelNames: labelNames},
+		values: map[string]*sample{},
+	}
+	r.register(c)
+	return c
+}
+
+// Add increments the counter for the label values by delta, which
+// must not be negative.
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		panic("metrics: counter " + c.fqName + " cannot decrease")
+	}
+	k := c.key(labelValues)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.values[k]
+	if !ok {
+		s = &sample{labels: append([]string(nil), labelValues...)}
+		c.values[k] = s
+	}
+	s.value += delta
+}
+
+// Inc increments the counter for the label values by one.
+func (c *CounterVec) Inc(labelValues ...string) { c.Add(1, labelValues...) }
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	c.header(w)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, s := range sortedSamples(c.values) {
+		fmt.Fprintf(w, "%s%s %s\n", c.fqName, c.labels(s.labels), formatFloat(s.value))
+	}
+}
+
+// GaugeVec is a family of values that can go up and down.
+type GaugeVec struct {
+	family
+	mu     sync.Mutex
+	values map[string]*sample
+}
+
+// NewGaugeVec registers a gauge family on r.
+func (r *Registry) NewGaugeVec(name, help string, labelNames ...string) *GaugeVec {
+	g := &GaugeVec{
+		family: family{fqName: name, help: help, kind: "gauge", labelNames: labelNames},
+		values: map[string]*sample{},
+	}
+	r.register(g)
+	return g
+}
+
+// Add moves the gauge for the label values by delta.
+func (g *GaugeVec) Add(delta float64, labelValues ...string) {
+	k := g.key(labelValues)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	s, ok := g.values[k]
+	if !ok {
+		s = &sample{labels: append([]string(nil), labelValues...)}
+		g.values[k] = s
+	}
+	s.value += delta
+}
+
+func (g *GaugeVec) write(w *bufio.Writer) {
+	g.header(w)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, s := range sortedSamples(g.values) {
+		fmt.Fprintf(w, "%s%s %s\n", g.fqName, g.labels(s.labels), formatFloat(s.value))
+	}
+}
+
+// HistogramVec is a family of cumulative histograms sharing one set
+// of bucket upper bounds.
+type HistogramVec struct {
+	family
+	buckets []float64
+	mu      sync.Mutex
+	values  map[string]*histSample
+}
+
+type histSample struct {
+	labels []string
+	counts []uint64 // per bucket, non-cumulative; last entry is +Inf
+	sum    float64
+	count  uint64
+}
+
+// NewHistogramVec registers a histogram family on r.  buckets are
+// the finite upper bounds in increasing order; +Inf is implicit.
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	if !sort.Float64sAreSorted(buckets) {
+		panic("metrics: histogram " + name + " buckets are not sorted")
+	}
+	h := &HistogramVec{
+		family:  family{fqName: name, help: help, kind: "histogram", labelNames: labelNames},
+		buckets: append([]float64(nil), buckets...),
+		values:  map[string]*histSample{},
+	}
+	r.register(h)
+	return h
+}
+
+// Observe records one value for the label values.
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	k := h.key(labelValues)
+	i := sort.SearchFloat64s(h.buckets, v) // first bound >= v
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.values[k]
+	if !ok {
+		s = &histSample{
+			labels: append([]string(nil), labelValues...),
+			counts: make([]uint64, len(h.buckets)+1),
+		}
+		h.values[k] = s
+	}
+	s.counts[i]++
+	s.sum += v
+	s.count++
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	h.header(w)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	keys := make([]string, 0, len(h.values))
+	for k := range h.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		s := h.values[k]
+		var cum uint64
+		for i, bound := range h.buckets {
+			cum += s.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.fqName,
+				h.labels(s.labels, "le", formatFloat(bound)), cum)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.fqName, h.labels(s.labels, "le", "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.fqName, h.labels(s.labels), formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.fqName, h.labels(s.labels), s.count)
+	}
+}
+
+func sortedSamples(m map[string]*sample) []*sample {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := make([]*sample, len(keys))
+	for i, k := range keys {
+		out[i] = m[k]
+	}
+	return out
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, +1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
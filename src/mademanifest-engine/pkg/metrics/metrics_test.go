@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestWriteTextExposition pins the text exposition of each metric
+// type, including cumulative buckets, label escaping and the
+// deterministic series order.
+func TestWriteTextExposition(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounterVec("t_requests_total", "Requests.", "code")
+	g := r.NewGaugeVec("t_in_flight", "In flight.")
+	h := r.NewHistogramVec("t_seconds", "Latency.", []float64{0.1, 1}, "stage")
+
+	c.Inc("500")
+	c.Add(2, "200")
+	g.Add(1)
+	g.Add(-1)
+	g.Add(1)
+	h.Observe(0.05, `a"b`)
+	h.Observe(0.1, `a"b`)
+	h.Observe(3, `a"b`)
+
+	var b strings.Builder
+	if err := r.WriteText(&b); err != nil {
+		t.Fatalf("WriteText: %v", err)
+	}
+	want := `# HELP t_requests_total Requests.
+# TYPE t_requests_total counter
+t_requests_total{code="200"} 2
+t_requests_total{code="500"} 1
+# HELP t_in_flight In flight.
+# TYPE t_in_flight gauge
+t_in_flight 1
+# HELP t_seconds Latency.
+# TYPE t_seconds histogram
+t_seconds_bucket{stage="a\"b",le="0.1"} 2
+t_seconds_bucket{stage="a\"b",le="1"} 2
+t_seconds_bucket{stage="a\"b",le="+Inf"} 3
+t_seconds_sum{stage="a\"b"} 3.15
+t_seconds_count{stage="a\"b"} 3
+`
+	if got := b.String(); got != want {
+		t.Errorf("exposition mismatch\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// TestMisuseFailsLoudly covers the programming errors the package
+// refuses: duplicate names, a wrong label arity, a negative counter
+// increment.
+func TestMisuseFailsLoudly(t *testing.T) {
+	mustPanic := func(name string, f func()) {
+		t.Helper()
+		defer func() {
+			if recover() == nil {
+				t.Errorf("%s: expected panic", name)
+			}
+		}()
+		f()
+	}
+	r := NewRegistry()
+	c := r.NewCounterVec("x_total", "x", "a")
+	mustPanic("duplicate", func() { r.NewGaugeVec("x_total", "x") })
+	mustPanic("arity", func() { c.Inc() })
+	mustPanic("negative", func() { c.Add(-1, "v") })
+}
+
+func TestHandlerServesTextFormat(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounterVec("y_total", "y").Inc()
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != ContentType {
+		t.Fatalf("status %d, Content-Type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(rec.Body.String(), "y_total 1\n") {
+		t.Errorf("body = %q", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST status = %d, want 405", rec.Code)
+	}
+}
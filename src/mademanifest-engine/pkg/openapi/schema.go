@@ -0,0 +1,253 @@
+// Package openapi derives OpenAPI 3.1 schema components from the Go
+// types that define the engine's wire contract, and checks JSON
+// documents against them.
+//
+// Schemas are built by reflection over the encoding/json view of a
+// type – field names and omitempty come from the struct tags – so a
+// renamed tag or a new field changes the generated document.  What
+// reflection cannot see (regex formats, numeric ranges, enumerations
+// of canon identifiers, the wire form of types with a custom
+// MarshalJSON) is supplied by the caller with Override, Annotate and
+// Replace, from the same Go values the engine computes with.
+//
+// The package is dependency-free and knows nothing about the
+// engine's routes; pkg/httpservice assembles the document.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Schema is one JSON Schema (draft 2020-12, the dialect of OpenAPI
+// 3.1) object.  encoding/json writes map keys sorted, so a Schema
+// always marshals to the same bytes.
+type Schema = map[string]any
+
+// RefPrefix is where component schemas live in the document.
+const RefPrefix = "#/components/schemas/"
+
+// Components collects the component schemas reachable from the
+// types passed to Ref.  Annotations must be registered before the
+// first Ref that reaches their type.
+type Components struct {
+	schemas   map[string]Schema
+	names     map[reflect.Type]string
+	overrides map[reflect.Type]Schema
+	fields    map[reflect.Type]map[string]fieldRule
+	errs      []string
+}
+
+// fieldRule adjusts the schema of one struct field.  replace
+// discards the reflected schema; used records that the rule matched
+// a JSON field, so a stale rule is reported by Schemas.
+type fieldRule struct {
+	schema  Schema
+	replace bool
+	used    bool
+}
+
+// NewComponents returns an empty component set.
+func NewComponents() *Components {
+	return &Components{
+		schemas:   map[string]Schema{},
+		names:     map[reflect.Type]string{},
+		overrides: map[reflect.Type]Schema{},
+		fields:    map[reflect.Type]map[string]fieldRule{},
+	}
+}
+
+// Override makes every occurrence of v's type render as s.  Types
+// implementing json.Marshaler must be overridden: their wire form is
+// not visible to reflection.
+func (c *Components) Override(v any, s Schema) {
+	c.overrides[reflect.TypeOf(v)] = s
+}
+
+// Annotate merges keywords (enum, pattern, minimum, description…)
+// into the reflected schema of the struct field whose JSON name is
+// field.
+func (c *Components) Annotate(v any, field string, keywords Schema) {
+	c.rule(v, field, fieldRule{schema: keywords})
+}
+
+// Replace substitutes s for the reflected schema of a struct field,
+// e.g. a json.RawMessage that always carries a known document.
+func (c *Components) Replace(v any, field string, s Schema) {
+	c.rule(v, field, fieldRule{schema: s, replace: true})
+}
+
+func (c *Components) rule(v any, field string, r fieldRule) {
+	t := reflect.TypeOf(v)
+	if c.fields[t] == nil {
+		c.fields[t] = map[string]fieldRule{}
+	}
+	c.fields[t][field] = r
+}
+
+// Ref registers v's struct type as a component schema and returns a
+// reference to it.  The component is named after the Go type.
+func (c *Components) Ref(v any) Schema {
+	return c.schemaFor(reflect.TypeOf(v))
+}
+
+// Schemas returns the collected component schemas, or an error
+// listing every type that could not be described and every
+// annotation that matched no JSON field.
+func (c *Components) Schemas() (map[string]Schema, error) {
+	errs := append([]string(nil), c.errs...)
+	for t, rules := range c.fields {
+		for name, r := range rules {
+			if !r.used {
+				errs = append(errs, fmt.Sprintf("%s: annotated field %q is not a JSON field", t, name))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return nil, fmt.Errorf("openapi: %s", strings.Join(errs, "; "))
+	}
+	return c.schemas, nil
+}
+
+var marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+func (c *Components) schemaFor(t reflect.Type) Schema {
+	if s, ok := c.overrides[t]; ok {
+		return s
+	}
+	if t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType) {
+		c.errs = append(c.errs, fmt.Sprintf("%s implements json.Marshaler; Override it", t))
+		return Schema{}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return c.schemaFor(t.Elem())
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.Slice:
+		return Schema{"type": "array", "items": c.schemaFor(t.Elem())}
+	case reflect.Array:
+		return Schema{"type": "array", "items": c.schemaFor(t.Elem()),
+			"minItems": t.Len(), "maxItems": t.Len()}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": c.schemaFor(t.Elem())}
+	case reflect.Interface:
+		return Schema{}
+	case reflect.Struct:
+		return c.structRef(t)
+	}
+	c.errs = append(c.errs, fmt.Sprintf("%s: unsupported kind %s", t, t.Kind()))
+	return Schema{}
+}
+
+// structRef registers t under its Go name and returns a $ref.  The
+// name is reserved before the fields are walked so recursive types
+// terminate.
+func (c *Components) structRef(t reflect.Type) Schema {
+	name, ok := c.names[t]
+	if !ok {
+		name = t.Name()
+		if name == "" {
+			c.errs = append(c.errs, fmt.Sprintf("anonymous struct %s cannot be a component", t))
+			return Schema{}
+		}
+		if _, taken := c.schemas[name]; taken {
+			c.errs = append(c.errs, fmt.Sprintf("component %s is defined by two Go types (%s)", name, t))
+			return Schema{}
+		}
+		c.names[t] = name
+		c.schemas[name] = Schema{}
+		props, required := Schema{}, []string{}
+		c.collectFields(t, t, props, &required)
+		s := Schema{"type": "object", "properties": props, "additionalProperties": false}
+		if len(required) > 0 {
+			s["required"] = required
+		}
+		c.schemas[name] = s
+	}
+	return Schema{"$ref": RefPrefix + name}
+}
+
+// collectFields walks t's fields as encoding/json sees them:
+// unexported and "-" fields are skipped, embedded structs without a
+// tag are flattened, and a field is required unless it is
+// omitempty.  Field rules are looked up on owner, the type being
+// registered, so an embedded struct's fields are annotated on the
+// outer type.
+func (c *Components) collectFields(owner, t reflect.Type, props Schema, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			c.collectFields(owner, f.Type, props, required)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		var s Schema
+		rule, ruled := c.fields[owner][name]
+		if ruled && rule.replace {
+			s = rule.schema
+		} else {
+			s = c.schemaFor(f.Type)
+			if ruled {
+				s = merge(s, rule.schema)
+			}
+		}
+		if ruled {
+			rule.used = true
+			c.fields[owner][name] = rule
+		}
+		props[name] = s
+		if !strings.Contains(","+opts+",", ",omitempty,") {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// merge returns base with keywords added.  A $ref cannot carry
+// sibling constraints usefully, so an annotated reference is
+// wrapped in allOf.
+func merge(base, keywords Schema) Schema {
+	out := Schema{}
+	if _, isRef := base["$ref"]; isRef {
+		out["allOf"] = []any{base}
+	} else {
+		for k, v := range base {
+			out[k] = v
+		}
+	}
+	for k, v := range keywords {
+		out[k] = v
+	}
+	return out
+}
+
+// Enum converts a list of Go values (typically a canon order array
+// or a slice of typed string constants) into an enum keyword value.
+func Enum[T any](values ...T) []any {
+	out := make([]any, len(values))
+	for i, v := range values {
+		out[i] = v
+	}
+	return out
+}
@@ -0,0 +1,117 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type inner struct {
+	Code string `json:"code"`
+}
+
+type base struct {
+	ID string `json:"id"`
+}
+
+type sample struct {
+	base
+	Name     string          `json:"name"`
+	Note     string          `json:"note,omitempty"`
+	Inner    *inner          `json:"inner,omitempty"`
+	Pair     [2]float64      `json:"pair"`
+	Tags     []string        `json:"tags"`
+	Extra    map[string]int  `json:"extra,omitempty"`
+	Raw      json.RawMessage `json:"raw,omitempty"`
+	Skipped  string          `json:"-"`
+	hidden   string
+	Untagged bool
+}
+
+type stamp struct{}
+
+func (stamp) MarshalJSON() ([]byte, error) { return []byte(`"x"`), nil }
+
+type withStamp struct {
+	At stamp `json:"at"`
+}
+
+// TestComponentsReflectJSONView checks field names, required-ness,
+// flattening and container shapes follow encoding/json.
+func TestComponentsReflectJSONView(t *testing.T) {
+	c := NewComponents()
+	c.Annotate(sample{}, "name", Schema{"pattern": "^[a-z]+$"})
+	c.Annotate(sample{}, "inner", Schema{"description": "nested"})
+	c.Replace(sample{}, "raw", Schema{"type": "object"})
+	if ref := c.Ref(sample{}); ref["$ref"] != RefPrefix+"sample" {
+		t.Fatalf("Ref = %v", ref)
+	}
+	schemas, err := c.Schemas()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := schemas["sample"]
+	props := s["properties"].(Schema)
+	var names []string
+	for k := range props {
+		names = append(names, k)
+	}
+	for _, want := range []string{"id", "name", "note", "inner", "pair", "tags", "extra", "raw", "Untagged"} {
+		if _, ok := props[want]; !ok {
+			t.Errorf("property %q missing (have %v)", want, names)
+		}
+	}
+	for _, gone := range []string{"Skipped", "-", "hidden", "base"} {
+		if _, ok := props[gone]; ok {
+			t.Errorf("property %q present; want it skipped", gone)
+		}
+	}
+	if want := []string{"id", "name", "pair", "tags", "Untagged"}; !reflect.DeepEqual(s["required"], want) {
+		t.Errorf("required = %v, want %v", s["required"], want)
+	}
+	if s["additionalProperties"] != false {
+		t.Error("object schema admits additional properties")
+	}
+	if got := props["name"].(Schema); got["type"] != "string" || got["pattern"] != "^[a-z]+$" {
+		t.Errorf("annotated name = %v", got)
+	}
+	inner := props["inner"].(Schema)
+	if all, ok := inner["allOf"].([]any); !ok || all[0].(Schema)["$ref"] != RefPrefix+"inner" ||
+		inner["description"] != "nested" {
+		t.Errorf("annotated reference = %v, want allOf-wrapped $ref", inner)
+	}
+	if _, ok := schemas["inner"]; !ok {
+		t.Error("nested struct not registered as a component")
+	}
+	if pair := props["pair"].(Schema); pair["minItems"] != 2 || pair["maxItems"] != 2 {
+		t.Errorf("array schema = %v, want fixed length 2", pair)
+	}
+	if got := props["raw"].(Schema); !reflect.DeepEqual(got, Schema{"type": "object"}) {
+		t.Errorf("replaced raw = %v", got)
+	}
+}
+
+// TestComponentsReportUndescribableTypes: a custom marshaller needs
+// an Override, and an annotation naming no JSON field is stale.
+func TestComponentsReportUndescribableTypes(t *testing.T) {
+	c := NewComponents()
+	c.Ref(withStamp{})
+	if _, err := c.Schemas(); err == nil || !strings.Contains(err.Error(), "json.Marshaler") {
+		t.Errorf("Schemas error = %v, want a json.Marshaler complaint", err)
+	}
+
+	c = NewComponents()
+	c.Override(stamp{}, Schema{"type": "string"})
+	c.Ref(withStamp{})
+	if _, err := c.Schemas(); err != nil {
+		t.Errorf("overridden marshaller: %v", err)
+	}
+
+	c = NewComponents()
+	c.Annotate(inner{}, "renamed", Schema{"enum": Enum("a")})
+	c.Ref(inner{})
+	if _, err := c.Schemas(); err == nil || !strings.Contains(err.Error(), `"renamed"`) {
+		t.Errorf("Schemas error = %v, want the stale annotation named", err)
+	}
+}
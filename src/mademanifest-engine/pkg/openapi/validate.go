@@ -0,0 +1,209 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Validate checks instance (a JSON document) against the component
+// schema name of doc (an OpenAPI document as JSON).  It implements
+// the JSON Schema subset this package generates – $ref, allOf,
+// oneOf, type, const, enum, properties, required,
+// additionalProperties, items, minItems, maxItems, pattern, minimum,
+// maximum, exclusiveMaximum – which is enough for a contract test
+// to hold real engine output against the published document.  The
+// error names the first failing location as a JSON pointer.
+func Validate(doc []byte, name string, instance []byte) error {
+	var d struct {
+		Components struct {
+			Schemas map[string]any `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(doc, &d); err != nil {
+		return fmt.Errorf("decode OpenAPI document: %w", err)
+	}
+	var v any
+	if err := json.Unmarshal(instance, &v); err != nil {
+		return fmt.Errorf("decode instance: %w", err)
+	}
+	val := validator{schemas: d.Components.Schemas}
+	return val.check(map[string]any{"$ref": RefPrefix + name}, v, "")
+}
+
+type validator struct {
+	schemas map[string]any
+}
+
+func (val validator) check(schema, v any, at string) error {
+	s, ok := schema.(map[string]any)
+	if !ok {
+		return fmt.Errorf("%s: schema is not an object", pointer(at))
+	}
+	if ref, ok := s["$ref"].(string); ok {
+		target, found := val.schemas[strings.TrimPrefix(ref, RefPrefix)]
+		if !strings.HasPrefix(ref, RefPrefix) || !found {
+			return fmt.Errorf("%s: unresolvable $ref %q", pointer(at), ref)
+		}
+		if err := val.check(target, v, at); err != nil {
+			return err
+		}
+	}
+	if all, ok := s["allOf"].([]any); ok {
+		for _, sub := range all {
+			if err := val.check(sub, v, at); err != nil {
+				return err
+			}
+		}
+	}
+	if one, ok := s["oneOf"].([]any); ok {
+		matched := 0
+		var errs []string
+		for _, sub := range one {
+			if err := val.check(sub, v, at); err != nil {
+				errs = append(errs, err.Error())
+			} else {
+				matched++
+			}
+		}
+		if matched != 1 {
+			return fmt.Errorf("%s: matches %d oneOf alternatives, want 1 (%s)",
+				pointer(at), matched, strings.Join(errs, "; "))
+		}
+	}
+	if t, ok := s["type"].(string); ok && !hasType(v, t) {
+		return fmt.Errorf("%s: %s is not of type %s", pointer(at), describe(v), t)
+	}
+	if c, ok := s["const"]; ok && !equal(c, v) {
+		return fmt.Errorf("%s: %s is not the constant %v", pointer(at), describe(v), c)
+	}
+	if e, ok := s["enum"].([]any); ok {
+		found := false
+		for _, want := range e {
+			found = found || equal(want, v)
+		}
+		if !found {
+			return fmt.Errorf("%s: %s is not one of %v", pointer(at), describe(v), e)
+		}
+	}
+	switch x := v.(type) {
+	case string:
+		if p, ok := s["pattern"].(string); ok {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				return fmt.Errorf("%s: bad pattern %q: %w", pointer(at), p, err)
+			}
+			if !re.MatchString(x) {
+				return fmt.Errorf("%s: %q does not match %s", pointer(at), x, p)
+			}
+		}
+	case float64:
+		if m, ok := s["minimum"].(float64); ok && x < m {
+			return fmt.Errorf("%s: %v is below the minimum %v", pointer(at), x, m)
+		}
+		if m, ok := s["maximum"].(float64); ok && x > m {
+			return fmt.Errorf("%s: %v is above the maximum %v", pointer(at), x, m)
+		}
+		if m, ok := s["exclusiveMaximum"].(float64); ok && x >= m {
+			return fmt.Errorf("%s: %v is not below %v", pointer(at), x, m)
+		}
+	case []any:
+		if m, ok := s["minItems"].(float64); ok && float64(len(x)) < m {
+			return fmt.Errorf("%s: %d items, want at least %v", pointer(at), len(x), m)
+		}
+		if m, ok := s["maxItems"].(float64); ok && float64(len(x)) > m {
+			return fmt.Errorf("%s: %d items, want at most %v", pointer(at), len(x), m)
+		}
+		if items, ok := s["items"]; ok {
+			for i, elem := range x {
+				if err := val.check(items, elem, fmt.Sprintf("%s/%d", at, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case map[string]any:
+		if req, ok := s["required"].([]any); ok {
+			for _, name := range req {
+				if _, present := x[name.(string)]; !present {
+					return fmt.Errorf("%s: required property %q is missing", pointer(at), name)
+				}
+			}
+		}
+		props, _ := s["properties"].(map[string]any)
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			path := at + "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(k)
+			if ps, ok := props[k]; ok {
+				if err := val.check(ps, x[k], path); err != nil {
+					return err
+				}
+				continue
+			}
+			switch extra := s["additionalProperties"].(type) {
+			case bool:
+				if !extra {
+					return fmt.Errorf("%s: property %q is not allowed", pointer(at), k)
+				}
+			case map[string]any:
+				if err := val.check(extra, x[k], path); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func hasType(v any, t string) bool {
+	switch t {
+	case "object":
+		_, ok := v.(map[string]any)
+		return ok
+	case "array":
+		_, ok := v.([]any)
+		return ok
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	case "integer":
+		f, ok := v.(float64)
+		return ok && f == math.Trunc(f)
+	case "null":
+		return v == nil
+	}
+	return false
+}
+
+func equal(a, b any) bool {
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	return string(ja) == string(jb)
+}
+
+func describe(v any) string {
+	raw, _ := json.Marshal(v)
+	if len(raw) > 60 {
+		return string(raw[:57]) + "..."
+	}
+	return string(raw)
+}
+
+func pointer(at string) string {
+	if at == "" {
+		return "/"
+	}
+	return at
+}
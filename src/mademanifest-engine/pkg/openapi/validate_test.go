@@ -0,0 +1,65 @@
+package openapi
+
+import (
+	"strings"
+	"testing"
+)
+
+// testDocument exercises every keyword Validate implements.
+const testDocument = `{
+  "components": {"schemas": {
+    "Thing": {
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["kind", "count"],
+      "properties": {
+        "kind": {"enum": ["a", "b"]},
+        "count": {"type": "integer", "minimum": 1, "maximum": 3},
+        "angle": {"type": "number", "minimum": 0, "exclusiveMaximum": 360},
+        "code": {"type": "string", "pattern": "^[A-Z]{2}$"},
+        "fixed": {"const": "v1"},
+        "pair": {"type": "array", "items": {"type": "integer"}, "minItems": 2, "maxItems": 2},
+        "child": {"allOf": [{"$ref": "#/components/schemas/Child"}]},
+        "either": {"oneOf": [{"type": "string"}, {"type": "integer"}]},
+        "labels": {"type": "object", "additionalProperties": {"type": "string"}}
+      }
+    },
+    "Child": {"type": "object", "properties": {"ok": {"type": "boolean"}}, "required": ["ok"]}
+  }}
+}`
+
+func TestValidateAcceptsConformingInstance(t *testing.T) {
+	instance := `{"kind":"a","count":2,"angle":359.5,"code":"NL","fixed":"v1",
+		"pair":[1,2],"child":{"ok":true},"either":7,"labels":{"x":"y"}}`
+	if err := Validate([]byte(testDocument), "Thing", []byte(instance)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateRejectsViolations(t *testing.T) {
+	for _, c := range []struct {
+		name, instance, want string
+	}{
+		{"missing required", `{"kind":"a"}`, `required property "count"`},
+		{"unknown property", `{"kind":"a","count":1,"colour":"red"}`, `"colour" is not allowed`},
+		{"enum", `{"kind":"c","count":1}`, "/kind"},
+		{"integer", `{"kind":"a","count":1.5}`, "not of type integer"},
+		{"maximum", `{"kind":"a","count":4}`, "above the maximum"},
+		{"exclusive maximum", `{"kind":"a","count":1,"angle":360}`, "not below 360"},
+		{"pattern", `{"kind":"a","count":1,"code":"nl"}`, "does not match"},
+		{"const", `{"kind":"a","count":1,"fixed":"v2"}`, "not the constant"},
+		{"min items", `{"kind":"a","count":1,"pair":[1]}`, "at least 2"},
+		{"item type", `{"kind":"a","count":1,"pair":[1,"x"]}`, "/pair/1"},
+		{"nested ref", `{"kind":"a","count":1,"child":{}}`, `/child: required property "ok"`},
+		{"oneOf", `{"kind":"a","count":1,"either":true}`, "matches 0 oneOf"},
+		{"additional schema", `{"kind":"a","count":1,"labels":{"x":1}}`, "/labels/x"},
+	} {
+		err := Validate([]byte(testDocument), "Thing", []byte(c.instance))
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%s: error = %v, want it to mention %q", c.name, err, c.want)
+		}
+	}
+	if err := Validate([]byte(testDocument), "Missing", []byte(`{}`)); err == nil {
+		t.Error("unknown component validated")
+	}
+}
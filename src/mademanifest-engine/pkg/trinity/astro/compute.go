@@ -5,17 +5,17 @@ import (
 	"math"
 	"time"
 
-	"github.com/mshafiee/swephgo"
 	"mademanifest-engine/pkg/astronomy"
 	"mademanifest-engine/pkg/canon"
 	"mademanifest-engine/pkg/ephemeris"
-	"mademanifest-engine/pkg/sweph"
 	"mademanifest-engine/pkg/trinity/input"
 	"mademanifest-engine/pkg/trinity/output"
 )
 
 // ComputeAstrology builds the complete astrology section of a
-// Trinity success envelope from a validated input payload.
+// Trinity success envelope from a validated input payload, with
+// every body longitude and the house cusps read from eph
+// (ephemeris.Default() in production).
 //
 // Pinned canon (Document 03):
 //   * zodiac       = tropical
@@ -33,26 +33,34 @@ import (
 // is implemented by HouseFor.  All longitudes are normalised to
 // [0, 360) before sign and house lookup so SignFor and HouseFor
 // never see boundary inputs they would reject.
-func ComputeAstrology(p input.Payload) (output.Astrology, error) {
+func ComputeAstrology(eph ephemeris.Provider, p input.Payload) (output.Astrology, error) {
 	utcTime, err := localToUTC(p)
 	if err != nil {
 		return output.Astrology{}, fmt.Errorf("convert birth time: %w", err)
 	}
-	jd := astronomy.ConvertUTCToJulianDay(utcTime)
+	return ComputeAstrologyAt(eph, astronomy.ConvertUTCToJulianDay(utcTime), p.Latitude, p.Longitude)
+}
 
-	rawLongs := ephemeris.CalculatePositions(jd) // also initialises sweph
+// ComputeAstrologyAt is ComputeAstrology for a birth at Julian Day
+// jd (UT) and the given place, for callers that move the birth
+// moment off the payload's minute (the birth-time window).
+func ComputeAstrologyAt(eph ephemeris.Provider, jd, latitude, longitude float64) (output.Astrology, error) {
+	rawLongs, err := ephemeris.Positions(eph, jd)
+	if err != nil {
+		return output.Astrology{}, fmt.Errorf("compute positions: %w", err)
+	}
 	sunLong := normalizeDeg(rawLongs["sun"])
 	rawLongs["earth"] = normalizeDeg(sunLong + 180.0) // override SE_EARTH
 
-	cusps := make([]float64, 13) // indices 1..12 used; 0 unused
-	ascmc := make([]float64, 10)
-	const placidus = int('P')
-	swephgo.HousesEx(jd, sweph.SEFLG_SWIEPH|sweph.SEFLG_NONUT,
-		p.Latitude, p.Longitude, placidus, cusps, ascmc)
+	cusps, rawAsc, rawMC, err := eph.Houses(jd,
+		latitude, longitude, placidus)
+	if err != nil {
+		return output.Astrology{}, fmt.Errorf("compute houses: %w", err)
+	}
 
 	var cuspArr [12]float64
 	for i := 0; i < 12; i++ {
-		cuspArr[i] = normalizeDeg(cusps[i+1])
+		cuspArr[i] = normalizeDeg(cusps[i])
 	}
 
 	objects := make([]output.AstroObject, 0, len(canon.AstrologyObjectOrder))
@@ -66,17 +74,8 @@ func ComputeAstrology(p input.Payload) (output.Astrology, error) {
 		})
 	}
 
-	cuspsOut := make([]output.HouseCusp, 12)
-	for i := 0; i < 12; i++ {
-		cuspsOut[i] = output.HouseCusp{
-			House:     i + 1,
-			Longitude: output.Longitude(cuspArr[i]),
-			Sign:      SignFor(cuspArr[i]),
-		}
-	}
-
-	asc := normalizeDeg(ascmc[0])
-	mc := normalizeDeg(ascmc[1])
+	asc := normalizeDeg(rawAsc)
+	mc := normalizeDeg(rawMC)
 
 	return output.Astrology{
 		System: output.AstroSystem{
@@ -94,31 +93,22 @@ func ComputeAstrology(p input.Payload) (output.Astrology, error) {
 				Sign:      SignFor(mc),
 			},
 		},
-		HouseCusps: cuspsOut,
+		HouseCusps: CuspTable(cuspArr),
 		Objects:    objects,
 	}, nil
 }
 
-// localToUTC parses the validated string forms of birth_date and
-// birth_time, attaches the validated timezone, and converts to UTC.
-// The validator has already proved the string format and zone are
-// canonical, so the parse failures here would indicate a bug
-// upstream – they are wrapped as descriptive errors anyway.
+// localToUTC resolves the validated birth_date / birth_time in the
+// validated timezone via input.LocalToUTC, the same DST-aware
+// conversion the validator ran.  The validator has already accepted
+// the payload, so a rejection here would indicate a bug upstream –
+// it is returned as an ordinary error.
 func localToUTC(p input.Payload) (time.Time, error) {
-	loc, err := time.LoadLocation(p.Timezone)
-	if err != nil {
-		return time.Time{}, fmt.Errorf("load timezone %q: %w", p.Timezone, err)
-	}
-	var year, month, day int
-	if _, err := fmt.Sscanf(p.BirthDate, "%d-%d-%d", &year, &month, &day); err != nil {
-		return time.Time{}, fmt.Errorf("parse birth_date %q: %w", p.BirthDate, err)
-	}
-	var hour, minute int
-	if _, err := fmt.Sscanf(p.BirthTime, "%d:%d", &hour, &minute); err != nil {
-		return time.Time{}, fmt.Errorf("parse birth_time %q: %w", p.BirthTime, err)
+	t, rej := input.LocalToUTC(p)
+	if rej != nil {
+		return time.Time{}, rej
 	}
-	local := time.Date(year, time.Month(month), day, hour, minute, 0, 0, loc)
-	return local.UTC(), nil
+	return t, nil
 }
 
 // normalizeDeg folds an arbitrary angular value into [0, 360),
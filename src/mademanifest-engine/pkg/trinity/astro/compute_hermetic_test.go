@@ -0,0 +1,94 @@
+package astro
+
+import (
+	"strings"
+	"testing"
+
+	"mademanifest-engine/pkg/ephemeris/ephemeristest"
+	"mademanifest-engine/pkg/trinity/input"
+)
+
+// j2000 is 2000-01-01 12:00 UT, Julian Day 2451545.0 exactly.
+var j2000 = input.Payload{
+	BirthDate: "2000-01-01",
+	BirthTime: "12:00",
+	Timezone:  "Europe/London",
+	Latitude:  52,
+	Longitude: 4,
+}
+
+const j2000JD = 2451545.0
+
+// j2000Fake tabulates every body at j2000JD and Placidus cusps 30°
+// apart starting at 15° (house n runs from 30n-15 to 30n+15).
+func j2000Fake() *ephemeristest.Fake {
+	f := &ephemeristest.Fake{}
+	for body, long := range map[string]float64{
+		"sun": 280.5, "moon": 14.9, "mercury": 15, "venus": 359.99,
+		"mars": 327, "jupiter": 25, "saturn": 40, "uranus": 314,
+		"neptune": 303, "pluto": 251, "chiron": 251.5,
+		"north_node_mean": 125, "north_node": 125, "north_node_true": 126.5,
+		"earth": 7, // heliocentric SE_EARTH; must be ignored
+	} {
+		f.AddLongitude(body, j2000JD, long)
+	}
+	h := ephemeristest.HouseSample{JD: j2000JD, Latitude: 52, Longitude: 4, HSys: 'P',
+		Ascendant: 15, Midheaven: 285}
+	for i := range h.Cusps {
+		h.Cusps[i] = float64(15 + 30*i)
+	}
+	f.AddHouses(h)
+	return f
+}
+
+// TestComputeAstrologyAgainstFake runs the astrology section on a
+// table-backed ephemeris, so every sign, house and derived value is
+// known exactly and the test needs neither cgo nor .se1 files.
+func TestComputeAstrologyAgainstFake(t *testing.T) {
+	got, err := ComputeAstrology(j2000Fake(), j2000)
+	if err != nil {
+		t.Fatalf("ComputeAstrology: %v", err)
+	}
+	want := map[string]struct {
+		long  float64
+		sign  string
+		house int
+	}{
+		"sun":             {280.5, "capricorn", 9},
+		"moon":            {14.9, "aries", 12},
+		"mercury":         {15, "aries", 1},
+		"venus":           {359.99, "pisces", 12},
+		"north_node_mean": {125, "leo", 4},
+		"earth":           {100.5, "cancer", 3},
+	}
+	for _, obj := range got.Objects {
+		w, ok := want[obj.ObjectID]
+		if !ok {
+			continue
+		}
+		if float64(obj.Longitude) != w.long || obj.Sign != w.sign || obj.House != w.house {
+			t.Errorf("%s = %v %s house %d, want %v %s house %d",
+				obj.ObjectID, float64(obj.Longitude), obj.Sign, obj.House, w.long, w.sign, w.house)
+		}
+	}
+	if got.HouseCusps[9].Longitude != 285 || got.HouseCusps[9].Sign != "capricorn" {
+		t.Errorf("cusp 10 = %+v, want 285 capricorn", got.HouseCusps[9])
+	}
+	if got.Angles.Ascendant.Sign != "aries" || got.Angles.Midheaven.Sign != "capricorn" {
+		t.Errorf("angles = %+v", got.Angles)
+	}
+}
+
+// TestComputeAstrologyReportsProviderFailure: a provider failure is
+// returned wrapped with the stage that hit it.
+func TestComputeAstrologyReportsProviderFailure(t *testing.T) {
+	f := j2000Fake()
+	f.HouseTable = nil
+	if _, err := ComputeAstrology(f, j2000); err == nil || !strings.Contains(err.Error(), "compute houses") {
+		t.Errorf("err = %v, want a compute houses failure", err)
+	}
+	delete(f.Bodies, "chiron")
+	if _, err := ComputeAstrology(f, j2000); err == nil || !strings.Contains(err.Error(), "compute positions") {
+		t.Errorf("err = %v, want a compute positions failure", err)
+	}
+}
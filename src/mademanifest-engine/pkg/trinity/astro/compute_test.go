@@ -1,3 +1,5 @@
+//go:build cgo
+
 package astro
 
 import (
@@ -5,13 +7,17 @@ import (
 	"testing"
 
 	"mademanifest-engine/pkg/canon"
+	"mademanifest-engine/pkg/ephemeris"
 	"mademanifest-engine/pkg/trinity/input"
 )
 
 // schiedamBaseline mirrors the canonical Schiedam payload used as
 // the Phase 4 oracle.  The struct is constructed in code rather
 // than parsed from JSON so the test does not depend on the
-// validator package.
+// validator package.  The tests in this file compute against the
+// real Swiss Ephemeris, hence the cgo constraint;
+// compute_hermetic_test.go covers ComputeAstrology through
+// ephemeristest.Fake.
 var schiedamBaseline = input.Payload{
 	BirthDate: "1990-04-09",
 	BirthTime: "18:04",
@@ -23,7 +29,7 @@ var schiedamBaseline = input.Payload{
 // TestComputeAstrologySystemBlock pins the three canonical scalars
 // that ComputeAstrology must always emit regardless of inputs.
 func TestComputeAstrologySystemBlock(t *testing.T) {
-	got, err := ComputeAstrology(schiedamBaseline)
+	got, err := ComputeAstrology(ephemeris.Default(), schiedamBaseline)
 	if err != nil {
 		t.Fatalf("ComputeAstrology: %v", err)
 	}
@@ -42,7 +48,7 @@ func TestComputeAstrologySystemBlock(t *testing.T) {
 // length and ordering to canon.AstrologyObjectOrder.  Any drift
 // here would silently shift the contract.
 func TestComputeAstrologyObjectsAreCanonOrdered(t *testing.T) {
-	got, err := ComputeAstrology(schiedamBaseline)
+	got, err := ComputeAstrology(ephemeris.Default(), schiedamBaseline)
 	if err != nil {
 		t.Fatalf("ComputeAstrology: %v", err)
 	}
@@ -60,7 +66,7 @@ func TestComputeAstrologyObjectsAreCanonOrdered(t *testing.T) {
 // TestComputeAstrologyEarthIsSunPlus180 verifies the canon's Earth
 // derivation – not Swiss Ephemeris's heliocentric SE_EARTH.
 func TestComputeAstrologyEarthIsSunPlus180(t *testing.T) {
-	got, err := ComputeAstrology(schiedamBaseline)
+	got, err := ComputeAstrology(ephemeris.Default(), schiedamBaseline)
 	if err != nil {
 		t.Fatalf("ComputeAstrology: %v", err)
 	}
@@ -85,7 +91,7 @@ func TestComputeAstrologyEarthIsSunPlus180(t *testing.T) {
 // non-zero longitudes (the Schiedam payload is far from any
 // degenerate latitude).
 func TestComputeAstrologyHouseCuspsAreOrdered1To12(t *testing.T) {
-	got, err := ComputeAstrology(schiedamBaseline)
+	got, err := ComputeAstrology(ephemeris.Default(), schiedamBaseline)
 	if err != nil {
 		t.Fatalf("ComputeAstrology: %v", err)
 	}
@@ -107,7 +113,7 @@ func TestComputeAstrologyHouseCuspsAreOrdered1To12(t *testing.T) {
 // receives a canonical lowercase sign id.  This catches longitude-
 // normalisation bugs (e.g. emitting 360.0 by mistake).
 func TestComputeAstrologyObjectsHaveNonEmptySigns(t *testing.T) {
-	got, err := ComputeAstrology(schiedamBaseline)
+	got, err := ComputeAstrology(ephemeris.Default(), schiedamBaseline)
 	if err != nil {
 		t.Fatalf("ComputeAstrology: %v", err)
 	}
@@ -132,7 +138,7 @@ func TestComputeAstrologyObjectsHaveNonEmptySigns(t *testing.T) {
 // house is in [1, 12] (the HouseFor sentinel-zero return would
 // indicate a bug).
 func TestComputeAstrologyObjectsHaveValidHouses(t *testing.T) {
-	got, err := ComputeAstrology(schiedamBaseline)
+	got, err := ComputeAstrology(ephemeris.Default(), schiedamBaseline)
 	if err != nil {
 		t.Fatalf("ComputeAstrology: %v", err)
 	}
@@ -148,7 +154,7 @@ func TestComputeAstrologyObjectsHaveValidHouses(t *testing.T) {
 // and midheaven shape: both must have non-zero longitudes (Schiedam
 // is far from the poles) and a canonical sign.
 func TestComputeAstrologyAnglesArePopulated(t *testing.T) {
-	got, err := ComputeAstrology(schiedamBaseline)
+	got, err := ComputeAstrology(ephemeris.Default(), schiedamBaseline)
 	if err != nil {
 		t.Fatalf("ComputeAstrology: %v", err)
 	}
@@ -1,5 +1,7 @@
 package astro
 
+import "math"
+
 // HouseFor returns the canonical house number (1..12) for a
 // normalised ecliptic longitude given the 12 house cusps in
 // canonical order: cusps[0] is the cusp of house 1, cusps[1] is
@@ -43,3 +45,17 @@ func HouseFor(longitude float64, cusps [12]float64) int {
 	}
 	return 0
 }
+
+// HouseMargin returns how far a normalised longitude lies from the
+// nearest of the 12 cusps, in degrees along the circle: the smallest
+// shift of the longitude, in either direction, that would change
+// HouseFor.  The nearest cusp is always one of the two bounding the
+// longitude's house.
+func HouseMargin(longitude float64, cusps [12]float64) float64 {
+	margin := 180.0
+	for _, c := range cusps {
+		d := normalizeDeg(longitude - c)
+		margin = math.Min(margin, math.Min(d, 360-d))
+	}
+	return margin
+}
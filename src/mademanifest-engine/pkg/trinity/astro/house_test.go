@@ -1,6 +1,9 @@
 package astro
 
-import "testing"
+import (
+	"math"
+	"testing"
+)
 
 // TestHouseForUniformCusps walks evenly-spaced cusps so each house
 // is exactly 30° wide.  The boundary cases pin the canonical
@@ -76,3 +79,20 @@ func TestHouseForReturnsZeroOnDegenerateCusps(t *testing.T) {
 		t.Errorf("HouseFor with degenerate cusps = %d, want 0", got)
 	}
 }
+
+// TestHouseMargin measures along the circle, so a longitude just
+// past 360° sits close to a cusp just below it.
+func TestHouseMargin(t *testing.T) {
+	var cusps [12]float64
+	for i := range cusps {
+		cusps[i] = math.Mod(350+float64(i)*30, 360)
+	}
+	cases := []struct{ long, want float64 }{
+		{350, 0}, {352, 2}, {5, 15}, {19.5, 0.5}, {20.25, 0.25}, {185, 15},
+	}
+	for _, tc := range cases {
+		if got := HouseMargin(tc.long, cusps); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("HouseMargin(%v) = %v, want %v", tc.long, got, tc.want)
+		}
+	}
+}
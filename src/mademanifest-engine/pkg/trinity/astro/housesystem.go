@@ -0,0 +1,175 @@
+package astro
+
+import (
+	"errors"
+	"fmt"
+
+	"mademanifest-engine/pkg/astronomy"
+	"mademanifest-engine/pkg/ephemeris"
+	"mademanifest-engine/pkg/trinity/input"
+	"mademanifest-engine/pkg/trinity/output"
+)
+
+// placidus is the Swiss Ephemeris letter of the canonical house
+// system (Document 03: house_system = placidus).
+const placidus = int('P')
+
+// HouseSystem is one entry of the house-system registry: the name
+// the extensions select it by and its Swiss Ephemeris letter.
+// Polar marks the systems undefined inside the polar circles, where
+// swe_houses_ex falls back to Porphyry and Houses reports an
+// *ephemeris.HousesError instead.
+type HouseSystem struct {
+	Name  string
+	HSys  int
+	Polar bool
+}
+
+// HouseSystems is the house-system registry, canonical Placidus
+// first.  ComputeAstrology only ever uses Placidus; the others are
+// for the non-canonical extensions (pkg/trinity/extended), placed
+// with the same HouseFor.  At high latitudes:
+//
+//   - placidus, koch: time-divided systems, undefined inside the
+//     polar circles (|latitude| >= ~66.56°), where part of the
+//     ecliptic never rises or sets; CheckHouseSystemFor rejects
+//     them there.
+//   - porphyry: the trisected quadrants between the ascendant and
+//     the midheaven; defined everywhere, the quadrants growing very
+//     unequal towards the polar circles.
+//   - regiomontanus, campanus: space-divided on the celestial
+//     equator and the prime vertical; defined everywhere but at the
+//     poles, with cusps crowding towards the angles.
+//   - equal, whole_sign: thirty-degree houses from the ascendant and
+//     from the start of its sign; defined everywhere, but inside the
+//     polar circles the ascendant itself can jump by up to 180°
+//     within minutes.
+var HouseSystems = []HouseSystem{
+	{"placidus", placidus, true},
+	{"koch", int('K'), true},
+	{"porphyry", int('O'), false},
+	{"regiomontanus", int('R'), false},
+	{"campanus", int('C'), false},
+	{"equal", int('E'), false},
+	{"whole_sign", int('W'), false},
+}
+
+// HouseSystemByName looks a house system up in HouseSystems.
+func HouseSystemByName(name string) (HouseSystem, bool) {
+	for _, sys := range HouseSystems {
+		if sys.Name == name {
+			return sys, true
+		}
+	}
+	return HouseSystem{}, false
+}
+
+// HouseSystemNames lists the names of HouseSystems, in registry
+// order.
+func HouseSystemNames() []string {
+	names := make([]string, len(HouseSystems))
+	for i, sys := range HouseSystems {
+		names[i] = sys.Name
+	}
+	return names
+}
+
+// CuspsAt computes the twelve cusps of sys for a birth at Julian Day
+// jd (UT) and the given place, normalised to [0, 360) as
+// ComputeAstrologyAt normalises the Placidus ones.  An undefined
+// system fails with Houses' *ephemeris.HousesError.
+func CuspsAt(eph ephemeris.Provider, jd, latitude, longitude float64, sys HouseSystem) ([12]float64, error) {
+	raw, _, _, err := eph.Houses(jd, latitude, longitude, sys.HSys)
+	if err != nil {
+		return [12]float64{}, err
+	}
+	var cusps [12]float64
+	for i := range raw {
+		cusps[i] = normalizeDeg(raw[i])
+	}
+	return cusps, nil
+}
+
+// CuspTable is the house_cusps table of normalised cusps, in house
+// order 1..12.
+func CuspTable(cusps [12]float64) []output.HouseCusp {
+	table := make([]output.HouseCusp, 12)
+	for i, c := range cusps {
+		table[i] = output.HouseCusp{
+			House:     i + 1,
+			Longitude: output.Longitude(c),
+			Sign:      SignFor(c),
+		}
+	}
+	return table
+}
+
+// CheckHouseSystem rejects payloads whose birth place has no
+// Placidus houses at the birth moment.  Inside the polar circles –
+// |latitude| >= 90° minus the obliquity of the ecliptic, about
+// 66.56° for any birth the bundled ephemeris covers – part of the
+// ecliptic never rises or sets and the Placidus cusps do not exist.
+// Swiss Ephemeris would silently substitute Porphyry cusps, which
+// the response would then mislabel house_system: "placidus"; the
+// canon pins Placidus without a fallback, so such a birth is
+// outside the engine's scope and is classified unsupported_input
+// (HTTP 422) on field latitude with error_code polar_latitude.
+// The limit is whatever eph reports as undefined (an
+// *ephemeris.HousesError), so the rare birth just outside the
+// circle whose cusp iteration does not converge is rejected too.
+//
+// The request pipeline runs this gate after the coverage gate and
+// before any section is computed, so a polar birth is rejected
+// whatever sections it selects.  The non-nil error return is
+// reserved for engine-side failures.
+func CheckHouseSystem(eph ephemeris.Provider, p input.Payload) (*input.Rejection, error) {
+	utcTime, err := localToUTC(p)
+	if err != nil {
+		return nil, fmt.Errorf("convert birth time: %w", err)
+	}
+	return CheckHouseSystemAt(eph, astronomy.ConvertUTCToJulianDay(utcTime), p.Latitude, p.Longitude)
+}
+
+// CheckHouseSystemAt is CheckHouseSystem for a birth at Julian Day
+// jd (UT) and the given place.
+func CheckHouseSystemAt(eph ephemeris.Provider, jd, latitude, longitude float64) (*input.Rejection, error) {
+	_, _, _, err := eph.Houses(jd, latitude, longitude, placidus)
+	var undefined *ephemeris.HousesError
+	switch {
+	case errors.As(err, &undefined):
+		return &input.Rejection{
+			Type:  input.RejectUnsupported,
+			Code:  input.CodePolarLatitude,
+			Field: "latitude",
+			Message: fmt.Sprintf("Placidus houses are undefined at latitude %v at this birth moment "+
+				"(inside the polar circle, |latitude| >= ~66.56°); the canonical house system has no fallback",
+				latitude),
+		}, nil
+	case err != nil:
+		return nil, fmt.Errorf("compute houses: %w", err)
+	}
+	return nil, nil
+}
+
+// CheckHouseSystemFor is CheckHouseSystemAt for any system of the
+// registry: a birth whose sys houses are undefined is the same
+// unsupported_input polar_latitude rejection.  The extensions run
+// it after the canonical gates, so it only ever rejects a Polar
+// system in the rare case Placidus converges where sys does not.
+func CheckHouseSystemFor(eph ephemeris.Provider, jd, latitude, longitude float64, sys HouseSystem) (*input.Rejection, error) {
+	_, err := CuspsAt(eph, jd, latitude, longitude, sys)
+	var undefined *ephemeris.HousesError
+	switch {
+	case errors.As(err, &undefined):
+		return &input.Rejection{
+			Type:  input.RejectUnsupported,
+			Code:  input.CodePolarLatitude,
+			Field: "latitude",
+			Message: fmt.Sprintf("%s houses are undefined at latitude %v at this birth moment "+
+				"(inside the polar circle, |latitude| >= ~66.56°)", sys.Name, latitude),
+		}, nil
+	case err != nil:
+		return nil, fmt.Errorf("compute %s houses: %w", sys.Name, err)
+	}
+	return nil, nil
+}
@@ -0,0 +1,97 @@
+package astro
+
+import (
+	"strings"
+	"testing"
+
+	"mademanifest-engine/pkg/ephemeris/ephemeristest"
+	"mademanifest-engine/pkg/trinity/input"
+)
+
+// TestCheckHouseSystem: a place where the provider reports Placidus
+// undefined is an unsupported_input polar_latitude rejection on
+// latitude; a defined one passes; any other provider failure is an
+// engine error, not a rejection.
+func TestCheckHouseSystem(t *testing.T) {
+	f := j2000Fake()
+	if rej, err := CheckHouseSystem(f, j2000); rej != nil || err != nil {
+		t.Errorf("defined houses: rejection %+v, err %v", rej, err)
+	}
+
+	polar := j2000
+	polar.Latitude, polar.Longitude = 78.2232, 15.6267
+	f.AddHouses(ephemeristest.HouseSample{JD: j2000JD, Latitude: polar.Latitude,
+		Longitude: polar.Longitude, HSys: 'P', Undefined: true})
+	rej, err := CheckHouseSystem(f, polar)
+	if err != nil {
+		t.Fatalf("polar: err = %v", err)
+	}
+	if rej == nil || rej.Type != input.RejectUnsupported || rej.Code != input.CodePolarLatitude ||
+		rej.Field != "latitude" || !strings.Contains(rej.Message, "78.2232") {
+		t.Errorf("polar: rejection = %+v, want unsupported polar_latitude on latitude", rej)
+	}
+
+	f.HouseTable = nil
+	if rej, err := CheckHouseSystem(f, j2000); rej != nil || err == nil ||
+		!strings.Contains(err.Error(), "compute houses") {
+		t.Errorf("provider failure: rejection %+v, err %v", rej, err)
+	}
+}
+
+// TestHouseSystemRegistry: names and Swiss Ephemeris letters are
+// unique, canonical Placidus comes first, and only the time-divided
+// systems are marked Polar.
+func TestHouseSystemRegistry(t *testing.T) {
+	if HouseSystems[0].Name != "placidus" || HouseSystems[0].HSys != placidus {
+		t.Errorf("first registry entry %+v, want canonical placidus", HouseSystems[0])
+	}
+	names, letters := map[string]bool{}, map[int]bool{}
+	for _, sys := range HouseSystems {
+		if names[sys.Name] || letters[sys.HSys] {
+			t.Errorf("duplicate registry entry %+v", sys)
+		}
+		names[sys.Name], letters[sys.HSys] = true, true
+		if got, ok := HouseSystemByName(sys.Name); !ok || got != sys {
+			t.Errorf("HouseSystemByName(%s) = %+v, %v", sys.Name, got, ok)
+		}
+		if polar := sys.Name == "placidus" || sys.Name == "koch"; sys.Polar != polar {
+			t.Errorf("%s: Polar %v", sys.Name, sys.Polar)
+		}
+	}
+	if _, ok := HouseSystemByName("Koch"); ok {
+		t.Error("HouseSystemByName is case-insensitive")
+	}
+}
+
+// TestCheckHouseSystemFor: CuspsAt normalises the provider's cusps,
+// and a system the provider reports undefined is the polar_latitude
+// rejection, naming the system.
+func TestCheckHouseSystemFor(t *testing.T) {
+	f := j2000Fake()
+	koch, _ := HouseSystemByName("koch")
+	h := ephemeristest.HouseSample{JD: j2000JD, Latitude: 52, Longitude: 4, HSys: koch.HSys}
+	for i := range h.Cusps {
+		h.Cusps[i] = float64(30*i - 10)
+	}
+	f.AddHouses(h)
+	cusps, err := CuspsAt(f, j2000JD, 52, 4, koch)
+	if err != nil || cusps[0] != 350 || cusps[1] != 20 {
+		t.Errorf("CuspsAt = %v, %v", cusps, err)
+	}
+	if rej, err := CheckHouseSystemFor(f, j2000JD, 52, 4, koch); rej != nil || err != nil {
+		t.Errorf("defined houses: rejection %+v, err %v", rej, err)
+	}
+
+	f.AddHouses(ephemeristest.HouseSample{JD: j2000JD, Latitude: 66.5, Longitude: 4, HSys: koch.HSys,
+		Undefined: true})
+	rej, err := CheckHouseSystemFor(f, j2000JD, 66.5, 4, koch)
+	if err != nil || rej == nil || rej.Code != input.CodePolarLatitude || rej.Field != "latitude" ||
+		!strings.Contains(rej.Message, "koch") {
+		t.Errorf("undefined houses: rejection %+v, err %v", rej, err)
+	}
+
+	campanus, _ := HouseSystemByName("campanus")
+	if rej, err := CheckHouseSystemFor(f, j2000JD, 52, 4, campanus); rej != nil || err == nil {
+		t.Errorf("provider failure: rejection %+v, err %v", rej, err)
+	}
+}
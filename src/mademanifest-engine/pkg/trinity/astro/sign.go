@@ -12,7 +12,11 @@
 // pkg/ephemeris + Swiss Ephemeris for the underlying numerics.
 package astro
 
-import "mademanifest-engine/pkg/canon"
+import (
+	"math"
+
+	"mademanifest-engine/pkg/canon"
+)
 
 // SignFor returns the canonical lowercase sign identifier for an
 // ecliptic longitude in [0, 360).  Each sign spans 30° with start-
@@ -34,3 +38,11 @@ func SignFor(longitude float64) string {
 	}
 	return canon.SignOrder[idx]
 }
+
+// SignMargin returns how far a normalised longitude lies from the
+// nearest sign boundary (a multiple of 30°), in degrees: the
+// smallest shift, in either direction, that would change SignFor.
+func SignMargin(longitude float64) float64 {
+	off := math.Mod(longitude, 30.0)
+	return math.Min(off, 30.0-off)
+}
@@ -1,6 +1,9 @@
 package astro
 
-import "testing"
+import (
+	"math"
+	"testing"
+)
 
 // TestSignForBoundariesAndWraps drives the every-30°-boundary
 // invariant in the canon: each sign is exactly 30° wide,
@@ -49,3 +52,16 @@ func TestSignForRejectsOutOfRange(t *testing.T) {
 		}
 	}
 }
+
+// TestSignMargin pins the distance to the nearest 30° boundary,
+// from either side and across 0°.
+func TestSignMargin(t *testing.T) {
+	cases := []struct{ longitude, want float64 }{
+		{0, 0}, {30, 0}, {3.5, 3.5}, {26, 4}, {15, 15}, {359.75, 0.25}, {333.397401, 3.397401},
+	}
+	for _, tc := range cases {
+		if got := SignMargin(tc.longitude); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("SignMargin(%v) = %v, want %v", tc.longitude, got, tc.want)
+		}
+	}
+}
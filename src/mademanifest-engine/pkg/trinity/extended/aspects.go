@@ -0,0 +1,135 @@
+package extended
+
+import (
+	"math"
+
+	"mademanifest-engine/pkg/trinity/output"
+)
+
+// OrbTableVersion is the revision of Orbs.  It bumps on any change
+// to an aspect, its angle or its orb, and is reported next to the
+// aspects so a stored chart says which table found them.
+const OrbTableVersion = "orbs-v1-rev-0"
+
+// The aspect classes.
+const (
+	ClassMajor = "major"
+	ClassMinor = "minor"
+)
+
+// The motions of an aspect: whether its orb is closing, opening, or
+// constant because both points move together (the Earth and the
+// Sun).
+const (
+	MotionApplying   = "applying"
+	MotionSeparating = "separating"
+	MotionFixed      = "fixed"
+)
+
+// AspectDef is one row of the orb table: two points form the aspect
+// when their angular separation is within Orb degrees of Angle, both
+// ends included.
+type AspectDef struct {
+	Name  string
+	Class string
+	Angle float64
+	Orb   float64
+}
+
+// Orbs is the compiled-in orb table, revision OrbTableVersion: the
+// five Ptolemaic aspects and six minor ones.  The orbs are the same
+// for every pair of points, and no two rows overlap, so a pair forms
+// at most one aspect.
+var Orbs = []AspectDef{
+	{Name: "conjunction", Class: ClassMajor, Angle: 0, Orb: 8},
+	{Name: "sextile", Class: ClassMajor, Angle: 60, Orb: 5},
+	{Name: "square", Class: ClassMajor, Angle: 90, Orb: 7},
+	{Name: "trine", Class: ClassMajor, Angle: 120, Orb: 7},
+	{Name: "opposition", Class: ClassMajor, Angle: 180, Orb: 8},
+	{Name: "semisextile", Class: ClassMinor, Angle: 30, Orb: 2},
+	{Name: "semisquare", Class: ClassMinor, Angle: 45, Orb: 2},
+	{Name: "quintile", Class: ClassMinor, Angle: 72, Orb: 2},
+	{Name: "sesquiquadrate", Class: ClassMinor, Angle: 135, Orb: 2},
+	{Name: "biquintile", Class: ClassMinor, Angle: 144, Orb: 2},
+	{Name: "quincunx", Class: ClassMinor, Angle: 150, Orb: 3},
+}
+
+// AspectNames lists the names of Orbs, in table order.
+func AspectNames() []string {
+	names := make([]string, len(Orbs))
+	for i, def := range Orbs {
+		names[i] = def.Name
+	}
+	return names
+}
+
+// Aspect is one aspect between two points of a chart.  Separation is
+// their angular distance in [0, 180] and Orb its distance to the
+// exact aspect angle.  Motion tells whether the orb is closing
+// (applying) or opening (separating) as time runs forward, from the
+// points' speeds; an exact aspect is separating.
+type Aspect struct {
+	ObjectA    string           `json:"object_a"`
+	ObjectB    string           `json:"object_b"`
+	Aspect     string           `json:"aspect"`
+	Class      string           `json:"class"`
+	Angle      float64          `json:"angle"`
+	Separation output.Longitude `json:"separation"`
+	Orb        output.Longitude `json:"orb"`
+	Motion     string           `json:"motion"`
+}
+
+// FindAspects returns the aspects of Orbs between every pair of
+// points, ordered as the pairs are: by the first point, then the
+// second, in the order of points.
+func FindAspects(points []Point) []Aspect {
+	aspects := []Aspect{}
+	for i, a := range points {
+		for _, b := range points[i+1:] {
+			arc := signedArc(a.Longitude, b.Longitude)
+			separation := math.Abs(arc)
+			for _, def := range Orbs {
+				deviation := separation - def.Angle
+				if math.Abs(deviation) > def.Orb {
+					continue
+				}
+				aspects = append(aspects, Aspect{
+					ObjectA:    a.ID,
+					ObjectB:    b.ID,
+					Aspect:     def.Name,
+					Class:      def.Class,
+					Angle:      def.Angle,
+					Separation: output.Longitude(separation),
+					Orb:        output.Longitude(math.Abs(deviation)),
+					Motion:     motion(arc, deviation, b.Speed-a.Speed),
+				})
+				break
+			}
+		}
+	}
+	return aspects
+}
+
+// fixedSpeed is the relative speed, in degrees per day, under which
+// two points are taken to move together: the Earth and the Sun
+// differ only by the rounding of earth = sun + 180.
+const fixedSpeed = 1e-9
+
+// motion classifies an aspect from the signed arc between its points,
+// the signed deviation of their separation from the aspect angle and
+// their relative speed: the separation |arc| grows at
+// sign(arc)·relative, so the orb |deviation| closes when that rate
+// and the deviation have opposite signs.
+func motion(arc, deviation, relative float64) string {
+	if math.Abs(relative) < fixedSpeed {
+		return MotionFixed
+	}
+	rate := relative
+	if arc < 0 {
+		rate = -rate
+	}
+	if deviation == 0 || (deviation > 0) == (rate > 0) {
+		return MotionSeparating
+	}
+	return MotionApplying
+}
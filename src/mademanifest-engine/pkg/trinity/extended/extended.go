@@ -0,0 +1,127 @@
+// Package extended computes the non-canonical "extended astrology"
+// of a chart: output trinity.org keeps out of the v1 canonical
+// envelope (§"Explicitly out of scope") but the product needs, such
+// as the aspects between the natal objects and the direction of
+// each object (direct, retrograde or stationary).  It reuses the
+// canonical positions of astro.ComputeAstrology unchanged and never
+// adds to output.SuccessEnvelope: the extension is served on its own route
+// with its own Version, so it can evolve without a canon revision
+// and a canonical client never sees it.
+package extended
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"mademanifest-engine/pkg/ephemeris"
+	"mademanifest-engine/pkg/trinity/astro"
+	"mademanifest-engine/pkg/trinity/output"
+)
+
+// Version is the revision of the extended astrology output.  It
+// bumps on any change to its shape or to a computation it reports,
+// independently of canon.CanonVersion; the orb table carries its
+// own OrbTableVersion and the extended object set its own
+// ObjectSetVersion.
+const Version = "extended-v1-rev-3"
+
+// The angles are aspecting points besides the astrology objects.
+const (
+	PointAscendant = "ascendant"
+	PointMidheaven = "midheaven"
+)
+
+// SpeedStep is the half-width of the central difference the speed
+// of an angle is taken from: the houses are computed SpeedStep
+// before and after the birth instant.  Ten minutes keeps the error
+// of the difference far below the precision of the orbs (the angles
+// move about 2.5° per side).  The objects' speeds come from the
+// ephemeris itself (SpeedsAt).
+const SpeedStep = 10 * time.Minute
+
+// Point is one aspecting point of a chart: an astrology object, in
+// canon.AstrologyObjectOrder, or an angle.  Longitude is in degrees
+// in [0, 360); Speed in degrees per day, negative when the point
+// moves backwards through the zodiac (a retrograde planet, the mean
+// node).
+type Point struct {
+	ID        string
+	Longitude float64
+	Speed     float64
+}
+
+// Chart is the extended astrology of one birth: the canonical
+// astrology section, the speed and direction of its objects, and its
+// aspecting points.
+type Chart struct {
+	Astrology output.Astrology
+	Speeds    []ObjectSpeed
+	Points    []Point
+}
+
+// ChartAt computes the chart of a birth at Julian Day jd (UT) and
+// the given place: the astrology section with
+// astro.ComputeAstrologyAt, the objects' speeds with SpeedsAt, and
+// the points – the objects in canonical order, then the ascendant
+// and the midheaven, whose speeds are the central difference of the
+// same computation SpeedStep on either side of jd.
+func ChartAt(eph ephemeris.VectorProvider, jd, latitude, longitude float64) (Chart, error) {
+	a, err := astro.ComputeAstrologyAt(eph, jd, latitude, longitude)
+	if err != nil {
+		return Chart{}, err
+	}
+	speeds, err := SpeedsAt(eph, jd)
+	if err != nil {
+		return Chart{}, err
+	}
+	step := stepDays()
+	before, err := astro.ComputeAstrologyAt(eph, jd-step, latitude, longitude)
+	if err != nil {
+		return Chart{}, fmt.Errorf("compute angle speeds: %w", err)
+	}
+	after, err := astro.ComputeAstrologyAt(eph, jd+step, latitude, longitude)
+	if err != nil {
+		return Chart{}, fmt.Errorf("compute angle speeds: %w", err)
+	}
+
+	now, prev, next := longitudes(a), longitudes(before), longitudes(after)
+	points := make([]Point, len(now))
+	for i := range now {
+		points[i] = Point{ID: now[i].ID, Longitude: now[i].Longitude}
+		if i < len(speeds) {
+			points[i].Speed = float64(speeds[i].Speed)
+		} else {
+			points[i].Speed = signedArc(prev[i].Longitude, next[i].Longitude) / (2 * step)
+		}
+	}
+	return Chart{Astrology: a, Speeds: speeds, Points: points}, nil
+}
+
+// stepDays is SpeedStep in days.
+func stepDays() float64 {
+	return SpeedStep.Hours() / 24
+}
+
+// longitudes lists the points of a, without speeds.
+func longitudes(a output.Astrology) []Point {
+	points := make([]Point, 0, len(a.Objects)+2)
+	for _, o := range a.Objects {
+		points = append(points, Point{ID: o.ObjectID, Longitude: float64(o.Longitude)})
+	}
+	return append(points,
+		Point{ID: PointAscendant, Longitude: float64(a.Angles.Ascendant.Longitude)},
+		Point{ID: PointMidheaven, Longitude: float64(a.Angles.Midheaven.Longitude)})
+}
+
+// signedArc returns the arc from longitude a to longitude b the
+// short way round, in (-180, 180].
+func signedArc(a, b float64) float64 {
+	d := math.Mod(b-a, 360)
+	if d <= -180 {
+		d += 360
+	} else if d > 180 {
+		d -= 360
+	}
+	return d
+}
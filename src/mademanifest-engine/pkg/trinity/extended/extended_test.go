@@ -0,0 +1,256 @@
+package extended
+
+import (
+	"math"
+	"testing"
+
+	"mademanifest-engine/pkg/canon"
+	"mademanifest-engine/pkg/ephemeris/ephemeristest"
+	"mademanifest-engine/pkg/trinity/astro"
+	"mademanifest-engine/pkg/trinity/output"
+)
+
+// TestOrbTableDisjoint: no separation falls within the orbs of two
+// rows, so FindAspects never has to choose between aspects.
+func TestOrbTableDisjoint(t *testing.T) {
+	for i, a := range Orbs {
+		if a.Orb <= 0 || a.Angle < 0 || a.Angle > 180 {
+			t.Errorf("%s: angle %v orb %v", a.Name, a.Angle, a.Orb)
+		}
+		for _, b := range Orbs[i+1:] {
+			if math.Abs(a.Angle-b.Angle) <= a.Orb+b.Orb {
+				t.Errorf("%s and %s overlap", a.Name, b.Name)
+			}
+		}
+	}
+}
+
+// TestFindAspects: orbs are inclusive, the separation is taken the
+// short way round 0°, and the motion follows the points' speeds.
+func TestFindAspects(t *testing.T) {
+	points := []Point{
+		{ID: "sun", Longitude: 10, Speed: 1},
+		{ID: "earth", Longitude: 190, Speed: 1},
+		{ID: "moon", Longitude: 126, Speed: 13},
+		{ID: "mars", Longitude: 352, Speed: 0.5},
+		{ID: "saturn", Longitude: 287, Speed: -0.05},
+	}
+	type found struct{ a, b, aspect, motion string }
+	want := map[found]float64{
+		{"sun", "earth", "opposition", MotionFixed}:        0,
+		{"sun", "moon", "trine", MotionApplying}:           4,
+		{"sun", "saturn", "square", MotionApplying}:        7, // 83° apart across 0°
+		{"earth", "moon", "sextile", MotionApplying}:       4,
+		{"earth", "saturn", "square", MotionApplying}:      7,
+		{"moon", "mars", "sesquiquadrate", MotionApplying}: 1,
+		{"mars", "saturn", "sextile", MotionSeparating}:    5,
+	}
+
+	got := FindAspects(points)
+	seen := map[found]bool{}
+	for _, a := range got {
+		key := found{a.ObjectA, a.ObjectB, a.Aspect, a.Motion}
+		orb, ok := want[key]
+		if !ok {
+			t.Errorf("unexpected %+v", a)
+			continue
+		}
+		seen[key] = true
+		if math.Abs(float64(a.Orb)-orb) > 1e-9 {
+			t.Errorf("%+v: orb %v, want %v", key, a.Orb, orb)
+		}
+	}
+	for key := range want {
+		if !seen[key] {
+			t.Errorf("missing %+v", key)
+		}
+	}
+}
+
+// TestMotion covers both sides of the exact angle, for both signs of
+// the arc.
+func TestMotion(t *testing.T) {
+	for _, c := range []struct {
+		arc, deviation, relative float64
+		want                     string
+	}{
+		{118, -2, 1, MotionApplying},    // B ahead, pulling away towards 120
+		{118, -2, -1, MotionSeparating}, // B ahead, falling back
+		{-118, -2, -1, MotionApplying},  // B behind, falling further back
+		{-122, 2, -1, MotionSeparating},
+		{-122, 2, 1, MotionApplying},
+		{120, 0, 1, MotionSeparating},
+		{5, 5, 0, MotionFixed},
+	} {
+		if got := motion(c.arc, c.deviation, c.relative); got != c.want {
+			t.Errorf("motion(%v, %v, %v) = %s, want %s", c.arc, c.deviation, c.relative, got, c.want)
+		}
+	}
+}
+
+// TestChartAtSpeeds: the objects move at their ephemeris speeds, the
+// Earth with the Sun, and the angles at the central difference of
+// the houses SpeedStep either side of the birth.
+func TestChartAtSpeeds(t *testing.T) {
+	const jd = 2451545.0
+	step := stepDays()
+	f := &ephemeristest.Fake{}
+	speeds := map[string]float64{"sun": 1, "moon": 13, "mercury": -0.5, "venus": 0.05, "north_node_mean": -0.05}
+	for _, body := range append(canon.AstrologyObjectOrder[:], "north_node", "north_node_true") {
+		speed := speeds[body]
+		for _, dt := range []float64{-1, 1} {
+			f.AddLongitude(body, jd+dt, math.Mod(359.9+speed*dt+360, 360))
+		}
+	}
+	for _, dt := range []float64{-step, 0, step} {
+		h := ephemeristest.HouseSample{JD: jd + dt, Latitude: 52, Longitude: 4, HSys: 'P',
+			Ascendant: 100 + 360*dt, Midheaven: 10 + 360*dt}
+		for i := range h.Cusps {
+			h.Cusps[i] = float64(30 * i)
+		}
+		f.AddHouses(h)
+	}
+
+	c, err := ChartAt(f, jd, 52, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	points := c.Points
+	if len(points) != len(c.Astrology.Objects)+2 || points[len(points)-2].ID != PointAscendant ||
+		points[len(points)-1].ID != PointMidheaven {
+		t.Fatalf("points %+v", points)
+	}
+	want := map[string]float64{"sun": 1, "earth": 1, "moon": 13, "mercury": -0.5, "venus": 0.05, "mars": 0,
+		"north_node_mean": -0.05, PointAscendant: 360, PointMidheaven: 360}
+	for _, p := range points {
+		if w, ok := want[p.ID]; ok && math.Abs(p.Speed-w) > 1e-6 {
+			t.Errorf("%s speed %v, want %v", p.ID, p.Speed, w)
+		}
+	}
+
+	directions := map[string]string{}
+	for i, s := range c.Speeds {
+		if s.ObjectID != canon.AstrologyObjectOrder[i] || float64(s.Speed) != points[i].Speed {
+			t.Errorf("speed %d = %+v, point %+v", i, s, points[i])
+		}
+		directions[s.ObjectID] = s.Direction
+	}
+	for id, direction := range map[string]string{"sun": DirectionDirect, "earth": DirectionDirect,
+		"mercury": DirectionRetrograde, "venus": DirectionStationary, "mars": DirectionStationary,
+		"north_node_mean": DirectionRetrograde} {
+		if directions[id] != direction {
+			t.Errorf("%s is %s, want %s", id, directions[id], direction)
+		}
+	}
+}
+
+// TestDirection: the threshold is strict, and bodies without one
+// are never stationary.
+func TestDirection(t *testing.T) {
+	for _, c := range []struct {
+		id    string
+		speed float64
+		want  string
+	}{
+		{"mercury", 0.1, DirectionDirect},
+		{"mercury", -0.0999, DirectionStationary},
+		{"mercury", -0.1, DirectionRetrograde},
+		{"pluto", 0, DirectionStationary},
+		{"moon", 0, DirectionDirect},
+		{"north_node_mean", -0.0001, DirectionRetrograde},
+	} {
+		if got := Direction(c.id, c.speed); got != c.want {
+			t.Errorf("Direction(%s, %v) = %s, want %s", c.id, c.speed, got, c.want)
+		}
+	}
+}
+
+// TestObjectsAt: the extended objects are placed against the
+// chart's own cusps with the canonical sign and house mapping, in
+// ExtendedObjects order, and the mean south node moves with the mean
+// north node, 180° away.
+func TestObjectsAt(t *testing.T) {
+	const jd = 2451545.0
+	f := &ephemeristest.Fake{}
+	longs := map[string]float64{"ceres": 5, "pallas": 95, "juno": 359.5, "vesta": 200,
+		"lilith_mean": 31, "lilith_true": 29.9, "north_node": 10}
+	speeds := map[string]float64{"ceres": 0.25, "pallas": -0.2, "juno": 0.01, "vesta": -0.02,
+		"lilith_mean": 0.11, "lilith_true": -3, "north_node": -0.05}
+	for body, long := range longs {
+		for _, dt := range []float64{-1, 1} {
+			f.AddLongitude(body, jd+dt, math.Mod(long+speeds[body]*dt+360, 360))
+		}
+	}
+	a := output.Astrology{}
+	for i := 0; i < 12; i++ {
+		a.HouseCusps = append(a.HouseCusps, output.HouseCusp{House: i + 1, Longitude: output.Longitude(30*i + 15)})
+	}
+
+	set, err := ObjectsAt(f, jd, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set.Version != ObjectSetVersion || len(set.Objects) != len(ExtendedObjects) {
+		t.Fatalf("set %+v", set)
+	}
+	want := []ExtendedObject{
+		{"ceres", 5, "aries", 12, 0.25, DirectionDirect},
+		{"pallas", 95, "cancer", 3, -0.2, DirectionRetrograde},
+		{"juno", 359.5, "pisces", 12, 0.01, DirectionStationary},
+		{"vesta", 200, "libra", 7, -0.02, DirectionStationary},
+		{"lilith_mean", 31, "taurus", 1, 0.11, DirectionDirect},
+		{"lilith_true", 29.9, "aries", 1, -3, DirectionRetrograde},
+		{"south_node_mean", 190, "libra", 6, -0.05, DirectionRetrograde},
+	}
+	for i, o := range set.Objects {
+		w := want[i]
+		if o.ObjectID != w.ObjectID || math.Abs(float64(o.Longitude-w.Longitude)) > 1e-9 || o.Sign != w.Sign ||
+			o.House != w.House || math.Abs(float64(o.Speed-w.Speed)) > 1e-9 || o.Direction != w.Direction {
+			t.Errorf("object %d = %+v, want %+v", i, o, w)
+		}
+	}
+
+	if _, err := ObjectsAt(f, jd, output.Astrology{}); err == nil {
+		t.Error("ObjectsAt without house cusps succeeded")
+	}
+}
+
+// TestHousesAt: the table carries the system's own cusps, and every
+// canonical object, then every extended one, is placed against them
+// with astro.HouseFor.
+func TestHousesAt(t *testing.T) {
+	const jd = 2451545.0
+	whole, _ := astro.HouseSystemByName("whole_sign")
+	f := &ephemeristest.Fake{}
+	h := ephemeristest.HouseSample{JD: jd, Latitude: 52, Longitude: 4, HSys: whole.HSys}
+	for i := range h.Cusps {
+		h.Cusps[i] = float64(60 + 30*i)
+	}
+	f.AddHouses(h)
+	a := output.Astrology{Objects: []output.AstroObject{
+		{ObjectID: "sun", Longitude: 60}, {ObjectID: "moon", Longitude: 59.9}, {ObjectID: "mars", Longitude: 150}}}
+	set := &ObjectSet{Objects: []ExtendedObject{{ObjectID: "ceres", Longitude: 0}}}
+
+	table, err := HousesAt(f, jd, 52, 4, whole, a, set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.HouseSystem != "whole_sign" || len(table.HouseCusps) != 12 || table.HouseCusps[0].Longitude != 60 ||
+		table.HouseCusps[0].Sign != "gemini" || table.HouseCusps[11].Longitude != 30 {
+		t.Errorf("table %+v", table)
+	}
+	want := []Placement{{"sun", 1}, {"moon", 12}, {"mars", 4}, {"ceres", 11}}
+	if len(table.Placements) != len(want) {
+		t.Fatalf("placements %+v", table.Placements)
+	}
+	for i, p := range table.Placements {
+		if p != want[i] {
+			t.Errorf("placement %d = %+v, want %+v", i, p, want[i])
+		}
+	}
+
+	koch, _ := astro.HouseSystemByName("koch")
+	if _, err := HousesAt(f, jd, 52, 4, koch, a, nil); err == nil {
+		t.Error("HousesAt without koch samples succeeded")
+	}
+}
@@ -0,0 +1,49 @@
+package extended
+
+import (
+	"fmt"
+
+	"mademanifest-engine/pkg/ephemeris"
+	"mademanifest-engine/pkg/trinity/astro"
+	"mademanifest-engine/pkg/trinity/output"
+)
+
+// Placement is the house of one object in a HouseTable.
+type Placement struct {
+	ObjectID string `json:"object_id"`
+	House    int    `json:"house"`
+}
+
+// HouseTable is a chart's houses in a system of the registry
+// (astro.HouseSystems), apart from the canonical Placidus houses of
+// the astrology section: the system's cusp table and the house of
+// every object in it.
+type HouseTable struct {
+	HouseSystem string             `json:"house_system"`
+	HouseCusps  []output.HouseCusp `json:"house_cusps"`
+	Placements  []Placement        `json:"placements"`
+}
+
+// HousesAt computes the houses in sys of a birth at Julian Day jd
+// (UT) and the given place, whose canonical astrology section is a.
+// The objects of a, in canonical order, then those of objects when
+// the extended object set was asked for, are placed with
+// astro.HouseFor against the sys cusps, the placement the canonical
+// objects get against the Placidus ones.
+func HousesAt(eph ephemeris.Provider, jd, latitude, longitude float64, sys astro.HouseSystem,
+	a output.Astrology, objects *ObjectSet) (HouseTable, error) {
+	cusps, err := astro.CuspsAt(eph, jd, latitude, longitude, sys)
+	if err != nil {
+		return HouseTable{}, fmt.Errorf("compute %s houses: %w", sys.Name, err)
+	}
+	placements := make([]Placement, 0, len(a.Objects))
+	for _, o := range a.Objects {
+		placements = append(placements, Placement{o.ObjectID, astro.HouseFor(float64(o.Longitude), cusps)})
+	}
+	if objects != nil {
+		for _, o := range objects.Objects {
+			placements = append(placements, Placement{o.ObjectID, astro.HouseFor(float64(o.Longitude), cusps)})
+		}
+	}
+	return HouseTable{HouseSystem: sys.Name, HouseCusps: astro.CuspTable(cusps), Placements: placements}, nil
+}
@@ -0,0 +1,90 @@
+package extended
+
+import (
+	"fmt"
+	"math"
+
+	"mademanifest-engine/pkg/ephemeris"
+	"mademanifest-engine/pkg/trinity/astro"
+	"mademanifest-engine/pkg/trinity/output"
+)
+
+// ObjectSetVersion is the revision of the extended object set: the
+// objects of ExtendedObjects, their bodies and their order.  It
+// bumps on any change to them, independently of Version.
+const ObjectSetVersion = "objects-v1-rev-0"
+
+// ExtendedObjects is the opt-in extended object set, in report
+// order: the four main-belt asteroids, the mean and the osculating
+// ("true") Black Moon Lilith, and the mean south node, the
+// counterpart of the canonical north_node_mean.  ID is the
+// object_id reported, Body the ephemeris body computed.  None of
+// them is a canonical object: canon.AstrologyObjectOrder stays the
+// thirteen of Document 02.
+var ExtendedObjects = []struct {
+	ID   string
+	Body string
+}{
+	{"ceres", "ceres"},
+	{"pallas", "pallas"},
+	{"juno", "juno"},
+	{"vesta", "vesta"},
+	{"lilith_mean", "lilith_mean"},
+	{"lilith_true", "lilith_true"},
+	{"south_node_mean", "south_node"},
+}
+
+// ExtendedObject is one object of the extended object set: the
+// four fields of a canonical output.AstroObject, with the sign and
+// house mapped exactly as the canonical objects are, and its speed
+// and direction as in ObjectSpeed.
+type ExtendedObject struct {
+	ObjectID  string           `json:"object_id"`
+	Longitude output.Longitude `json:"longitude"`
+	Sign      string           `json:"sign"`
+	House     int              `json:"house"`
+	Speed     output.Longitude `json:"speed"`
+	Direction string           `json:"direction"`
+}
+
+// ObjectSet is the extended object set of a chart, with the
+// revision it was computed under.
+type ObjectSet struct {
+	Version string           `json:"object_set_version"`
+	Objects []ExtendedObject `json:"objects"`
+}
+
+// ObjectsAt computes the extended object set at Julian Day jd (UT)
+// for the chart whose canonical astrology section is a: each object
+// is placed with astro.SignFor and astro.HouseFor against the
+// Placidus cusps of a, the mapping astro.ComputeAstrologyAt applies
+// to the canonical objects.
+func ObjectsAt(eph ephemeris.VectorProvider, jd float64, a output.Astrology) (ObjectSet, error) {
+	if len(a.HouseCusps) != 12 {
+		return ObjectSet{}, fmt.Errorf("place extended objects: %d house cusps", len(a.HouseCusps))
+	}
+	var cusps [12]float64
+	for i, c := range a.HouseCusps {
+		cusps[i] = float64(c.Longitude)
+	}
+	objects := make([]ExtendedObject, 0, len(ExtendedObjects))
+	for _, o := range ExtendedObjects {
+		v, err := eph.BodyVector(jd, o.Body)
+		if err != nil {
+			return ObjectSet{}, fmt.Errorf("compute %s: %w", o.ID, err)
+		}
+		long := math.Mod(v.Longitude, 360)
+		if long < 0 {
+			long += 360
+		}
+		objects = append(objects, ExtendedObject{
+			ObjectID:  o.ID,
+			Longitude: output.Longitude(long),
+			Sign:      astro.SignFor(long),
+			House:     astro.HouseFor(long, cusps),
+			Speed:     output.Longitude(v.LongitudeSpeed),
+			Direction: Direction(o.ID, v.LongitudeSpeed),
+		})
+	}
+	return ObjectSet{Version: ObjectSetVersion, Objects: objects}, nil
+}
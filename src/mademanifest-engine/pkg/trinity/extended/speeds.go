@@ -0,0 +1,90 @@
+package extended
+
+import (
+	"fmt"
+	"math"
+
+	"mademanifest-engine/pkg/canon"
+	"mademanifest-engine/pkg/ephemeris"
+	"mademanifest-engine/pkg/trinity/output"
+)
+
+// The directions of an astrology object.
+const (
+	DirectionDirect     = "direct"
+	DirectionRetrograde = "retrograde"
+	DirectionStationary = "stationary"
+)
+
+// Directions lists the directions in report order.
+var Directions = []string{DirectionDirect, DirectionRetrograde, DirectionStationary}
+
+// StationSpeeds are the pinned speed thresholds, in degrees per day,
+// under which a body is stationary: a tenth of its mean geocentric
+// motion, which holds Mercury stationary for about a day either side
+// of its station and the outer planets for two to four days.  The
+// Sun, the Moon, the Earth, the nodes and the two Liliths have no
+// threshold: their direction is the sign of their speed.  The
+// asteroids of ExtendedObjects station like the planets.  The table
+// is pinned by Version.
+var StationSpeeds = map[string]float64{
+	"mercury": 0.1,
+	"venus":   0.1,
+	"mars":    0.05,
+	"jupiter": 0.008,
+	"saturn":  0.003,
+	"uranus":  0.001,
+	"neptune": 0.0006,
+	"pluto":   0.0004,
+	"chiron":  0.002,
+	"ceres":   0.02,
+	"pallas":  0.02,
+	"juno":    0.02,
+	"vesta":   0.03,
+}
+
+// ObjectSpeed is the speed in longitude of one astrology object, in
+// degrees per day, and the direction it gives.
+type ObjectSpeed struct {
+	ObjectID  string           `json:"object_id"`
+	Speed     output.Longitude `json:"speed"`
+	Direction string           `json:"direction"`
+}
+
+// Direction classifies an object moving at speed degrees per day:
+// stationary under its StationSpeeds threshold, else direct or
+// retrograde by the sign of speed.
+func Direction(objectID string, speed float64) string {
+	switch {
+	case math.Abs(speed) < StationSpeeds[objectID]:
+		return DirectionStationary
+	case speed < 0:
+		return DirectionRetrograde
+	default:
+		return DirectionDirect
+	}
+}
+
+// SpeedsAt returns the speed of every object of
+// canon.AstrologyObjectOrder at Julian Day jd (UT), in that order,
+// from the SEFLG_SPEED vectors of eph.  The Earth moves with the Sun
+// (earth = sun + 180°, as in astro.ComputeAstrology).
+func SpeedsAt(eph ephemeris.VectorProvider, jd float64) ([]ObjectSpeed, error) {
+	speeds := make([]ObjectSpeed, 0, len(canon.AstrologyObjectOrder))
+	for _, id := range canon.AstrologyObjectOrder {
+		body := id
+		if id == "earth" {
+			body = "sun"
+		}
+		v, err := eph.BodyVector(jd, body)
+		if err != nil {
+			return nil, fmt.Errorf("compute %s speed: %w", id, err)
+		}
+		speeds = append(speeds, ObjectSpeed{
+			ObjectID:  id,
+			Speed:     output.Longitude(v.LongitudeSpeed),
+			Direction: Direction(id, v.LongitudeSpeed),
+		})
+	}
+	return speeds, nil
+}
@@ -1,6 +1,8 @@
 package hd
 
 import (
+	"context"
+	"fmt"
 	"math"
 
 	"mademanifest-engine/pkg/astronomy"
@@ -25,8 +27,9 @@ import (
 // in Phase 5 (design activations).
 //
 // Determinism: the function below depends only on the JD argument,
-// the Swiss Ephemeris pinned via pkg/ephemeris (cross-checked at
-// boot), and the compiled-in canon constants.  No environment
+// the ephemeris.Provider (in production the Swiss Ephemeris pinned
+// via pkg/ephemeris, cross-checked at boot), and the compiled-in
+// canon constants.  No environment
 // variables are consulted; the SE_NODE_POLICY shim that previously
 // toggled mean vs. true node was removed in this same phase.
 
@@ -35,10 +38,15 @@ import (
 // north_node is always SE_TRUE_NODE for the Human Design domain.
 // south_node is mathematically derived as north_node + 180° mod 360.
 // earth is mathematically derived as sun + 180° mod 360 (matching
-// the astrology pipeline; trinity.org line 240).
-func snapshotLongitudes(jd float64) map[string]float64 {
+// the astrology pipeline; trinity.org line 240).  The first
+// ephemeris failure aborts the snapshot and is returned, as does a
+// cancelled ctx (checked before each body).
+func snapshotLongitudes(ctx context.Context, eph ephemeris.Provider, jd float64) (map[string]float64, error) {
 	out := make(map[string]float64, len(canon.HDSnapshotOrder))
 	for _, body := range canon.HDSnapshotOrder {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		switch body {
 		case "earth":
 			// Earth requires Sun's value; HDSnapshotOrder lists
@@ -51,17 +59,25 @@ func snapshotLongitudes(jd float64) map[string]float64 {
 			// HD north_node via SE_TRUE_NODE.  The astrology
 			// pipeline uses SE_MEAN_NODE under "north_node_mean"
 			// instead; the two paths are now strictly separate.
-			out["north_node"] = ephemeris.GetPlanetLongAtTime(jd, "north_node_true")
+			long, err := eph.BodyLongitude(jd, "north_node_true")
+			if err != nil {
+				return nil, err
+			}
+			out["north_node"] = long
 		case "south_node":
 			// south_node = north_node (true) + 180° mod 360.
 			// HDSnapshotOrder lists north_node before south_node
 			// so out["north_node"] is already populated.
 			out["south_node"] = mod360(out["north_node"] + 180.0)
 		default:
-			out[body] = ephemeris.GetPlanetLongAtTime(jd, body)
+			long, err := eph.BodyLongitude(jd, body)
+			if err != nil {
+				return nil, err
+			}
+			out[body] = long
 		}
 	}
-	return out
+	return out, nil
 }
 
 // activationsFor builds the canonically-ordered HDActivation slice
@@ -82,21 +98,55 @@ func activationsFor(longs map[string]float64) []output.HDActivation {
 }
 
 // ComputeActivations builds personality and design HDActivation
-// slices for a validated Trinity payload.  It consumes the
+// slices for a validated Trinity payload, reading every longitude
+// from eph.  It consumes the
 // already-computed design Julian Day (so the caller can reuse the
 // expensive bisection result rather than running it twice) and
 // returns the two slices in canon order.  Errors here are wrapped
-// engine-internal time-conversion failures; a non-nil error must be
-// surfaced as execution_failure (HTTP 500) by the caller.
-func ComputeActivations(p input.Payload, designJD float64) (personality, design []output.HDActivation, err error) {
+// engine-internal time-conversion or ephemeris failures; a non-nil
+// error must be surfaced as execution_failure (HTTP 500) by the
+// caller, except that a cancelled ctx surfaces as ctx.Err() wrapped.
+func ComputeActivations(ctx context.Context, eph ephemeris.Provider, p input.Payload, designJD float64) (personality, design []output.HDActivation, err error) {
+	birthLongs, designLongs, err := ActivationLongitudes(ctx, eph, p, designJD)
+	if err != nil {
+		return nil, nil, err
+	}
+	return activationsFor(birthLongs), activationsFor(designLongs), nil
+}
+
+// ActivationLongitudes returns the personality and design snapshot
+// longitudes ComputeActivations maps to gates and lines, keyed by
+// canon.HDSnapshotOrder name, for callers that need the positions
+// themselves (the boundary-sensitivity report).  Errors are those of
+// ComputeActivations.
+func ActivationLongitudes(ctx context.Context, eph ephemeris.Provider, p input.Payload, designJD float64) (personality, design map[string]float64, err error) {
 	utcBirth, err := localToUTC(p)
 	if err != nil {
 		return nil, nil, err
 	}
-	birthJD := astronomy.ConvertUTCToJulianDay(utcBirth)
+	return activationLongitudesAt(ctx, eph, astronomy.ConvertUTCToJulianDay(utcBirth), designJD)
+}
+
+// ActivationsAt is ComputeActivations for a birth at Julian Day
+// birthJD (UT), for callers that move the birth moment off the
+// payload's minute (the birth-time window).
+func ActivationsAt(ctx context.Context, eph ephemeris.Provider, birthJD, designJD float64) (personality, design []output.HDActivation, err error) {
+	birthLongs, designLongs, err := activationLongitudesAt(ctx, eph, birthJD, designJD)
+	if err != nil {
+		return nil, nil, err
+	}
+	return activationsFor(birthLongs), activationsFor(designLongs), nil
+}
 
-	personality = activationsFor(snapshotLongitudes(birthJD))
-	design = activationsFor(snapshotLongitudes(designJD))
+func activationLongitudesAt(ctx context.Context, eph ephemeris.Provider, birthJD, designJD float64) (personality, design map[string]float64, err error) {
+	personality, err = snapshotLongitudes(ctx, eph, birthJD)
+	if err != nil {
+		return nil, nil, fmt.Errorf("personality snapshot: %w", err)
+	}
+	design, err = snapshotLongitudes(ctx, eph, designJD)
+	if err != nil {
+		return nil, nil, fmt.Errorf("design snapshot: %w", err)
+	}
 	return personality, design, nil
 }
 
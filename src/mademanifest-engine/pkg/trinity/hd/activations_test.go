@@ -0,0 +1,99 @@
+package hd
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"mademanifest-engine/pkg/canon"
+	"mademanifest-engine/pkg/ephemeris/ephemeristest"
+	"mademanifest-engine/pkg/hd/calc"
+	"mademanifest-engine/pkg/trinity/input"
+	"mademanifest-engine/pkg/trinity/output"
+)
+
+// j2000 is 2000-01-01 12:00 UT, Julian Day 2451545.0 exactly.
+var j2000 = input.Payload{
+	BirthDate: "2000-01-01",
+	BirthTime: "12:00",
+	Timezone:  "Europe/London",
+	Latitude:  52,
+	Longitude: 4,
+}
+
+const j2000JD = 2451545.0
+
+// snapshotFake tabulates the HD bodies at jd, each offset from
+// base so personality and design snapshots differ.  The mean node
+// sits far from the true node: reading it would move north_node to
+// another gate.
+func snapshotFake(f *ephemeristest.Fake, jd, base float64) {
+	for i, body := range []string{"sun", "moon", "mercury", "venus", "mars",
+		"jupiter", "saturn", "uranus", "neptune", "pluto"} {
+		f.AddLongitude(body, jd, base+float64(27*i))
+	}
+	f.AddLongitude("north_node_true", jd, base+200)
+	f.AddLongitude("north_node", jd, base+230)
+	f.AddLongitude("north_node_mean", jd, base+230)
+}
+
+// TestComputeActivationsAgainstFake checks every activation is the
+// mandala mapping of the tabulated longitude, with earth and the
+// south node derived from the sun and the true node.
+func TestComputeActivationsAgainstFake(t *testing.T) {
+	const designJD = j2000JD - 88.5
+	f := &ephemeristest.Fake{}
+	snapshotFake(f, j2000JD, 3)
+	snapshotFake(f, designJD, 100)
+
+	personality, design, err := ComputeActivations(context.Background(), f, j2000, designJD)
+	if err != nil {
+		t.Fatalf("ComputeActivations: %v", err)
+	}
+	for _, snap := range []struct {
+		name string
+		base float64
+		acts []output.HDActivation
+	}{
+		{"personality", 3, personality},
+		{"design", 100, design},
+	} {
+		want := map[string]float64{
+			"sun": snap.base, "earth": snap.base + 180,
+			"north_node": snap.base + 200, "south_node": mod360(snap.base + 20),
+			"moon": snap.base + 27, "pluto": snap.base + 243,
+		}
+		if len(snap.acts) != len(canon.HDSnapshotOrder) {
+			t.Fatalf("%s: %d activations, want %d", snap.name, len(snap.acts), len(canon.HDSnapshotOrder))
+		}
+		for i, a := range snap.acts {
+			if a.ObjectID != canon.HDSnapshotOrder[i] {
+				t.Errorf("%s[%d] = %s, want %s", snap.name, i, a.ObjectID, canon.HDSnapshotOrder[i])
+			}
+			long, ok := want[a.ObjectID]
+			if !ok {
+				continue
+			}
+			if gate, line := calc.MapToGateLine(long); a.Gate != gate || a.Line != line {
+				t.Errorf("%s %s = %d.%d, want %d.%d (longitude %v)",
+					snap.name, a.ObjectID, a.Gate, a.Line, gate, line, long)
+			}
+		}
+	}
+}
+
+// TestComputeActivationsStopsOnProviderFailure: a missing body and
+// a cancelled context both abort the snapshot.
+func TestComputeActivationsStopsOnProviderFailure(t *testing.T) {
+	f := &ephemeristest.Fake{}
+	snapshotFake(f, j2000JD, 3)
+	if _, _, err := ComputeActivations(context.Background(), f, j2000, j2000JD-88); err == nil {
+		t.Error("design snapshot without samples succeeded")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := ComputeActivations(ctx, f, j2000, j2000JD); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,58 @@
+package hd
+
+import (
+	"fmt"
+
+	"mademanifest-engine/pkg/ephemeris"
+	"mademanifest-engine/pkg/hd/calc"
+	"mademanifest-engine/pkg/trinity/input"
+)
+
+// CheckEphemerisCoverage rejects payloads eph cannot compute.  Both
+// the birth JD (personality snapshot, astrology) and the earliest JD
+// the design-time solver evaluates (birth - calc.DesignLookbackDays)
+// must fall inside eph.Coverage(); for the Swiss Ephemeris that is
+// ephemeris.EphemerisCoverage(), outside which libswe would either
+// fail or silently fall back to the Moshier ephemeris, which the
+// canon does not allow.
+//
+// An out-of-range birth is structurally valid input outside the
+// engine's scope, so it is classified unsupported_input (HTTP 422)
+// on field birth_date (InputSchemaVersion trinity-v1-rev-2).  The
+// non-nil error return is reserved for engine-side failures
+// (unreadable ephemeris headers, time conversion bugs) and must
+// surface as execution_failure.
+func CheckEphemerisCoverage(eph ephemeris.Provider, p input.Payload) (*input.Rejection, error) {
+	birthJD, err := BirthJDFromPayload(p)
+	if err != nil {
+		return nil, err
+	}
+	return CheckEphemerisCoverageAt(eph, birthJD)
+}
+
+// CheckEphemerisCoverageAt is CheckEphemerisCoverage for a birth at
+// Julian Day birthJD (UT).
+func CheckEphemerisCoverageAt(eph ephemeris.Provider, birthJD float64) (*input.Rejection, error) {
+	cov, err := eph.Coverage()
+	if err != nil {
+		return nil, fmt.Errorf("ephemeris coverage: %w", err)
+	}
+	if !cov.Contains(birthJD) {
+		return &input.Rejection{
+			Type:  input.RejectUnsupported,
+			Field: "birth_date",
+			Message: fmt.Sprintf("birth moment (JD %.6f) is outside the ephemeris coverage %s",
+				birthJD, cov),
+		}, nil
+	}
+	if earliest := birthJD - calc.DesignLookbackDays; !cov.Contains(earliest) {
+		return &input.Rejection{
+			Type:  input.RejectUnsupported,
+			Field: "birth_date",
+			Message: fmt.Sprintf("design-time search (from JD %.6f, %.0f days before birth) "+
+				"is outside the ephemeris coverage %s",
+				earliest, calc.DesignLookbackDays, cov),
+		}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,39 @@
+package hd
+
+import (
+	"strings"
+	"testing"
+
+	"mademanifest-engine/pkg/ephemeris"
+	"mademanifest-engine/pkg/ephemeris/ephemeristest"
+	"mademanifest-engine/pkg/hd/calc"
+	"mademanifest-engine/pkg/trinity/input"
+)
+
+// TestCheckEphemerisCoverageUsesProviderWindow gates on the
+// provider's window: the birth and the design-time lookback must
+// both fall inside it.
+func TestCheckEphemerisCoverageUsesProviderWindow(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		start, end float64
+		reject     string
+	}{
+		{"covered", j2000JD - 1000, j2000JD + 1000, ""},
+		{"birth after window", j2000JD - 1000, j2000JD, "birth moment"},
+		{"lookback before window", j2000JD - calc.DesignLookbackDays, j2000JD + 1000, "design-time search"},
+	} {
+		f := &ephemeristest.Fake{Window: ephemeris.Coverage{StartJD: tc.start, EndJD: tc.end}}
+		rej, err := CheckEphemerisCoverage(f, j2000)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		switch {
+		case tc.reject == "" && rej != nil:
+			t.Errorf("%s: rejected: %s", tc.name, rej.Message)
+		case tc.reject != "" && (rej == nil || rej.Type != input.RejectUnsupported ||
+			!strings.Contains(rej.Message, tc.reject)):
+			t.Errorf("%s: rejection = %+v, want unsupported naming the %s", tc.name, rej, tc.reject)
+		}
+	}
+}
@@ -10,6 +10,7 @@
 package hd
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"time"
@@ -22,8 +23,8 @@ import (
 
 // ComputeDesignTime returns the Human Design design-time as a UTC
 // time.Time for a validated Trinity input payload.  It runs the
-// canonical bisection solver against the Swiss Ephemeris Sun
-// longitude function over Julian Day.
+// canonical bisection solver against the Sun longitude function of
+// eph (ephemeris.Default() in production) over Julian Day.
 //
 // The payload's birth_date / birth_time / timezone fields have
 // already been validated by pkg/trinity/input; we therefore treat
@@ -35,39 +36,49 @@ import (
 // policy (mean for astrology, true for human_design) does not enter
 // the design-time computation.  We therefore call ephemeris's raw
 // "sun" body lookup directly, avoiding any node policy crosstalk.
-func ComputeDesignTime(p input.Payload) (time.Time, error) {
+// An ephemeris failure inside the solver aborts it and is returned
+// wrapped, so errors.As still finds the *ephemeris.CalcError.
+// Cancelling ctx abandons the bisection at its next step; the error
+// then wraps ctx.Err().
+func ComputeDesignTime(ctx context.Context, eph ephemeris.Provider, p input.Payload) (time.Time, error) {
+	t, _, err := ComputeDesignTimeWithDiagnostics(ctx, eph, p)
+	return t, err
+}
+
+// ComputeDesignTimeWithDiagnostics is ComputeDesignTime plus the
+// solver's calc.Diagnostics, which the HTTP service exports as
+// bisection-iteration and bracket-expansion metrics.  The returned
+// design time is identical; the diagnostics never reach the
+// response envelope.
+func ComputeDesignTimeWithDiagnostics(ctx context.Context, eph ephemeris.Provider, p input.Payload) (time.Time, calc.Diagnostics, error) {
 	utcBirth, err := localToUTC(p)
 	if err != nil {
-		return time.Time{}, err
+		return time.Time{}, calc.Diagnostics{}, err
 	}
-	birthJD := astronomy.ConvertUTCToJulianDay(utcBirth)
-	sun := func(jd float64) float64 {
-		return ephemeris.GetPlanetLongAtTime(jd, "sun")
+	return ComputeDesignTimeAt(ctx, eph, astronomy.ConvertUTCToJulianDay(utcBirth))
+}
+
+// ComputeDesignTimeAt is ComputeDesignTimeWithDiagnostics for a
+// birth at Julian Day birthJD (UT).
+func ComputeDesignTimeAt(ctx context.Context, eph ephemeris.Provider, birthJD float64) (time.Time, calc.Diagnostics, error) {
+	sun := func(jd float64) (float64, error) {
+		return eph.BodyLongitude(jd, "sun")
 	}
-	designJD, err := calc.SolveDesignTime(birthJD, sun)
+	designJD, diag, err := calc.SolveDesignTimeWithDiagnostics(ctx, birthJD, sun)
 	if err != nil {
-		return time.Time{}, fmt.Errorf("solve design time: %w", err)
+		return time.Time{}, diag, fmt.Errorf("solve design time: %w", err)
 	}
-	return julianDayToUTC(designJD), nil
+	return julianDayToUTC(designJD), diag, nil
 }
 
 // localToUTC mirrors astro.localToUTC: the validator has already
-// proved these strings parse, so any error here is an engine bug.
+// resolved this local time, so any rejection here is an engine bug.
 func localToUTC(p input.Payload) (time.Time, error) {
-	loc, err := time.LoadLocation(p.Timezone)
-	if err != nil {
-		return time.Time{}, fmt.Errorf("load timezone %q: %w", p.Timezone, err)
-	}
-	var year, month, day int
-	if _, err := fmt.Sscanf(p.BirthDate, "%d-%d-%d", &year, &month, &day); err != nil {
-		return time.Time{}, fmt.Errorf("parse birth_date %q: %w", p.BirthDate, err)
-	}
-	var hour, minute int
-	if _, err := fmt.Sscanf(p.BirthTime, "%d:%d", &hour, &minute); err != nil {
-		return time.Time{}, fmt.Errorf("parse birth_time %q: %w", p.BirthTime, err)
+	t, rej := input.LocalToUTC(p)
+	if rej != nil {
+		return time.Time{}, rej
 	}
-	local := time.Date(year, time.Month(month), day, hour, minute, 0, 0, loc)
-	return local.UTC(), nil
+	return t, nil
 }
 
 // julianDayToUTC inverts astronomy.ConvertUTCToJulianDay.  The
@@ -0,0 +1,42 @@
+package hd
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"mademanifest-engine/pkg/ephemeris/ephemeristest"
+)
+
+// TestComputeDesignTimeAgainstFake tabulates a Sun advancing one
+// degree per day; the design time is then exactly 88 days before
+// birth, to the solver's one-second resolution.
+func TestComputeDesignTimeAgainstFake(t *testing.T) {
+	f := &ephemeristest.Fake{}
+	f.AddLongitude("sun", j2000JD-120, 160.5)
+	f.AddLongitude("sun", j2000JD, 280.5)
+
+	got, diag, err := ComputeDesignTimeWithDiagnostics(context.Background(), f, j2000)
+	if err != nil {
+		t.Fatalf("ComputeDesignTime: %v", err)
+	}
+	want := time.Date(1999, 10, 5, 12, 0, 0, 0, time.UTC)
+	if d := got.Sub(want); math.Abs(d.Seconds()) > 1 {
+		t.Errorf("design time = %v, want %v (off by %v)", got, want, d)
+	}
+	if diag.BracketIterations == 0 {
+		t.Error("diagnostics report no bisection iterations")
+	}
+}
+
+// TestComputeDesignTimeReportsProviderFailure: a Sun the provider
+// cannot place aborts the solve.
+func TestComputeDesignTimeReportsProviderFailure(t *testing.T) {
+	f := &ephemeristest.Fake{}
+	f.AddLongitude("sun", j2000JD-10, 270.5)
+	f.AddLongitude("sun", j2000JD, 280.5)
+	if _, err := ComputeDesignTime(context.Background(), f, j2000); err == nil {
+		t.Error("solve succeeded outside the tabulated Sun")
+	}
+}
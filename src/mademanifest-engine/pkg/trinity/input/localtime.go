@@ -0,0 +1,168 @@
+package input
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// utcOffsetRE is the canonical ±HH:MM form of the optional
+// utc_offset field (InputSchemaVersion trinity-v1-rev-1).  The
+// field only ever selects between offsets the tzdb already allows
+// for the wall-clock time, so minute precision is sufficient.
+var utcOffsetRE = regexp.MustCompile(UTCOffsetPattern)
+
+// zoneScanWindow bounds the search for UTC offsets around a wall
+// clock time.  Real offsets stay within ±14 h, so every instant that
+// could display the wall time lies inside ±48 h of it read as UTC.
+const zoneScanWindow = 48 * time.Hour
+
+// LocalToUTC resolves the payload's birth_date + birth_time in its
+// timezone to a UTC instant against the pinned tzdb.  It is the one
+// place the engine turns civil time into an instant: the validator
+// calls it so DST problems are rejected before any calculation, and
+// the astrology / Human Design pipelines call it to obtain the same
+// instant.
+//
+// time.Date silently normalises the two DST hazards, which the
+// canon's "no silent repair" rule forbids:
+//
+//   - a wall-clock time skipped by a spring-forward transition is
+//     rejected as invalid_input / nonexistent_local_time;
+//   - a wall-clock time repeated by a fall-back transition is
+//     rejected as invalid_input / ambiguous_local_time unless the
+//     payload carries a utc_offset naming one of the occurrences.
+//
+// A utc_offset that matches none of the offsets the tzdb allows for
+// the wall-clock time is rejected as utc_offset_mismatch, also when
+// the time is not ambiguous.
+func LocalToUTC(p Payload) (time.Time, *Rejection) {
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return time.Time{}, rej(RejectInvalid, "timezone",
+			"unknown IANA identifier: "+err.Error())
+	}
+	wall, err := time.Parse("2006-01-02 15:04", p.BirthDate+" "+p.BirthTime)
+	if err != nil {
+		return time.Time{}, rej(RejectInvalid, "birth_time",
+			"cannot parse birth_date + birth_time: "+err.Error())
+	}
+
+	instants := wallClockInstants(wall, loc)
+	if len(instants) == 0 {
+		return time.Time{}, &Rejection{
+			Type:  RejectInvalid,
+			Code:  CodeNonexistentLocalTime,
+			Field: "birth_time",
+			Message: fmt.Sprintf("local time %s %s does not exist in %s "+
+				"(skipped by a clock change)", p.BirthDate, p.BirthTime, p.Timezone),
+		}
+	}
+	if p.UTCOffset != "" {
+		want, err := parseUTCOffset(p.UTCOffset)
+		if err != nil {
+			return time.Time{}, rej(RejectInvalid, "utc_offset", err.Error())
+		}
+		for _, t := range instants {
+			if _, off := t.Zone(); off == want {
+				return t.UTC(), nil
+			}
+		}
+		return time.Time{}, &Rejection{
+			Type:  RejectInvalid,
+			Code:  CodeUTCOffsetMismatch,
+			Field: "utc_offset",
+			Message: fmt.Sprintf("utc_offset %s does not apply to local time %s %s in %s "+
+				"(valid: %s)", p.UTCOffset, p.BirthDate, p.BirthTime, p.Timezone,
+				offsetList(instants)),
+		}
+	}
+	if len(instants) > 1 {
+		return time.Time{}, &Rejection{
+			Type:  RejectInvalid,
+			Code:  CodeAmbiguousLocalTime,
+			Field: "birth_time",
+			Message: fmt.Sprintf("local time %s %s occurs more than once in %s "+
+				"(UTC offsets %s); supply utc_offset to select one",
+				p.BirthDate, p.BirthTime, p.Timezone, offsetList(instants)),
+		}
+	}
+	return instants[0].UTC(), nil
+}
+
+// wallClockInstants returns, in chronological order, every instant
+// whose wall clock in loc reads the year..minute fields of wall
+// (wall itself is a UTC value carrying those fields).  The result
+// is empty inside a DST gap and has two entries inside an overlap.
+func wallClockInstants(wall time.Time, loc *time.Location) []time.Time {
+	var out []time.Time
+	for _, off := range zoneOffsetsNear(wall, loc) {
+		t := wall.Add(-time.Duration(off) * time.Second).In(loc)
+		if _, got := t.Zone(); got == off {
+			out = append(out, t)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Before(out[j]) })
+	return out
+}
+
+// zoneOffsetsNear collects the distinct UTC offsets loc uses within
+// zoneScanWindow of wall, walking zone boundaries with ZoneBounds.
+func zoneOffsetsNear(wall time.Time, loc *time.Location) []int {
+	seen := map[int]bool{}
+	var offsets []int
+	stop := wall.Add(zoneScanWindow)
+	for t := wall.Add(-zoneScanWindow).In(loc); ; {
+		if _, off := t.Zone(); !seen[off] {
+			seen[off] = true
+			offsets = append(offsets, off)
+		}
+		_, end := t.ZoneBounds()
+		if end.IsZero() || end.After(stop) {
+			return offsets
+		}
+		t = end
+	}
+}
+
+// parseUTCOffset converts a ±HH:MM string into seconds east of UTC.
+func parseUTCOffset(s string) (int, error) {
+	if !utcOffsetRE.MatchString(s) {
+		return 0, fmt.Errorf("must match ±HH:MM (e.g. +02:00)")
+	}
+	var hh, mm int
+	if _, err := fmt.Sscanf(s[1:], "%d:%d", &hh, &mm); err != nil {
+		return 0, fmt.Errorf("cannot parse offset: %v", err)
+	}
+	secs := hh*3600 + mm*60
+	if s[0] == '-' {
+		secs = -secs
+	}
+	return secs, nil
+}
+
+// formatUTCOffset renders seconds east of UTC as ±HH:MM, adding :SS
+// for the sub-minute local-mean-time offsets of historical zones.
+func formatUTCOffset(secs int) string {
+	sign := '+'
+	if secs < 0 {
+		sign, secs = '-', -secs
+	}
+	s := fmt.Sprintf("%c%02d:%02d", sign, secs/3600, secs/60%60)
+	if secs%60 != 0 {
+		s += fmt.Sprintf(":%02d", secs%60)
+	}
+	return s
+}
+
+// offsetList renders the UTC offsets of instants for diagnostics.
+func offsetList(instants []time.Time) string {
+	parts := make([]string, len(instants))
+	for i, t := range instants {
+		_, off := t.Zone()
+		parts[i] = formatUTCOffset(off)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,137 @@
+package input
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// dstPayload builds a canonical payload around the given local
+// time; utcOffset is omitted when empty.
+func dstPayload(date, clock, zone, utcOffset string) []byte {
+	m := map[string]any{
+		"birth_date": date,
+		"birth_time": clock,
+		"timezone":   zone,
+		"latitude":   51.9167,
+		"longitude":  4.4,
+	}
+	if utcOffset != "" {
+		m["utc_offset"] = utcOffset
+	}
+	raw, _ := json.Marshal(m)
+	return raw
+}
+
+// TestValidateRejectsDSTHazards pins the classification of the two
+// DST hazards time.Date would otherwise normalise silently, for the
+// Europe/Amsterdam and America/New_York 1990 transitions, plus the
+// utc_offset mismatch.
+func TestValidateRejectsDSTHazards(t *testing.T) {
+	cases := []struct {
+		name                      string
+		date, clock, zone, offset string
+		wantCode                  RejectionCode
+		wantField                 string
+	}{
+		{"amsterdam spring gap", "1990-03-25", "02:30", "Europe/Amsterdam", "",
+			CodeNonexistentLocalTime, "birth_time"},
+		{"amsterdam spring gap with offset", "1990-03-25", "02:00", "Europe/Amsterdam", "+01:00",
+			CodeNonexistentLocalTime, "birth_time"},
+		{"amsterdam autumn overlap", "1990-09-30", "02:30", "Europe/Amsterdam", "",
+			CodeAmbiguousLocalTime, "birth_time"},
+		{"new york spring gap", "1990-04-01", "02:59", "America/New_York", "",
+			CodeNonexistentLocalTime, "birth_time"},
+		{"new york autumn overlap", "1990-10-28", "01:00", "America/New_York", "",
+			CodeAmbiguousLocalTime, "birth_time"},
+		{"new york overlap wrong offset", "1990-10-28", "01:30", "America/New_York", "-06:00",
+			CodeUTCOffsetMismatch, "utc_offset"},
+		{"unambiguous time wrong offset", "1990-04-09", "18:04", "Europe/Amsterdam", "+01:00",
+			CodeUTCOffsetMismatch, "utc_offset"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, r := Validate(dstPayload(tc.date, tc.clock, tc.zone, tc.offset))
+			if r == nil {
+				t.Fatalf("expected rejection, got payload %+v", p)
+			}
+			if r.Type != RejectInvalid || r.Code != tc.wantCode || r.Field != tc.wantField {
+				t.Errorf("rejection = %s/%s on %q, want %s/%s on %q (msg: %s)",
+					r.Type, r.Code, r.Field, RejectInvalid, tc.wantCode, tc.wantField, r.Message)
+			}
+			if r.Message == "" {
+				t.Error("rejection message is empty")
+			}
+		})
+	}
+}
+
+// TestLocalToUTCResolvesTransitions checks the instants on either
+// side of each transition and the utc_offset selection inside an
+// overlap.  The minutes right before a gap and right after an
+// overlap exist exactly once and must not be flagged.
+func TestLocalToUTCResolvesTransitions(t *testing.T) {
+	cases := []struct {
+		name                      string
+		date, clock, zone, offset string
+		want                      string
+	}{
+		{"amsterdam before gap", "1990-03-25", "01:59", "Europe/Amsterdam", "", "1990-03-25T00:59:00Z"},
+		{"amsterdam after gap", "1990-03-25", "03:00", "Europe/Amsterdam", "", "1990-03-25T01:00:00Z"},
+		{"amsterdam overlap summer", "1990-09-30", "02:30", "Europe/Amsterdam", "+02:00", "1990-09-30T00:30:00Z"},
+		{"amsterdam overlap winter", "1990-09-30", "02:30", "Europe/Amsterdam", "+01:00", "1990-09-30T01:30:00Z"},
+		{"amsterdam after overlap", "1990-09-30", "03:00", "Europe/Amsterdam", "", "1990-09-30T02:00:00Z"},
+		{"new york overlap daylight", "1990-10-28", "01:30", "America/New_York", "-04:00", "1990-10-28T05:30:00Z"},
+		{"new york overlap standard", "1990-10-28", "01:30", "America/New_York", "-05:00", "1990-10-28T06:30:00Z"},
+		{"new york after overlap", "1990-10-28", "02:00", "America/New_York", "", "1990-10-28T07:00:00Z"},
+		{"unambiguous with matching offset", "1990-04-09", "18:04", "Europe/Amsterdam", "+02:00", "1990-04-09T16:04:00Z"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, r := Validate(dstPayload(tc.date, tc.clock, tc.zone, tc.offset))
+			if r != nil {
+				t.Fatalf("Validate rejected: %v", r)
+			}
+			got, r := LocalToUTC(p)
+			if r != nil {
+				t.Fatalf("LocalToUTC rejected: %v", r)
+			}
+			if s := got.Format(time.RFC3339); s != tc.want {
+				t.Errorf("UTC = %s, want %s", s, tc.want)
+			}
+		})
+	}
+}
+
+// TestValidateRejectsMalformedUTCOffset covers the field-level
+// checks on the optional utc_offset.
+func TestValidateRejectsMalformedUTCOffset(t *testing.T) {
+	for _, raw := range []string{
+		`"+2"`, `"+02:00:00"`, `"02:00"`, `"+24:00"`, `"Z"`, `""`, `2`,
+	} {
+		payload := `{"birth_date":"1990-04-09","birth_time":"18:04",` +
+			`"timezone":"Europe/Amsterdam","latitude":51.9167,"longitude":4.4,` +
+			`"utc_offset":` + raw + `}`
+		_, r := Validate([]byte(payload))
+		if r == nil || r.Type != RejectInvalid || r.Field != "utc_offset" {
+			t.Errorf("utc_offset %s: rejection = %v, want invalid_input on utc_offset", raw, r)
+		}
+	}
+}
+
+// TestFormatUTCOffset pins the diagnostic offset rendering,
+// including sub-minute historical offsets.
+func TestFormatUTCOffset(t *testing.T) {
+	cases := map[int]string{
+		0:      "+00:00",
+		7200:   "+02:00",
+		-18000: "-05:00",
+		19800:  "+05:30",
+		1172:   "+00:19:32", // Amsterdam local mean time
+	}
+	for secs, want := range cases {
+		if got := formatUTCOffset(secs); got != want {
+			t.Errorf("formatUTCOffset(%d) = %q, want %q", secs, got, want)
+		}
+	}
+}
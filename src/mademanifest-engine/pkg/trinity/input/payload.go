@@ -3,11 +3,11 @@
 // validator (Validate) that rejects any input outside the canon's
 // in-scope shape with one of three classifications:
 //
-//   incomplete_input   – a required field is missing.
-//   invalid_input      – a present field has the wrong type, format,
-//                        range, or shape (numeric-as-string included).
-//   unsupported_input  – the input is structurally valid but outside
-//                        Trinity v1 supported scope (A5 RESOLVED).
+//	incomplete_input   – a required field is missing.
+//	invalid_input      – a present field has the wrong type, format,
+//	                     range, or shape (numeric-as-string included).
+//	unsupported_input  – the input is structurally valid but outside
+//	                     Trinity v1 supported scope (A5 RESOLVED).
 //
 // Canon source: specifications/trinity/trinity.org §"Input Contract"
 // lines 155-220, plus Documents 04 and 09.  The "no silent repair"
@@ -15,15 +15,19 @@
 // truncate, default, or alias-resolve.
 package input
 
-// Payload is the canonical Trinity v1 input.  All five fields are
-// required; v1 has no optional canonical input fields.
+// Payload is the canonical Trinity v1 input.  The first five fields
+// are required.  UTCOffset is the one optional field (added in
+// InputSchemaVersion trinity-v1-rev-1): it disambiguates a birth
+// time repeated by a DST fall-back transition and is empty when
+// absent.  See LocalToUTC.
 //
 // JSON tag values are part of the input contract and must not change
 // without an InputSchemaVersion bump.
 type Payload struct {
-	BirthDate string  `json:"birth_date"` // YYYY-MM-DD (Gregorian)
-	BirthTime string  `json:"birth_time"` // HH:MM (24-hour, minute precision)
-	Timezone  string  `json:"timezone"`   // IANA Area/Location identifier
-	Latitude  float64 `json:"latitude"`   // decimal degrees, [-90.0, 90.0]
-	Longitude float64 `json:"longitude"`  // decimal degrees, [-180.0, 180.0]
+	BirthDate string  `json:"birth_date"`           // YYYY-MM-DD (Gregorian)
+	BirthTime string  `json:"birth_time"`           // HH:MM (24-hour, minute precision)
+	Timezone  string  `json:"timezone"`             // IANA Area/Location identifier
+	Latitude  float64 `json:"latitude"`             // decimal degrees, [-90.0, 90.0]
+	Longitude float64 `json:"longitude"`            // decimal degrees, [-180.0, 180.0]
+	UTCOffset string  `json:"utc_offset,omitempty"` // optional ±HH:MM
 }
@@ -14,16 +14,50 @@ const (
 	RejectUnsupported RejectionType = "unsupported_input"
 )
 
+// RejectionCode refines a RejectionType for cases clients must be
+// able to tell apart without parsing Message.  It is emitted as the
+// envelope's error_code, which D23 makes authoritative alongside
+// error_type.  Most rejections carry no code.
+type RejectionCode string
+
+const (
+	// CodeNonexistentLocalTime: the wall-clock birth time falls in a
+	// DST gap (spring forward) and never happened in the timezone.
+	CodeNonexistentLocalTime RejectionCode = "nonexistent_local_time"
+	// CodeAmbiguousLocalTime: the wall-clock birth time falls in a
+	// DST overlap (fall back) and no utc_offset selects one
+	// occurrence.
+	CodeAmbiguousLocalTime RejectionCode = "ambiguous_local_time"
+	// CodeUTCOffsetMismatch: utc_offset names an offset the timezone
+	// does not use at the wall-clock birth time.
+	CodeUTCOffsetMismatch RejectionCode = "utc_offset_mismatch"
+	// CodePolarLatitude: the birth place lies inside a polar
+	// circle, where the canonical Placidus house system is
+	// undefined.  Emitted by astro.CheckHouseSystem, not by
+	// Validate (InputSchemaVersion trinity-v1-rev-3).
+	CodePolarLatitude RejectionCode = "polar_latitude"
+)
+
+// RejectionCodes lists every RejectionCode the engine emits.
+var RejectionCodes = []RejectionCode{
+	CodeNonexistentLocalTime,
+	CodeAmbiguousLocalTime,
+	CodeUTCOffsetMismatch,
+	CodePolarLatitude,
+}
+
 // Rejection is the structured failure value returned by Validate.
 //
 // Field names the offending JSON key (or the empty string when the
 // rejection applies to the payload as a whole, e.g. malformed JSON
-// or non-object root).  Message is a developer-facing diagnostic
+// or non-object root).  Code is the optional error_code refinement
+// (empty for most rejections).  Message is a developer-facing diagnostic
 // string; callers may pass it through to the response envelope's
 // "message" field.  A4 (RESOLVED, Document 12 D23): the Message
 // text is informational – fixtures match Type (and Field) only.
 type Rejection struct {
 	Type    RejectionType
+	Code    RejectionCode
 	Field   string
 	Message string
 }
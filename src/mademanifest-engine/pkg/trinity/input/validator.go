@@ -21,15 +21,37 @@ var requiredFields = []string{
 	"longitude",
 }
 
+// optionalFields lists the canonical input fields a payload may
+// omit.  utc_offset was introduced with InputSchemaVersion
+// trinity-v1-rev-1 to disambiguate DST fall-back overlaps.
+var optionalFields = []string{
+	"utc_offset",
+}
+
+// Format patterns and ranges of the canonical fields.  Exported so
+// the published OpenAPI contract (pkg/httpservice) states exactly
+// the rules this validator applies.  The patterns are necessary,
+// not sufficient: a date must also exist in the Gregorian calendar
+// and a timezone must be a canonical zone1970.tab identifier.
+const (
+	BirthDatePattern = `^\d{4}-\d{2}-\d{2}$`
+	BirthTimePattern = `^([01]\d|2[0-3]):[0-5]\d$`
+	TimezonePattern  = `^[A-Za-z][A-Za-z0-9_+\-]*(?:/[A-Za-z0-9_+\-]+)+$`
+	UTCOffsetPattern = `^[+-]([01]\d|2[0-3]):[0-5]\d$`
+
+	LatitudeMin, LatitudeMax   = -90.0, 90.0
+	LongitudeMin, LongitudeMax = -180.0, 180.0
+)
+
 var (
 	// dateRE strictly accepts YYYY-MM-DD with four-digit year.  Rules
 	// like "1990-13-01" still pass the regex but get rejected by the
 	// time.Parse Gregorian check below.
-	dateRE = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	dateRE = regexp.MustCompile(BirthDatePattern)
 
 	// timeMinuteRE is the canonical HH:MM, 24-hour, minute precision
 	// (trinity.org line 161).  HH=00..23, MM=00..59.
-	timeMinuteRE = regexp.MustCompile(`^([01]\d|2[0-3]):[0-5]\d$`)
+	timeMinuteRE = regexp.MustCompile(BirthTimePattern)
 
 	// timeWithSecondsRE catches HH:MM:SS or HH:MM:SS.fraction.
 	// A5 (RESOLVED — Document 04 strict input contract + Document 12
@@ -41,7 +63,7 @@ var (
 	// ianaCanonicalShapeRE is a coarse shape check requiring at
 	// least one slash.  It rules out abbreviations like "CET", "EST"
 	// (per trinity.org line 110-111: "rejects abbreviations").
-	ianaCanonicalShapeRE = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_+\-]*(?:/[A-Za-z0-9_+\-]+)+$`)
+	ianaCanonicalShapeRE = regexp.MustCompile(TimezonePattern)
 )
 
 // Legacy fallback: known IANA *link* prefixes rejected when the
@@ -67,10 +89,10 @@ var ianaLinkPrefixes = []string{
 
 // Validate parses raw JSON, applies every Trinity v1 input rule
 // (presence, strict numeric typing, format, range, IANA canonical
-// zone, no unknown fields), and returns either a fully populated
-// Payload or a non-nil Rejection.  The two return values are
-// mutually exclusive: an error implies the Payload is the zero
-// value, and vice versa.
+// zone, no unknown fields, a local time that exists exactly once),
+// and returns either a fully populated Payload or a non-nil
+// Rejection.  The two return values are mutually exclusive: an
+// error implies the Payload is the zero value, and vice versa.
 func Validate(raw []byte) (Payload, *Rejection) {
 	// First pass: decode into a map of raw messages so we can
 	// distinguish "missing" (key absent) from "wrong type" (key
@@ -100,15 +122,19 @@ func Validate(raw []byte) (Payload, *Rejection) {
 	// Reject any unknown fields up front – the canon forbids extra
 	// fields silently affecting results, so we surface them as
 	// invalid_input rather than swallowing them.
-	known := make(map[string]bool, len(requiredFields))
+	known := make(map[string]bool, len(requiredFields)+len(optionalFields))
 	for _, f := range requiredFields {
 		known[f] = true
 	}
+	for _, f := range optionalFields {
+		known[f] = true
+	}
 	for k := range m {
 		if !known[k] {
 			return Payload{}, rej(RejectInvalid, k,
 				"unknown field; canonical payload has exactly "+
-					strings.Join(requiredFields, ", "))
+					strings.Join(requiredFields, ", ")+
+					" plus optional "+strings.Join(optionalFields, ", "))
 		}
 	}
 
@@ -140,10 +166,24 @@ func Validate(raw []byte) (Payload, *Rejection) {
 	if r := validateTimezone(p.Timezone); r != nil {
 		return Payload{}, r
 	}
-	if r := decodeNumber(m["latitude"], "latitude", -90.0, 90.0, &p.Latitude); r != nil {
+	if r := decodeNumber(m["latitude"], "latitude", LatitudeMin, LatitudeMax, &p.Latitude); r != nil {
 		return Payload{}, r
 	}
-	if r := decodeNumber(m["longitude"], "longitude", -180.0, 180.0, &p.Longitude); r != nil {
+	if r := decodeNumber(m["longitude"], "longitude", LongitudeMin, LongitudeMax, &p.Longitude); r != nil {
+		return Payload{}, r
+	}
+	if raw, ok := m["utc_offset"]; ok {
+		if r := decodeString(raw, "utc_offset", &p.UTCOffset); r != nil {
+			return Payload{}, r
+		}
+		if _, err := parseUTCOffset(p.UTCOffset); err != nil {
+			return Payload{}, rej(RejectInvalid, "utc_offset", err.Error())
+		}
+	}
+
+	// Cross-field check: the local birth time must name exactly one
+	// instant in the timezone (no DST gap, overlap resolved).
+	if _, r := LocalToUTC(p); r != nil {
 		return Payload{}, r
 	}
 	return p, nil
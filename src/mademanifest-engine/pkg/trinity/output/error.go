@@ -19,15 +19,28 @@ const (
 	ErrorExecutionFailure = "execution_failure"
 )
 
+// ErrorTypes lists the canonical error_type values in the order
+// above.
+var ErrorTypes = []string{
+	ErrorInvalidInput,
+	ErrorIncompleteInput,
+	ErrorUnsupportedInput,
+	ErrorCanonConflict,
+	ErrorExecutionFailure,
+}
+
 // Error is the nested object inside an ErrorEnvelope.  Type is one
-// of the canonical error_type constants; Message is a developer-
-// facing string.  A4 (RESOLVED, Document 12 D23): fixtures compare
+// of the canonical error_type constants; Code is an optional
+// error_code refining it (e.g. nonexistent_local_time, see
+// input.RejectionCode) and is omitted when empty; Message is a
+// developer-facing string.  A4 (RESOLVED, Document 12 D23): fixtures compare
 // error_type (and, when pinned, error_code) only – the Message
 // field must be present where required but its wording is not
 // authoritative for fixture exactness unless a future canon
 // revision introduces a formal message catalogue.
 type Error struct {
 	Type    string `json:"error_type"`
+	Code    string `json:"error_code,omitempty"`
 	Message string `json:"message"`
 }
 
@@ -80,3 +93,33 @@ func StatusCodeForErrorType(errType string) int {
 		return http.StatusInternalServerError
 	}
 }
+
+// Process exit codes of cmd/manifest, one per class of HTTP status
+// the service answers with, so a script can branch on the outcome
+// without parsing the envelope.
+const (
+	ExitSuccess     = 0 // 200: success envelope
+	ExitFailure     = 1 // 500: execution_failure, canon_conflict
+	ExitInvalid     = 2 // 400: invalid_input, incomplete_input
+	ExitUnsupported = 3 // 422, 413: unsupported_input
+	ExitUnavailable = 4 // 503: deadline exceeded or cancelled; retry
+)
+
+// ExitCodeForStatus maps the HTTP status of an envelope (the one
+// StatusCodeForErrorType assigns, or 200 / 413 / 503) to the
+// matching exit code.  Unknown statuses map to ExitFailure, the
+// same safe default as StatusCodeForErrorType.
+func ExitCodeForStatus(status int) int {
+	switch status {
+	case http.StatusOK:
+		return ExitSuccess
+	case http.StatusBadRequest:
+		return ExitInvalid
+	case http.StatusUnprocessableEntity, http.StatusRequestEntityTooLarge:
+		return ExitUnsupported
+	case http.StatusServiceUnavailable:
+		return ExitUnavailable
+	default:
+		return ExitFailure
+	}
+}
@@ -117,6 +117,32 @@ func TestStatusCodeForErrorTypeMatchesPhase3Policy(t *testing.T) {
 	}
 }
 
+// TestExitCodeForStatusFollowsErrorType: every canonical error_type
+// exits with its own class, and success alone exits 0.
+func TestExitCodeForStatusFollowsErrorType(t *testing.T) {
+	for errType, want := range map[string]int{
+		ErrorInvalidInput:     ExitInvalid,
+		ErrorIncompleteInput:  ExitInvalid,
+		ErrorUnsupportedInput: ExitUnsupported,
+		ErrorCanonConflict:    ExitFailure,
+		ErrorExecutionFailure: ExitFailure,
+	} {
+		if got := ExitCodeForStatus(StatusCodeForErrorType(errType)); got != want {
+			t.Errorf("%s: exit code %d, want %d", errType, got, want)
+		}
+	}
+	for status, want := range map[int]int{
+		http.StatusOK:                    ExitSuccess,
+		http.StatusRequestEntityTooLarge: ExitUnsupported,
+		http.StatusServiceUnavailable:    ExitUnavailable,
+		http.StatusTeapot:                ExitFailure,
+	} {
+		if got := ExitCodeForStatus(status); got != want {
+			t.Errorf("status %d: exit code %d, want %d", status, got, want)
+		}
+	}
+}
+
 // TestCanonicalErrorTypeConstants guards against accidental drift
 // of the error_type literal strings.  These strings appear verbatim
 // in fixtures and in API consumers; any rename is a contract break.
@@ -0,0 +1,59 @@
+package output
+
+// Section names a top-level calculation section of the success
+// envelope.  The values are the envelope's JSON keys, so a section
+// selector reads the same as the response it produces.
+type Section string
+
+const (
+	SectionAstrology   Section = "astrology"
+	SectionHumanDesign Section = "human_design"
+	SectionGeneKeys    Section = "gene_keys"
+)
+
+// SectionOrder is the canonical order of the calculation sections,
+// matching their declaration order in SuccessEnvelope.
+var SectionOrder = []Section{SectionAstrology, SectionHumanDesign, SectionGeneKeys}
+
+// PartialSuccessEnvelope is the success response when the caller
+// selected a strict subset of the calculation sections.  The
+// sections field lists the selected sections in SectionOrder and is
+// what marks the envelope as partial; the unselected sections are
+// absent rather than zero-valued, so a consumer can never mistake a
+// skipped section for a computed one.
+//
+// Every present section is byte-identical to the same section of
+// the full SuccessEnvelope for the same payload.  A request that
+// selects all three sections is answered with the plain
+// SuccessEnvelope, never with this type.
+type PartialSuccessEnvelope struct {
+	Status      string          `json:"status"` // always "success"
+	Metadata    Metadata        `json:"metadata"`
+	Sections    []Section       `json:"sections"`
+	InputEcho   InputEcho       `json:"input_echo"`
+	Astrology   *Astrology      `json:"astrology,omitempty"`
+	HumanDesign *HumanDesignOut `json:"human_design,omitempty"`
+	GeneKeys    *GeneKeysOut    `json:"gene_keys,omitempty"`
+}
+
+// Partial projects a success envelope onto the given sections,
+// which must be in SectionOrder without duplicates.
+func (e SuccessEnvelope) Partial(sections []Section) PartialSuccessEnvelope {
+	p := PartialSuccessEnvelope{
+		Status:    e.Status,
+		Metadata:  e.Metadata,
+		Sections:  sections,
+		InputEcho: e.InputEcho,
+	}
+	for _, s := range sections {
+		switch s {
+		case SectionAstrology:
+			p.Astrology = &e.Astrology
+		case SectionHumanDesign:
+			p.HumanDesign = &e.HumanDesign
+		case SectionGeneKeys:
+			p.GeneKeys = &e.GeneKeys
+		}
+	}
+	return p
+}
@@ -5,6 +5,18 @@ import (
 	"mademanifest-engine/pkg/trinity/input"
 )
 
+// EchoInput is the input_echo block of a validated payload.
+func EchoInput(p input.Payload) InputEcho {
+	return InputEcho{
+		BirthDate: p.BirthDate,
+		BirthTime: p.BirthTime,
+		Timezone:  p.Timezone,
+		Latitude:  Longitude(p.Latitude),
+		Longitude: Longitude(p.Longitude),
+		UTCOffset: p.UTCOffset,
+	}
+}
+
 // NewPlaceholderSuccess builds a canonically-shaped SuccessEnvelope
 // from a validated Trinity payload.  It fills in every structural
 // field that Phase 3 can determine without running the calculation
@@ -38,13 +50,7 @@ func NewPlaceholderSuccess(p input.Payload) SuccessEnvelope {
 	return SuccessEnvelope{
 		Status:    StatusSuccess,
 		Metadata:  CurrentMetadata(),
-		InputEcho: InputEcho{
-			BirthDate: p.BirthDate,
-			BirthTime: p.BirthTime,
-			Timezone:  p.Timezone,
-			Latitude:  Longitude(p.Latitude),
-			Longitude: Longitude(p.Longitude),
-		},
+		InputEcho: EchoInput(p),
 		Astrology: Astrology{
 			System: AstroSystem{
 				Zodiac:      "tropical",
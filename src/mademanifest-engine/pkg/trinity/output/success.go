@@ -33,13 +33,16 @@ type SuccessEnvelope struct {
 // InputEcho re-emits the canonical input fields.  Per
 // trinity.org §"Input Echo" lines 464-471 only the five canonical
 // payload fields are echoed – nothing else from the original
-// request body may leak into the response.
+// request body may leak into the response.  The optional
+// utc_offset (InputSchemaVersion trinity-v1-rev-1) is echoed only
+// when the payload supplied it.
 type InputEcho struct {
 	BirthDate string    `json:"birth_date"`
 	BirthTime string    `json:"birth_time"`
 	Timezone  string    `json:"timezone"`
 	Latitude  Longitude `json:"latitude"`
 	Longitude Longitude `json:"longitude"`
+	UTCOffset string    `json:"utc_offset,omitempty"`
 }
 
 // Astrology is the astrology section of the success envelope.
@@ -41,6 +41,28 @@ func TestSuccessEnvelopeTopLevelKeyOrder(t *testing.T) {
 	assertKeyOrder(t, raw, wantOrder)
 }
 
+// TestPartialSuccessEnvelopeKeyOrder pins the partial envelope's
+// root: the sections marker follows metadata, and only the selected
+// sections appear, in SuccessEnvelope order.
+func TestPartialSuccessEnvelopeKeyOrder(t *testing.T) {
+	env := NewPlaceholderSuccess(canonicalPayload)
+	raw, err := json.Marshal(env.Partial([]Section{SectionAstrology, SectionGeneKeys}))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	assertKeyOrder(t, raw, []string{
+		`"status"`,
+		`"metadata"`,
+		`"sections"`,
+		`"input_echo"`,
+		`"astrology"`,
+		`"gene_keys"`,
+	})
+	if bytes.Contains(raw, []byte(`"human_design":`)) {
+		t.Errorf("unselected human_design present: %s", raw)
+	}
+}
+
 // TestSuccessEnvelopeNestedKeyOrder pins the canonical sub-section
 // orderings inside metadata, input_echo, astrology.system, and
 // gene_keys.activations.
@@ -0,0 +1,335 @@
+// Package sensitivity reports how close each computed position of a
+// chart lies to the boundary that decides its classification: the
+// sign and house of every astrology object, the sign of both angles,
+// and the gate and line of every personality and design activation.
+// A position a few arcminutes from a boundary changes class when the
+// recorded birth minute is off by a little, so the report lets
+// support tell a customer whether their chart is sensitive to it.
+//
+// It is the engine's version of the hand-made boundary-distances.txt
+// of the Schiedam baseline review
+// (src/doc/schiedam-baseline-review-2026-04-30): margins use the
+// 30° signs of astro.SignFor, the chart's own Placidus cusps
+// (astro.HouseFor, D21) and canon.GateWidthDeg / canon.LineWidthDeg
+// (calc.MapToGateLine).  The report is diagnostic output and never
+// part of the canonical envelope.
+package sensitivity
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"mademanifest-engine/pkg/canon"
+	"mademanifest-engine/pkg/hd/calc"
+	"mademanifest-engine/pkg/trinity/astro"
+	"mademanifest-engine/pkg/trinity/output"
+)
+
+// The boundary classes, in report order.
+const (
+	ClassSign  = "sign"
+	ClassHouse = "house"
+	ClassGate  = "gate"
+	ClassLine  = "line"
+)
+
+// Classes lists the boundary classes in report order.
+var Classes = []string{ClassSign, ClassHouse, ClassGate, ClassLine}
+
+// Thresholds are the margins, in degrees, under which a position is
+// flagged as near its boundary, per class.
+type Thresholds struct {
+	Sign  float64 `json:"sign"`
+	House float64 `json:"house"`
+	Gate  float64 `json:"gate"`
+	Line  float64 `json:"line"`
+}
+
+// DefaultThresholds are the canon-stress windows of the Schiedam
+// review: sign and house 0.5°, gate 0.1°, line 0.02°.  For scale,
+// one minute of birth time moves the Moon about 0.01° and the house
+// cusps about 0.25°.
+var DefaultThresholds = Thresholds{Sign: 0.5, House: 0.5, Gate: 0.1, Line: 0.02}
+
+func (t Thresholds) of(class string) float64 {
+	switch class {
+	case ClassSign:
+		return t.Sign
+	case ClassHouse:
+		return t.House
+	case ClassGate:
+		return t.Gate
+	default:
+		return t.Line
+	}
+}
+
+// ParseThresholds overrides DefaultThresholds with a comma-separated
+// list of class:degrees pairs, e.g. "gate:0.2,line:0.05"; "" yields
+// the defaults.  A threshold must be a finite number of degrees in
+// [0, 180].
+func ParseThresholds(s string) (Thresholds, error) {
+	t := DefaultThresholds
+	if s == "" {
+		return t, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		class, value, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok {
+			return t, fmt.Errorf("threshold %q: want class:degrees", pair)
+		}
+		deg, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(deg) || deg < 0 || deg > 180 {
+			return t, fmt.Errorf("threshold %q: want degrees in [0, 180]", pair)
+		}
+		switch class {
+		case ClassSign:
+			t.Sign = deg
+		case ClassHouse:
+			t.House = deg
+		case ClassGate:
+			t.Gate = deg
+		case ClassLine:
+			t.Line = deg
+		default:
+			return t, fmt.Errorf("threshold %q: unknown class %q (want one of %s)",
+				pair, class, strings.Join(Classes, ", "))
+		}
+	}
+	return t, nil
+}
+
+// AstrologyMargin is one astrology object or angle with its sign and
+// house and their margins.  The angles (ascendant, midheaven) have
+// no house: the ascendant is cusp 1 and the midheaven cusp 10.
+type AstrologyMargin struct {
+	ObjectID       string           `json:"object_id"`
+	Longitude      output.Longitude `json:"longitude"`
+	Sign           string           `json:"sign"`
+	SignMarginDeg  float64          `json:"sign_margin_deg"`
+	House          int              `json:"house,omitempty"`
+	HouseMarginDeg *float64         `json:"house_margin_deg,omitempty"`
+	// Near lists the classes whose margin is under its threshold.
+	Near []string `json:"near"`
+}
+
+// ActivationMargin is one Human Design activation with its gate and
+// line and their margins.
+type ActivationMargin struct {
+	ObjectID      string           `json:"object_id"`
+	Longitude     output.Longitude `json:"longitude"`
+	Gate          int              `json:"gate"`
+	GateMarginDeg float64          `json:"gate_margin_deg"`
+	Line          int              `json:"line"`
+	LineMarginDeg float64          `json:"line_margin_deg"`
+	Near          []string         `json:"near"`
+}
+
+// Tightest is the smallest margin of one class across the chart.
+type Tightest struct {
+	Class string `json:"class"`
+	// Snapshot is "astrology" for sign and house, "personality" or
+	// "design" for gate and line.
+	Snapshot  string  `json:"snapshot"`
+	ObjectID  string  `json:"object_id"`
+	MarginDeg float64 `json:"margin_deg"`
+	Near      bool    `json:"near"`
+}
+
+// Report is the boundary-sensitivity report of one chart.
+type Report struct {
+	Thresholds  Thresholds         `json:"thresholds"`
+	Astrology   []AstrologyMargin  `json:"astrology"`
+	Personality []ActivationMargin `json:"personality"`
+	Design      []ActivationMargin `json:"design"`
+	// Tightest holds one entry per class, in Classes order.
+	Tightest []Tightest `json:"tightest"`
+	// Sensitive is true when any position is near a boundary.
+	Sensitive bool `json:"sensitive"`
+}
+
+// Compute builds the report from the chart's astrology section and
+// its personality and design snapshot longitudes
+// (hd.ActivationLongitudes), keyed by canon.HDSnapshotOrder name.
+func Compute(a output.Astrology, personality, design map[string]float64, th Thresholds) Report {
+	r := Report{
+		Thresholds:  th,
+		Astrology:   make([]AstrologyMargin, 0, len(a.Objects)+2),
+		Personality: activationMargins(personality, th),
+		Design:      activationMargins(design, th),
+	}
+
+	var cusps [12]float64
+	for i, c := range a.HouseCusps {
+		if i < 12 {
+			cusps[i] = float64(c.Longitude)
+		}
+	}
+	for _, o := range a.Objects {
+		long := float64(o.Longitude)
+		house := astro.HouseMargin(long, cusps)
+		m := AstrologyMargin{
+			ObjectID:       o.ObjectID,
+			Longitude:      o.Longitude,
+			Sign:           o.Sign,
+			SignMarginDeg:  astro.SignMargin(long),
+			House:          o.House,
+			HouseMarginDeg: &house,
+		}
+		m.Near = near(th, margin{ClassSign, m.SignMarginDeg}, margin{ClassHouse, house})
+		r.Astrology = append(r.Astrology, m)
+	}
+	for _, angle := range []struct {
+		id string
+		sl output.SignedLongitude
+	}{{"ascendant", a.Angles.Ascendant}, {"midheaven", a.Angles.Midheaven}} {
+		m := AstrologyMargin{
+			ObjectID:      angle.id,
+			Longitude:     angle.sl.Longitude,
+			Sign:          angle.sl.Sign,
+			SignMarginDeg: astro.SignMargin(float64(angle.sl.Longitude)),
+		}
+		m.Near = near(th, margin{ClassSign, m.SignMarginDeg})
+		r.Astrology = append(r.Astrology, m)
+	}
+
+	for _, class := range Classes {
+		r.Tightest = append(r.Tightest, r.tightest(class))
+	}
+	for _, t := range r.Tightest {
+		r.Sensitive = r.Sensitive || t.Near
+	}
+	return r
+}
+
+func activationMargins(longs map[string]float64, th Thresholds) []ActivationMargin {
+	out := make([]ActivationMargin, 0, len(canon.HDSnapshotOrder))
+	for _, body := range canon.HDSnapshotOrder {
+		long := longs[body]
+		gate, line := calc.MapToGateLine(long)
+		gateMargin, lineMargin := calc.GateLineMargins(long)
+		out = append(out, ActivationMargin{
+			ObjectID:      body,
+			Longitude:     output.Longitude(long),
+			Gate:          gate,
+			GateMarginDeg: gateMargin,
+			Line:          line,
+			LineMarginDeg: lineMargin,
+			Near:          near(th, margin{ClassGate, gateMargin}, margin{ClassLine, lineMargin}),
+		})
+	}
+	return out
+}
+
+type margin struct {
+	class string
+	deg   float64
+}
+
+// near returns the classes of margins under their threshold, never
+// nil.
+func near(th Thresholds, margins ...margin) []string {
+	out := []string{}
+	for _, m := range margins {
+		if m.deg < th.of(m.class) {
+			out = append(out, m.class)
+		}
+	}
+	return out
+}
+
+// tightest scans the positions that carry class; ties keep the
+// first in report order.
+func (r Report) tightest(class string) Tightest {
+	t := Tightest{Class: class, MarginDeg: math.Inf(1)}
+	consider := func(snapshot, id string, margin float64) {
+		if margin < t.MarginDeg {
+			t.Snapshot, t.ObjectID, t.MarginDeg = snapshot, id, margin
+		}
+	}
+	switch class {
+	case ClassSign, ClassHouse:
+		for _, m := range r.Astrology {
+			if class == ClassSign {
+				consider("astrology", m.ObjectID, m.SignMarginDeg)
+			} else if m.HouseMarginDeg != nil {
+				consider("astrology", m.ObjectID, *m.HouseMarginDeg)
+			}
+		}
+	default:
+		for _, snap := range []struct {
+			name    string
+			margins []ActivationMargin
+		}{{"personality", r.Personality}, {"design", r.Design}} {
+			for _, m := range snap.margins {
+				if class == ClassGate {
+					consider(snap.name, m.ObjectID, m.GateMarginDeg)
+				} else {
+					consider(snap.name, m.ObjectID, m.LineMarginDeg)
+				}
+			}
+		}
+	}
+	t.Near = t.MarginDeg < r.Thresholds.of(class)
+	return t
+}
+
+// Text renders the report as a plain-text table in the layout of
+// the Schiedam review's boundary-distances.txt.
+func (r Report) Text() string {
+	var b strings.Builder
+	b.WriteString("Distance from each position to the nearest sign / house / gate / line\n")
+	fmt.Fprintf(&b, "boundary, in degrees.  Flagged 'near': sign < %g°, house < %g°,\n",
+		r.Thresholds.Sign, r.Thresholds.House)
+	fmt.Fprintf(&b, "gate < %g°, line < %g°.\n\n", r.Thresholds.Gate, r.Thresholds.Line)
+
+	fmt.Fprintf(&b, "%-17s %11s  %-11s %10s  %5s %10s   %s\n",
+		"astrology", "longitude", "sign", "Δsign", "house", "Δhouse", "notes")
+	for _, m := range r.Astrology {
+		house, dHouse := "-", "-"
+		if m.HouseMarginDeg != nil {
+			house, dHouse = strconv.Itoa(m.House), fmt.Sprintf("%.6f", *m.HouseMarginDeg)
+		}
+		fmt.Fprintf(&b, "%-17s %11.6f  %-11s %10.6f  %5s %10s   %s\n", m.ObjectID,
+			float64(m.Longitude), m.Sign, m.SignMarginDeg, house, dHouse, notes(m.Near))
+	}
+	for _, snap := range []struct {
+		name    string
+		margins []ActivationMargin
+	}{{"personality", r.Personality}, {"design", r.Design}} {
+		fmt.Fprintf(&b, "\n%-17s %11s  %4s %10s  %4s %10s   %s\n",
+			snap.name, "longitude", "gate", "Δgate", "line", "Δline", "notes")
+		for _, m := range snap.margins {
+			fmt.Fprintf(&b, "%-17s %11.6f  %4d %10.6f  %4d %10.6f   %s\n", m.ObjectID,
+				float64(m.Longitude), m.Gate, m.GateMarginDeg, m.Line, m.LineMarginDeg, notes(m.Near))
+		}
+	}
+
+	b.WriteString("\nTightest margin per boundary class:\n")
+	for _, t := range r.Tightest {
+		flag := ""
+		if t.Near {
+			flag = "  (near)"
+		}
+		fmt.Fprintf(&b, "  %-6s %.6f°  %s %s%s\n", t.Class, t.MarginDeg, t.Snapshot, t.ObjectID, flag)
+	}
+	if r.Sensitive {
+		b.WriteString("\nSensitive: a position lies near a boundary; a small error in the\nrecorded birth time may change it.\n")
+	} else {
+		b.WriteString("\nNot sensitive: no position lies near a boundary.\n")
+	}
+	return b.String()
+}
+
+func notes(near []string) string {
+	if len(near) == 0 {
+		return "safe"
+	}
+	out := make([]string, len(near))
+	for i, class := range near {
+		out[i] = "near " + class + " cusp"
+	}
+	return strings.Join(out, ", ")
+}
@@ -0,0 +1,109 @@
+package sensitivity
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"mademanifest-engine/pkg/canon"
+	"mademanifest-engine/pkg/trinity/output"
+)
+
+func TestParseThresholds(t *testing.T) {
+	got, err := ParseThresholds("")
+	if err != nil || got != DefaultThresholds {
+		t.Errorf(`ParseThresholds("") = %+v, %v`, got, err)
+	}
+	got, err = ParseThresholds("gate:0.2, line:0")
+	want := Thresholds{Sign: 0.5, House: 0.5, Gate: 0.2, Line: 0}
+	if err != nil || got != want {
+		t.Errorf("ParseThresholds = %+v, %v; want %+v", got, err, want)
+	}
+	for _, bad := range []string{"gate", "gate:", "gate:x", "gate:-1", "gate:NaN", "gate:181", "moon:1", "gate:0.1,"} {
+		if _, err := ParseThresholds(bad); err == nil {
+			t.Errorf("ParseThresholds(%q) accepted", bad)
+		}
+	}
+}
+
+// chart is a synthetic chart: cusps every 30° from 10°, and every
+// HD body in the middle of line 3 of gate 38 except the sun, 0.01°
+// into line 4.
+func chart() (output.Astrology, map[string]float64) {
+	var a output.Astrology
+	for i := 0; i < 12; i++ {
+		a.HouseCusps = append(a.HouseCusps, output.HouseCusp{House: i + 1, Longitude: output.Longitude(10 + 30*i)})
+	}
+	a.Objects = []output.AstroObject{
+		{ObjectID: "sun", Longitude: 29.8, Sign: "aries", House: 1},    // 0.2 from taurus, 10.2 from cusp 2
+		{ObjectID: "moon", Longitude: 100.3, Sign: "cancer", House: 4}, // 0.3 from cusp 4
+		{ObjectID: "mars", Longitude: 55, Sign: "taurus", House: 2},    // safe
+	}
+	a.Angles.Ascendant = output.SignedLongitude{Longitude: 10, Sign: "aries"}
+	a.Angles.Midheaven = output.SignedLongitude{Longitude: 270.1, Sign: "capricorn"}
+
+	longs := map[string]float64{}
+	for _, body := range canon.HDSnapshotOrder {
+		longs[body] = canon.MandalaAnchorDeg + 2.5*canon.LineWidthDeg
+	}
+	longs["sun"] = canon.MandalaAnchorDeg + 3*canon.LineWidthDeg + 0.01
+	return a, longs
+}
+
+func TestComputeFlagsNearBoundaries(t *testing.T) {
+	a, longs := chart()
+	r := Compute(a, longs, longs, DefaultThresholds)
+
+	near := map[string][]string{}
+	for _, m := range r.Astrology {
+		near[m.ObjectID] = m.Near
+	}
+	wantNear := map[string][]string{
+		"sun": {"sign"}, "moon": {"house"}, "mars": {}, "ascendant": {}, "midheaven": {"sign"},
+	}
+	if !reflect.DeepEqual(near, wantNear) {
+		t.Errorf("astrology near = %v, want %v", near, wantNear)
+	}
+	asc := r.Astrology[3]
+	if asc.ObjectID != "ascendant" || asc.House != 0 || asc.HouseMarginDeg != nil {
+		t.Errorf("ascendant row = %+v, want no house", asc)
+	}
+	if sun := r.Astrology[0]; math.Abs(*sun.HouseMarginDeg-10.2) > 1e-9 || math.Abs(sun.SignMarginDeg-0.2) > 1e-9 {
+		t.Errorf("sun margins = %v, %v", sun.SignMarginDeg, *sun.HouseMarginDeg)
+	}
+
+	for _, m := range r.Personality {
+		wantLine := 0.5 * canon.LineWidthDeg
+		wantNear := []string{}
+		if m.ObjectID == "sun" {
+			wantLine, wantNear = 0.01, []string{"line"}
+		}
+		if math.Abs(m.LineMarginDeg-wantLine) > 1e-9 || !reflect.DeepEqual(m.Near, wantNear) {
+			t.Errorf("%s: line margin %v near %v, want %v %v", m.ObjectID, m.LineMarginDeg, m.Near, wantLine, wantNear)
+		}
+		if m.Gate != 38 {
+			t.Errorf("%s: gate %d, want 38", m.ObjectID, m.Gate)
+		}
+	}
+
+	var classes []string
+	for _, tt := range r.Tightest {
+		classes = append(classes, tt.Class+"@"+tt.Snapshot+"."+tt.ObjectID)
+	}
+	want := []string{"sign@astrology.midheaven", "house@astrology.moon", "gate@personality.earth", "line@personality.sun"}
+	if !reflect.DeepEqual(classes, want) {
+		t.Errorf("tightest = %v, want %v", classes, want)
+	}
+	if !r.Sensitive || !r.Tightest[0].Near || r.Tightest[2].Near {
+		t.Errorf("Sensitive = %v, tightest %+v", r.Sensitive, r.Tightest)
+	}
+
+	r = Compute(a, longs, longs, Thresholds{})
+	if r.Sensitive {
+		t.Errorf("zero thresholds flagged %+v", r.Tightest)
+	}
+	if text := r.Text(); !strings.Contains(text, "Not sensitive") || !strings.Contains(text, "ascendant") {
+		t.Errorf("Text:\n%s", text)
+	}
+}
@@ -0,0 +1,308 @@
+// Package window answers the birth-time uncertainty question: over
+// a window of birth instants around the stated birth time, which
+// distinct charts does the engine produce, and where does one give
+// way to the next?
+//
+// A chart (Chart) is the discrete part of a Trinity envelope – the
+// sign and house of every astrology object, the sign of both
+// angles, the gate and line of every personality and design
+// activation, and the channels, definition, type, authority and
+// profile derived from them.  It changes only where some position
+// crosses a sign, house, gate or line boundary, so the window is
+// cut into sub-windows at those instants.
+//
+// The instants are found by bisection on the chart rather than by
+// stepping minute by minute: the window is split into probe
+// intervals of at most Probe, an interval whose two ends give the
+// same chart is taken to hold none, and an interval whose ends
+// differ is halved until every change is pinned to Resolution.
+// This finds every change as long as no position crosses a boundary
+// and back within one probe interval.  Houses and angle signs take
+// about a sidereal day to come round, and the gates and lines move
+// monotonically except at a planet's station, so the only miss is a
+// body turning direction within minutes of sitting exactly on a
+// boundary.
+package window
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"mademanifest-engine/pkg/astronomy"
+	"mademanifest-engine/pkg/ephemeris"
+	"mademanifest-engine/pkg/hd/structure"
+	"mademanifest-engine/pkg/trinity/astro"
+	"mademanifest-engine/pkg/trinity/hd"
+)
+
+// Probe is the longest interval taken to hold no change when its
+// two ends give the same chart.  Resolution is the precision of a
+// change instant: the scan computes charts at whole seconds from
+// the window start.
+const (
+	Probe      = time.Hour
+	Resolution = time.Second
+)
+
+// Chart is the discrete chart at one instant, keyed by field path:
+//
+//	astrology.<object_id>.sign, astrology.<object_id>.house
+//	astrology.ascendant.sign, astrology.midheaven.sign
+//	human_design.personality.<object_id>.gate / .line
+//	human_design.design.<object_id>.gate / .line
+//	human_design.channels (channel ids, canonical order)
+//	human_design.definition / .type / .authority / .profile
+//
+// Signs and the derived classes are strings, houses, gates and
+// lines integers.
+type Chart map[string]any
+
+// ChartFunc computes the chart of a birth at instant t.
+type ChartFunc func(ctx context.Context, t time.Time) (Chart, error)
+
+// ChartAt computes the chart of a birth at instant t at the given
+// place against eph, with the pipeline of the /manifest envelope:
+// the chart at the payload's own birth instant holds exactly the
+// envelope's values.
+func ChartAt(ctx context.Context, eph ephemeris.Provider, t time.Time, latitude, longitude float64) (Chart, error) {
+	jd := astronomy.ConvertUTCToJulianDay(t)
+	a, err := astro.ComputeAstrologyAt(eph, jd, latitude, longitude)
+	if err != nil {
+		return nil, fmt.Errorf("compute astrology: %w", err)
+	}
+	designTime, _, err := hd.ComputeDesignTimeAt(ctx, eph, jd)
+	if err != nil {
+		return nil, fmt.Errorf("compute design time: %w", err)
+	}
+	personality, design, err := hd.ActivationsAt(ctx, eph, jd, hd.DesignJDFromTime(designTime))
+	if err != nil {
+		return nil, fmt.Errorf("compute activations: %w", err)
+	}
+	s, err := structure.Compute(personality, design)
+	if err != nil {
+		return nil, fmt.Errorf("compute structure: %w", err)
+	}
+
+	c := Chart{
+		"astrology.ascendant.sign": a.Angles.Ascendant.Sign,
+		"astrology.midheaven.sign": a.Angles.Midheaven.Sign,
+		"human_design.definition":  s.Definition,
+		"human_design.type":        s.Type,
+		"human_design.authority":   s.Authority,
+		"human_design.profile":     s.Profile,
+	}
+	for _, o := range a.Objects {
+		c["astrology."+o.ObjectID+".sign"] = o.Sign
+		c["astrology."+o.ObjectID+".house"] = o.House
+	}
+	for _, act := range personality {
+		c["human_design.personality."+act.ObjectID+".gate"] = act.Gate
+		c["human_design.personality."+act.ObjectID+".line"] = act.Line
+	}
+	for _, act := range design {
+		c["human_design.design."+act.ObjectID+".gate"] = act.Gate
+		c["human_design.design."+act.ObjectID+".line"] = act.Line
+	}
+	channels := make([]string, len(s.Channels))
+	for i, ch := range s.Channels {
+		channels[i] = ch.ChannelID
+	}
+	c["human_design.channels"] = channels
+	return c, nil
+}
+
+// Diff returns the fields whose value differs between c and other,
+// sorted.
+func (c Chart) Diff(other Chart) []string {
+	fields := []string{}
+	for field, v := range c {
+		if w, ok := other[field]; !ok || !reflect.DeepEqual(v, w) {
+			fields = append(fields, field)
+		}
+	}
+	for field := range other {
+		if _, ok := c[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// Span is one sub-window of a scan: the chart computed at every
+// whole second from Start to End, both included.  Changed lists the
+// fields that changed at Start, against the previous span; it is
+// empty for the first.
+type Span struct {
+	Start   time.Time
+	End     time.Time
+	Chart   Chart
+	Changed []string
+}
+
+// Scan cuts [start, end] into the spans of constant chart, with the
+// bisection described in the package comment.  start and end are
+// taken to whole seconds apart.  It returns the spans in time order
+// and the number of charts computed; the first failure of chart,
+// or a cancelled ctx, aborts the scan.
+func Scan(ctx context.Context, chart ChartFunc, start, end time.Time) ([]Span, int, error) {
+	s := scanner{chart: chart, start: start, charts: map[int64]Chart{}}
+	last := int64(end.Sub(start) / Resolution)
+	probe := int64(Probe / Resolution)
+	var cuts []int64
+	for a := int64(0); a < last; a += probe {
+		if err := s.bisect(ctx, a, min(a+probe, last), &cuts); err != nil {
+			return nil, len(s.charts), err
+		}
+	}
+
+	first, err := s.at(ctx, 0)
+	if err != nil {
+		return nil, len(s.charts), err
+	}
+	spans := []Span{{Start: start, Chart: first, Changed: []string{}}}
+	for _, cut := range cuts {
+		prev := &spans[len(spans)-1]
+		prev.End = s.instant(cut - 1)
+		c := s.charts[cut]
+		spans = append(spans, Span{Start: s.instant(cut), Chart: c, Changed: prev.Chart.Diff(c)})
+	}
+	spans[len(spans)-1].End = s.instant(last)
+	return spans, len(s.charts), nil
+}
+
+// scanner memoises the charts of one scan by their offset in
+// seconds from start: adjacent intervals share their ends.
+type scanner struct {
+	chart  ChartFunc
+	start  time.Time
+	charts map[int64]Chart
+}
+
+func (s *scanner) instant(offset int64) time.Time {
+	return s.start.Add(time.Duration(offset) * Resolution)
+}
+
+func (s *scanner) at(ctx context.Context, offset int64) (Chart, error) {
+	if c, ok := s.charts[offset]; ok {
+		return c, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	c, err := s.chart(ctx, s.instant(offset))
+	if err != nil {
+		return nil, fmt.Errorf("chart at %s: %w", s.instant(offset).Format(time.RFC3339), err)
+	}
+	s.charts[offset] = c
+	return c, nil
+}
+
+// bisect appends to cuts, in order, the offset of the first second
+// of every new chart in (a, b].
+func (s *scanner) bisect(ctx context.Context, a, b int64, cuts *[]int64) error {
+	ca, err := s.at(ctx, a)
+	if err != nil {
+		return err
+	}
+	cb, err := s.at(ctx, b)
+	if err != nil {
+		return err
+	}
+	if reflect.DeepEqual(ca, cb) {
+		return nil
+	}
+	if b-a == 1 {
+		*cuts = append(*cuts, b)
+		return nil
+	}
+	mid := a + (b-a)/2
+	if err := s.bisect(ctx, a, mid, cuts); err != nil {
+		return err
+	}
+	return s.bisect(ctx, mid, b, cuts)
+}
+
+// Report is the birth-time window of one payload: the window
+// itself, the chart at the stated birth time (Reference), and the
+// sub-windows of constant chart with the fields in which each
+// differs from it.  Times are RFC 3339 at whole seconds, in UTC and
+// in the payload's timezone.
+type Report struct {
+	WindowMinutes     int         `json:"window_minutes"`
+	BirthUTC          string      `json:"birth_utc"`
+	StartUTC          string      `json:"start_utc"`
+	EndUTC            string      `json:"end_utc"`
+	ResolutionSeconds int         `json:"resolution_seconds"`
+	ChartsComputed    int         `json:"charts_computed"`
+	Reference         Chart       `json:"reference"`
+	SubWindows        []SubWindow `json:"sub_windows"`
+}
+
+// SubWindow is one span of constant chart.  End is the last second
+// of the span, so consecutive sub-windows are one second apart.
+// Changed lists the fields that changed at Start; Differs holds this
+// sub-window's value of every field that differs from the
+// reference chart, and is empty for the sub-window holding the
+// stated birth time.
+type SubWindow struct {
+	StartUTC          string   `json:"start_utc"`
+	EndUTC            string   `json:"end_utc"`
+	StartLocal        string   `json:"start_local"`
+	EndLocal          string   `json:"end_local"`
+	ContainsBirthTime bool     `json:"contains_birth_time"`
+	Changed           []string `json:"changed"`
+	Differs           Chart    `json:"differs"`
+}
+
+// Compute scans the window of ±minutes around birth, a whole-minute
+// UTC instant, with chart, and reports its sub-windows with local
+// times in loc.
+func Compute(ctx context.Context, chart ChartFunc, birth time.Time, minutes int, loc *time.Location) (Report, error) {
+	half := time.Duration(minutes) * time.Minute
+	start, end := birth.Add(-half), birth.Add(half)
+	spans, computed, err := Scan(ctx, chart, start, end)
+	if err != nil {
+		return Report{}, err
+	}
+
+	var reference Chart
+	for _, sp := range spans {
+		if !birth.Before(sp.Start) && !birth.After(sp.End) {
+			reference = sp.Chart
+		}
+	}
+	r := Report{
+		WindowMinutes:     minutes,
+		BirthUTC:          utc(birth),
+		StartUTC:          utc(start),
+		EndUTC:            utc(end),
+		ResolutionSeconds: int(Resolution / time.Second),
+		ChartsComputed:    computed,
+		Reference:         reference,
+		SubWindows:        make([]SubWindow, len(spans)),
+	}
+	for i, sp := range spans {
+		differs := Chart{}
+		for _, field := range reference.Diff(sp.Chart) {
+			differs[field] = sp.Chart[field]
+		}
+		r.SubWindows[i] = SubWindow{
+			StartUTC:          utc(sp.Start),
+			EndUTC:            utc(sp.End),
+			StartLocal:        sp.Start.In(loc).Format(time.RFC3339),
+			EndLocal:          sp.End.In(loc).Format(time.RFC3339),
+			ContainsBirthTime: !birth.Before(sp.Start) && !birth.After(sp.End),
+			Changed:           sp.Changed,
+			Differs:           differs,
+		}
+	}
+	return r, nil
+}
+
+func utc(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
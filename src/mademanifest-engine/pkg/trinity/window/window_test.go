@@ -0,0 +1,136 @@
+package window
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var start = time.Date(1990, 4, 9, 15, 34, 0, 0, time.UTC)
+
+// ticks is a synthetic chart: field "<name>" counts the periods
+// elapsed since start, so it changes every period exactly.
+func ticks(periods map[string]time.Duration) ChartFunc {
+	return func(_ context.Context, t time.Time) (Chart, error) {
+		c := Chart{}
+		for name, period := range periods {
+			c[name] = int(t.Sub(start) / period)
+		}
+		return c, nil
+	}
+}
+
+// TestScanPinsEveryChange: changes a few seconds apart inside one
+// probe interval, and a field changing twice in one, are each found
+// to the second, with far fewer charts than seconds.
+func TestScanPinsEveryChange(t *testing.T) {
+	chart := ticks(map[string]time.Duration{
+		"slow":  25 * time.Minute,
+		"fast":  25*time.Minute + 3*time.Second,
+		"twice": 13 * time.Minute,
+	})
+	spans, computed, err := Scan(context.Background(), chart, start, start.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	type cut struct {
+		at      time.Duration
+		changed []string
+	}
+	var got []cut
+	for i, sp := range spans {
+		if i > 0 && sp.Start != spans[i-1].End.Add(Resolution) {
+			t.Errorf("span %d starts %v, previous ends %v", i, sp.Start, spans[i-1].End)
+		}
+		got = append(got, cut{sp.Start.Sub(start), sp.Changed})
+	}
+	want := []cut{
+		{0, []string{}},
+		{13 * time.Minute, []string{"twice"}},
+		{25 * time.Minute, []string{"slow"}},
+		{25*time.Minute + 3*time.Second, []string{"fast"}},
+		{26 * time.Minute, []string{"twice"}},
+		{39 * time.Minute, []string{"twice"}},
+		{50 * time.Minute, []string{"slow"}},
+		{50*time.Minute + 6*time.Second, []string{"fast"}},
+		{52 * time.Minute, []string{"twice"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("cuts\n %v\nwant\n %v", got, want)
+	}
+	if end := spans[len(spans)-1].End; !end.Equal(start.Add(time.Hour)) {
+		t.Errorf("last span ends %v", end)
+	}
+	if computed > 150 {
+		t.Errorf("%d charts computed for 8 changes", computed)
+	}
+}
+
+// TestScanConstantChart: a window shorter than Probe without a
+// change costs two charts.
+func TestScanConstantChart(t *testing.T) {
+	spans, computed, err := Scan(context.Background(), ticks(nil), start, start.Add(50*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spans) != 1 || computed != 2 {
+		t.Errorf("%d spans from %d charts, want 1 from 2", len(spans), computed)
+	}
+}
+
+func TestScanStopsOnFailure(t *testing.T) {
+	boom := errors.New("boom")
+	failing := func(_ context.Context, t time.Time) (Chart, error) {
+		if t.After(start) {
+			return nil, boom
+		}
+		return Chart{}, nil
+	}
+	if _, _, err := Scan(context.Background(), failing, start, start.Add(time.Hour)); !errors.Is(err, boom) {
+		t.Errorf("err = %v, want boom", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := Scan(ctx, ticks(nil), start, start.Add(time.Hour)); !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled scan: err = %v", err)
+	}
+}
+
+// TestComputeDiffersFromReference: the sub-window holding the birth
+// time is the reference and differs in nothing; the others list
+// their own values of the fields that differ, with local times in
+// the payload's zone.
+func TestComputeDiffersFromReference(t *testing.T) {
+	amsterdam, err := time.LoadLocation("Europe/Amsterdam")
+	if err != nil {
+		t.Fatal(err)
+	}
+	birth := start.Add(30 * time.Minute)
+	chart := ticks(map[string]time.Duration{"a": 20 * time.Minute, "b": 45 * time.Minute})
+	r, err := Compute(context.Background(), chart, birth, 30, amsterdam)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.StartUTC != "1990-04-09T15:34:00Z" || r.EndUTC != "1990-04-09T16:34:00Z" || r.BirthUTC != "1990-04-09T16:04:00Z" {
+		t.Errorf("window %s – %s around %s", r.StartUTC, r.EndUTC, r.BirthUTC)
+	}
+	if !reflect.DeepEqual(r.Reference, Chart{"a": 1, "b": 0}) {
+		t.Errorf("reference = %v", r.Reference)
+	}
+	var differs []Chart
+	for _, w := range r.SubWindows {
+		differs = append(differs, w.Differs)
+		if w.ContainsBirthTime != (len(w.Differs) == 0) {
+			t.Errorf("%s – %s: contains birth %v, differs %v", w.StartUTC, w.EndUTC, w.ContainsBirthTime, w.Differs)
+		}
+	}
+	want := []Chart{{"a": 0}, {}, {"a": 2}, {"a": 2, "b": 1}, {"a": 3, "b": 1}}
+	if !reflect.DeepEqual(differs, want) {
+		t.Errorf("differs = %v, want %v", differs, want)
+	}
+	if w := r.SubWindows[1]; w.StartLocal != "1990-04-09T17:54:00+02:00" || w.EndLocal != "1990-04-09T18:13:59+02:00" {
+		t.Errorf("birth sub-window %s – %s", w.StartLocal, w.EndLocal)
+	}
+}
@@ -7,6 +7,7 @@
 #
 # Exercises:
 #   GET  /healthz   – expects 200 {"status":"ok"}
+#   GET  /readyz    – expects 200 {"status":"ready"}
 #   POST /manifest  – expects 200 with the JSON calculation envelope,
 #                     payload taken from the Trinity baseline fixture
 #                     under golden/trinity/valid_baseline/.
@@ -57,6 +58,15 @@ status=${resp##*HTTPSTATUS:}
 body=${resp%$'\n'HTTPSTATUS:*}
 print_result healthz "$status" "$body"
 
+echo
+echo "==> GET $URL/readyz"
+resp=$(curl --silent --show-error --max-time 10 \
+  --write-out '\nHTTPSTATUS:%{http_code}' \
+  "$URL/readyz" || printf '\nHTTPSTATUS:000')
+status=${resp##*HTTPSTATUS:}
+body=${resp%$'\n'HTTPSTATUS:*}
+print_result readyz "$status" "$body"
+
 echo
 echo "==> POST $URL/manifest"
 echo "    payload: $FIXTURE"